@@ -10,8 +10,11 @@ import (
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/handlers"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/lockout"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/mailer"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/implserv"
 	"github.com/DmytroBeliasnyk/in_memory_cache/memory"
 	"github.com/sirupsen/logrus"
 )
@@ -54,13 +57,37 @@ func main() {
 		logrus.WithField("error", err).Fatal("error occurred while connecting to db")
 	}
 
+	mail, err := mailer.New(cfg.Mailer)
+	if err != nil {
+		logrus.WithField("error", err).Fatal("error initializing mailer")
+	}
+
+	attempts, err := lockout.New(cfg.SignIn.Lockout, db)
+	if err != nil {
+		logrus.WithField("error", err).Fatal("error initializing lockout store")
+	}
+
 	repo := repositories.NewRepository(db)
-	service := services.NewService(repo, cfg)
+	service, err := services.NewService(repo, mail, attempts, cfg)
+	if err != nil {
+		logrus.WithField("error", err).Fatal("error initializing services")
+	}
+
 	handlers := handlers.NewHandler(service, cfg, memory.GetCache())
 
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	retention := implserv.NewAuditRetention(repo.AuditRepository, cfg.Audit.Retention, cfg.Audit.PurgeInterval)
+	go retention.Run(purgeCtx)
+	go implserv.NewLockoutRetention(attempts, cfg.SignIn.Lockout).Run(purgeCtx)
+
+	routes, err := handlers.InitRoutes()
+	if err != nil {
+		logrus.WithField("error", err).Fatal("error initializing routes")
+	}
+
 	server := new(core.Server)
 	go func() {
-		if err = server.Run(cfg.ServerPort, handlers.InitRoutes()); err != nil {
+		if err = server.Run(cfg.ServerPort, routes); err != nil {
 			logrus.WithField("error", err).Fatal("error occurred while running http server")
 		}
 	}()
@@ -70,6 +97,7 @@ func main() {
 
 	<-quit
 
+	stopPurge()
 	if err = server.Shutdown(context.Background()); err != nil {
 		logrus.WithField("error", err).Fatal("error occurred on server shutting down")
 	}
@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+// AccessTokenPrefix tells personal access tokens apart from JWTs in the Authorization header.
+const AccessTokenPrefix = "pat_"
+
+// Scopes of personal access tokens. Access tokens of a sign in are not limited by scopes.
+const (
+	ScopeProjectsRead  = "projects:read"
+	ScopeProjectsWrite = "projects:write"
+)
+
+type CreateAccessTokenDTO struct {
+	Name          string   `json:"name" binding:"required,lte=255"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,unique,dive,oneof=projects:read projects:write"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"required,gte=1,lte=365"`
+}
+
+type AccessTokenDTO struct {
+	Id         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// CreatedAccessTokenDTO carries the token itself, which is only shown once.
+type CreatedAccessTokenDTO struct {
+	AccessTokenDTO
+	Token string `json:"token"`
+}
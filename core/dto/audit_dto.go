@@ -0,0 +1,52 @@
+package dto
+
+import "time"
+
+const DefaultAuditEventsLimit = 50
+
+type AuditEventDTO struct {
+	Id        int64     `json:"id"`
+	Event     string    `json:"event"`
+	UserId    *int64    `json:"user_id"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	RequestId string    `json:"request_id"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditQueryDTO filters the audit log, events are listed from the newest.
+type AuditQueryDTO struct {
+	Limit  int        `form:"limit" binding:"omitempty,gte=1,lte=100"`
+	Cursor string     `form:"cursor"`
+	UserId int64      `form:"user_id" binding:"omitempty,gte=1"`
+	Event  string     `form:"event"`
+	IP     string     `form:"ip" binding:"omitempty,ip"`
+	From   *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+
+	BeforeId int64 `form:"-"`
+}
+
+// Validate fills in the page size and decodes the cursor.
+func (q *AuditQueryDTO) Validate() error {
+	if q.Limit == 0 {
+		q.Limit = DefaultAuditEventsLimit
+	}
+
+	if q.Cursor != "" {
+		before, err := DecodeIdCursor(q.Cursor)
+		if err != nil {
+			return err
+		}
+
+		q.BeforeId = before
+	}
+
+	return nil
+}
+
+type AuditPageDTO struct {
+	Items      []AuditEventDTO `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
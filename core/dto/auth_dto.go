@@ -1,6 +1,12 @@
 package dto
 
-import "github.com/go-playground/validator/v10"
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
 
 type SignUpDTO struct {
 	Name     string `json:"name" validate:"required,gte=2"`
@@ -9,15 +15,69 @@ type SignUpDTO struct {
 	Password string `json:"password" validate:"required,gte=8"`
 }
 
+// SignInDTO identifies the user by either the username or the email.
 type SignInDTO struct {
-	Username string `json:"username" binding:"required"`
+	Username string `json:"username" binding:"required_without=Email,excluded_with=Email"`
+	Email    string `json:"email" binding:"required_without=Username,omitempty,email"`
 	Password string `json:"password" binding:"required"`
 }
 
+// RefreshTokenDTO carries the refresh token of clients that do not use cookies.
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type VerifyEmailDTO struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationDTO struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ForgotPasswordDTO struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordDTO struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,gte=8"`
+}
+
+// ChangePasswordDTO sets the new password, accounts without a password yet,
+// e.g. of users signed up with OpenID Connect, send no current password.
+type ChangePasswordDTO struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password" binding:"required,gte=8"`
+}
+
+// TokenClaimsDTO are the claims of a verified access token. Id is the jti the
+// token can be revoked by and SessionId is the family id of its session,
+// tokens issued before either existed have none.
+type TokenClaimsDTO struct {
+	UserId    int64
+	Role      string
+	Id        string
+	SessionId int64
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
 var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+	validate.RegisterTagNameFunc(jsonName)
+}
+
+// jsonName makes validation errors refer to fields by their JSON names.
+func jsonName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+
+	return name
 }
 
 func (su *SignUpDTO) Validate() error {
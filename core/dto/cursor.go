@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+)
+
+// EncodeIdCursor returns the opaque cursor of a page that continues from the id,
+// it is used by the lists ordered by id, e.g. users and audit events.
+func EncodeIdCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func DecodeIdCursor(cursor string) (int64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
+	}
+
+	id, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid cursor")
+	}
+
+	return id, nil
+}
@@ -0,0 +1,18 @@
+package dto
+
+// JWKSetDTO is the JSON Web Key Set (RFC 7517) of the keys access tokens can be verified with.
+type JWKSetDTO struct {
+	Keys []JWKDTO `json:"keys"`
+}
+
+type JWKDTO struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
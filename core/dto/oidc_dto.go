@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+// OIDCStartDTO starts an OpenID Connect sign in. The user is sent to AuthURL and
+// Flow is kept by the client until the provider redirects back.
+type OIDCStartDTO struct {
+	AuthURL   string
+	Flow      string
+	ExpiresAt time.Time
+}
+
+// OIDCCallbackDTO is the query the provider redirects back to the callback with.
+type OIDCCallbackDTO struct {
+	Code             string `form:"code" binding:"required_without=Error"`
+	State            string `form:"state" binding:"required"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
@@ -1,25 +1,152 @@
 package dto
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
 )
 
+const (
+	DefaultProjectsLimit = 20
+	MaxProjectsLimit     = 100
+)
+
+// ProjectDTO creates or replaces a project. With DoneFromTasks the project is done
+// when it has tasks and all of them are done, the Done of the input is ignored then.
 type ProjectDTO struct {
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description"`
-	Done        bool   `json:"done"`
+	Title         string `json:"title" binding:"required"`
+	Description   string `json:"description"`
+	Done          bool   `json:"done"`
+	DoneFromTasks bool   `json:"done_from_tasks"`
+}
+
+type ProjectResponseDTO struct {
+	Id            int64      `json:"id"`
+	UserId        int64      `json:"user_id"`
+	Role          string     `json:"role"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	Done          bool       `json:"done"`
+	DoneFromTasks bool       `json:"done_from_tasks"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
 }
 
 type UpdateProjectDTO struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	Done        *bool   `json:"done"`
+	Title         *string `json:"title"`
+	Description   *string `json:"description"`
+	Done          *bool   `json:"done"`
+	DoneFromTasks *bool   `json:"done_from_tasks"`
 }
 
 func (up *UpdateProjectDTO) Validate() error {
-	if up.Title == nil && up.Description == nil && up.Done == nil {
+	if up.Title == nil && up.Description == nil && up.Done == nil && up.DoneFromTasks == nil {
 		return errors.New("update structure has no values")
 	}
 
 	return nil
 }
+
+type ProjectQueryDTO struct {
+	Limit       int        `form:"limit" binding:"omitempty,gte=1,lte=100"`
+	Cursor      string     `form:"cursor"`
+	Done        *bool      `form:"done"`
+	Search      string     `form:"search"`
+	CreatedFrom *time.Time `form:"created_from"`
+	CreatedTo   *time.Time `form:"created_to"`
+	Sort        string     `form:"sort" binding:"omitempty,oneof=title created_at updated_at"`
+	Order       string     `form:"order" binding:"omitempty,oneof=asc desc"`
+
+	After *ProjectCursor `form:"-"`
+}
+
+// Validate checks the query after defaults were applied and decodes the cursor.
+func (q *ProjectQueryDTO) Validate() error {
+	if q.CreatedFrom != nil && q.CreatedTo != nil && q.CreatedFrom.After(*q.CreatedTo) {
+		return errors.New("created_from must not be after created_to")
+	}
+
+	if q.Cursor != "" {
+		after, err := DecodeProjectCursor(q.Cursor)
+		if err != nil {
+			return err
+		}
+
+		if after.Sort != q.Sort {
+			return errors.New("cursor was issued for another sorting")
+		}
+
+		q.After = after
+	}
+
+	return nil
+}
+
+// SetDefaults fills in the page size and sorting that were not requested.
+func (q *ProjectQueryDTO) SetDefaults() {
+	if q.Limit == 0 {
+		q.Limit = DefaultProjectsLimit
+	}
+	if q.Sort == "" {
+		q.Sort = "created_at"
+	}
+	if q.Order == "" {
+		q.Order = "asc"
+	}
+}
+
+// CacheKey returns a canonical representation of the query
+// that does not depend on the order of the query parameters.
+func (q *ProjectQueryDTO) CacheKey() string {
+	done := ""
+	if q.Done != nil {
+		done = fmt.Sprint(*q.Done)
+	}
+
+	from, to := "", ""
+	if q.CreatedFrom != nil {
+		from = q.CreatedFrom.UTC().Format(time.RFC3339Nano)
+	}
+	if q.CreatedTo != nil {
+		to = q.CreatedTo.UTC().Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprintf("limit=%d&cursor=%s&done=%s&search=%s&from=%s&to=%s&sort=%s&order=%s",
+		q.Limit, q.Cursor, done, q.Search, from, to, q.Sort, q.Order)
+}
+
+type ProjectPageDTO struct {
+	Items      []ProjectResponseDTO `json:"items"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// ProjectCursor points to the last project of a page. It is handed to clients
+// as an opaque string and is only valid for the sorting it was issued for.
+type ProjectCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Id    int64  `json:"id"`
+}
+
+func (c ProjectCursor) Encode() string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeProjectCursor(cursor string) (*ProjectCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	var c ProjectCursor
+	if err = json.Unmarshal(data, &c); err != nil || c.Id == 0 {
+		return nil, errors.New("invalid cursor")
+	}
+
+	return &c, nil
+}
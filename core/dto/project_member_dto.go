@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+// Roles of project members. Every project has exactly one owner, editors
+// can change the project and viewers can only read it.
+const (
+	ProjectRoleOwner  = "owner"
+	ProjectRoleEditor = "editor"
+	ProjectRoleViewer = "viewer"
+)
+
+type ProjectMemberDTO struct {
+	UserId    int64     `json:"user_id"`
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddProjectMemberDTO invites a user by username or by email.
+type AddProjectMemberDTO struct {
+	Username string `json:"username" binding:"required_without=Email,excluded_with=Email"`
+	Email    string `json:"email" binding:"required_without=Username,omitempty,email"`
+	Role     string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// UpdateProjectMemberDTO changes the role of a member, the owner changes
+// only by transferring the ownership.
+type UpdateProjectMemberDTO struct {
+	Role string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+type TransferProjectDTO struct {
+	UserId int64 `json:"user_id" binding:"required,gte=1"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+// ClientInfo describes the client of a request, sessions and audit events record it.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+	RequestId string
+}
+
+type SessionDTO struct {
+	Id         int64     `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
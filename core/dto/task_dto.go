@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	TaskStatusTodo       = "todo"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusDone       = "done"
+
+	// DueDateLayout is the format of due dates, they have no time of day
+	DueDateLayout = "2006-01-02"
+)
+
+// TaskDTO creates a task. Without a position the task is appended to the project.
+type TaskDTO struct {
+	Title      string `json:"title" binding:"required,lte=255"`
+	Notes      string `json:"notes"`
+	Status     string `json:"status" binding:"omitempty,oneof=todo in_progress done"`
+	DueDate    string `json:"due_date" binding:"omitempty,datetime=2006-01-02"`
+	Position   *int   `json:"position" binding:"omitempty,gte=0"`
+	AssigneeId *int64 `json:"assignee_id" binding:"omitempty,gte=1"`
+}
+
+type TaskResponseDTO struct {
+	Id          int64      `json:"id"`
+	ProjectId   int64      `json:"project_id"`
+	Title       string     `json:"title"`
+	Notes       string     `json:"notes"`
+	Status      string     `json:"status"`
+	DueDate     *string    `json:"due_date"`
+	Position    int        `json:"position"`
+	AssigneeId  *int64     `json:"assignee_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// UpdateTaskDTO changes the provided fields of a task. An empty due date
+// removes the due date and an assignee id of 0 unassigns the task.
+type UpdateTaskDTO struct {
+	Title      *string `json:"title" binding:"omitempty,gte=1,lte=255"`
+	Notes      *string `json:"notes"`
+	Status     *string `json:"status" binding:"omitempty,oneof=todo in_progress done"`
+	DueDate    *string `json:"due_date" binding:"omitempty,len=0|datetime=2006-01-02"`
+	Position   *int    `json:"position" binding:"omitempty,gte=0"`
+	AssigneeId *int64  `json:"assignee_id" binding:"omitempty,gte=0"`
+}
+
+func (ut *UpdateTaskDTO) Validate() error {
+	if ut.Title == nil && ut.Notes == nil && ut.Status == nil && ut.DueDate == nil &&
+		ut.Position == nil && ut.AssigneeId == nil {
+		return errors.New("update structure has no values")
+	}
+
+	return nil
+}
+
+type TaskQueryDTO struct {
+	Status     string `form:"status" binding:"omitempty,oneof=todo in_progress done"`
+	AssigneeId int64  `form:"assignee_id" binding:"omitempty,gte=1"`
+}
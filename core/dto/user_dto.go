@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+
+	DefaultUsersLimit = 20
+)
+
+type UserResponseDTO struct {
+	Id              int64      `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Username        string     `json:"username"`
+	TwoFactor       bool       `json:"two_factor"`
+	Role            string     `json:"role"`
+}
+
+type UpdateUserDTO struct {
+	Name     *string `json:"name" binding:"omitempty,gte=2"`
+	Email    *string `json:"email" binding:"omitempty,email"`
+	Username *string `json:"username" binding:"omitempty,gte=3"`
+}
+
+func (uu *UpdateUserDTO) Validate() error {
+	if uu.Name == nil && uu.Email == nil && uu.Username == nil {
+		return errors.New("update structure has no values")
+	}
+
+	return nil
+}
+
+// DeleteUserDTO confirms the deletion with the password, accounts without
+// a password, e.g. of users signed up with OpenID Connect, send none.
+type DeleteUserDTO struct {
+	Password string `json:"password"`
+}
+
+// AdminUserDTO is a user as shown to admins.
+type AdminUserDTO struct {
+	UserResponseDTO
+	DisabledAt *time.Time `json:"disabled_at"`
+}
+
+type UserQueryDTO struct {
+	Limit    int    `form:"limit" binding:"omitempty,gte=1,lte=100"`
+	Cursor   string `form:"cursor"`
+	Search   string `form:"search"`
+	Role     string `form:"role" binding:"omitempty,oneof=user admin"`
+	Disabled *bool  `form:"disabled"`
+
+	AfterId int64 `form:"-"`
+}
+
+// Validate fills in the page size and decodes the cursor.
+func (q *UserQueryDTO) Validate() error {
+	if q.Limit == 0 {
+		q.Limit = DefaultUsersLimit
+	}
+
+	if q.Cursor != "" {
+		after, err := DecodeIdCursor(q.Cursor)
+		if err != nil {
+			return err
+		}
+
+		q.AfterId = after
+	}
+
+	return nil
+}
+
+type UserPageDTO struct {
+	Items      []AdminUserDTO `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
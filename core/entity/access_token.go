@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/lib/pq"
+)
+
+// AccessToken is a long-lived personal access token of a user, limited to its scopes.
+type AccessToken struct {
+	Id         int64          `db:"id"`
+	UserId     int64          `db:"user_id"`
+	Name       string         `db:"name"`
+	Scopes     pq.StringArray `db:"scopes"`
+	CreatedAt  time.Time      `db:"created_at"`
+	ExpiresAt  time.Time      `db:"expires_at"`
+	LastUsedAt *time.Time     `db:"last_used_at"`
+}
+
+func (t AccessToken) ToDTO() dto.AccessTokenDTO {
+	return dto.AccessTokenDTO{
+		Id:         t.Id,
+		Name:       t.Name,
+		Scopes:     t.Scopes,
+		CreatedAt:  t.CreatedAt,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+	}
+}
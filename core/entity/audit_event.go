@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+)
+
+// Events of the audit log.
+const (
+	AuditSignUp            = "sign_up"
+	AuditSignIn            = "sign_in"
+	AuditSignInFailed      = "sign_in_failed"
+	AuditSignInLocked      = "sign_in_locked"
+	AuditSignInUnlocked    = "sign_in_unlocked"
+	AuditTokenRefreshed    = "token_refreshed"
+	AuditRefreshRejected   = "refresh_rejected"
+	AuditRefreshTokenReuse = "refresh_token_reuse"
+	AuditPasswordChanged   = "password_changed"
+	AuditPasswordReset     = "password_reset"
+	AuditIdentityLinked    = "identity_linked"
+)
+
+// AuditEvent records a security relevant event together with the client that caused it.
+// UserId is nil if the event is not related to a known user, e.g. a sign in with an unknown username.
+type AuditEvent struct {
+	Id        int64     `db:"id"`
+	Event     string    `db:"event"`
+	UserId    *int64    `db:"user_id"`
+	IP        string    `db:"ip"`
+	UserAgent string    `db:"user_agent"`
+	RequestId string    `db:"request_id"`
+	Detail    string    `db:"detail"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (e AuditEvent) ToDTO() dto.AuditEventDTO {
+	return dto.AuditEventDTO{
+		Id:        e.Id,
+		Event:     e.Event,
+		UserId:    e.UserId,
+		IP:        e.IP,
+		UserAgent: e.UserAgent,
+		RequestId: e.RequestId,
+		Detail:    e.Detail,
+		CreatedAt: e.CreatedAt,
+	}
+}
@@ -1,27 +1,47 @@
 package entity
 
-import "github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+import (
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+)
 
 type Project struct {
-	Id          int64  `db:"id"`
-	Title       string `db:"title"`
-	Description string `db:"description"`
-	Done        bool   `db:"done"`
-	UserId      int64  `db:"user_id"`
+	Id            int64      `db:"id"`
+	Title         string     `db:"title"`
+	Description   string     `db:"description"`
+	Done          bool       `db:"done"`
+	DoneFromTasks bool       `db:"done_from_tasks"`
+	UserId        int64      `db:"user_id"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
+	CompletedAt   *time.Time `db:"completed_at"`
+	// Role is the role in the project of the user it was loaded for.
+	Role string `db:"role"`
 }
 
+// FromDTO converts a validated input. A project deriving done from its tasks
+// has no tasks yet, so it is not done.
 func FromDTO(dto dto.ProjectDTO) *Project {
 	return &Project{
-		Title:       dto.Title,
-		Description: dto.Description,
-		Done:        dto.Done,
+		Title:         dto.Title,
+		Description:   dto.Description,
+		Done:          dto.Done && !dto.DoneFromTasks,
+		DoneFromTasks: dto.DoneFromTasks,
 	}
 }
 
-func (p *Project) ToDTO() *dto.ProjectDTO {
-	return &dto.ProjectDTO{
-		Title:       p.Title,
-		Description: p.Description,
-		Done:        p.Done,
+func (p *Project) ToResponseDTO() *dto.ProjectResponseDTO {
+	return &dto.ProjectResponseDTO{
+		Id:            p.Id,
+		UserId:        p.UserId,
+		Role:          p.Role,
+		Title:         p.Title,
+		Description:   p.Description,
+		Done:          p.Done,
+		DoneFromTasks: p.DoneFromTasks,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+		CompletedAt:   p.CompletedAt,
 	}
 }
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+)
+
+// ProjectMember is a user sharing a project together with the username and
+// name of the user.
+type ProjectMember struct {
+	ProjectId int64     `db:"project_id"`
+	UserId    int64     `db:"user_id"`
+	Username  string    `db:"username"`
+	Name      string    `db:"name"`
+	Role      string    `db:"role"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (m ProjectMember) ToDTO() dto.ProjectMemberDTO {
+	return dto.ProjectMemberDTO{
+		UserId:    m.UserId,
+		Username:  m.Username,
+		Name:      m.Name,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt,
+	}
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+)
+
+// Session is a refresh token together with the client it was issued to.
+// Every rotation issues a new token of the same family, the family id is
+// the id of the session shown to the user.
+type Session struct {
+	Id         int64      `db:"id"`
+	UserId     int64      `db:"user_id"`
+	FamilyId   int64      `db:"family_id"`
+	ParentId   *int64     `db:"parent_id"`
+	RotatedAt  *time.Time `db:"rotated_at"`
+	UserAgent  string     `db:"user_agent"`
+	IP         string     `db:"ip"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt time.Time  `db:"last_used_at"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+}
+
+func (s Session) ToDTO(current bool) dto.SessionDTO {
+	return dto.SessionDTO{
+		Id:         s.FamilyId,
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+		Current:    current,
+	}
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+)
+
+// Task is a work item of a project. Position is only nil for new tasks
+// that are to be appended to the project.
+type Task struct {
+	Id          int64      `db:"id"`
+	ProjectId   int64      `db:"project_id"`
+	Title       string     `db:"title"`
+	Notes       string     `db:"notes"`
+	Status      string     `db:"status"`
+	DueDate     *time.Time `db:"due_date"`
+	Position    *int       `db:"position"`
+	AssigneeId  *int64     `db:"assignee_id"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	CompletedAt *time.Time `db:"completed_at"`
+}
+
+// TaskFromDTO converts a validated input, new tasks are to do unless stated otherwise.
+func TaskFromDTO(input dto.TaskDTO) (*Task, error) {
+	t := &Task{
+		Title:      input.Title,
+		Notes:      input.Notes,
+		Status:     input.Status,
+		Position:   input.Position,
+		AssigneeId: input.AssigneeId,
+	}
+
+	if t.Status == "" {
+		t.Status = dto.TaskStatusTodo
+	}
+
+	if input.DueDate != "" {
+		dueDate, err := time.Parse(dto.DueDateLayout, input.DueDate)
+		if err != nil {
+			return nil, err
+		}
+		t.DueDate = &dueDate
+	}
+
+	return t, nil
+}
+
+func (t *Task) ToResponseDTO() *dto.TaskResponseDTO {
+	res := &dto.TaskResponseDTO{
+		Id:          t.Id,
+		ProjectId:   t.ProjectId,
+		Title:       t.Title,
+		Notes:       t.Notes,
+		Status:      t.Status,
+		AssigneeId:  t.AssigneeId,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+		CompletedAt: t.CompletedAt,
+	}
+
+	if t.Position != nil {
+		res.Position = *t.Position
+	}
+
+	if t.DueDate != nil {
+		dueDate := t.DueDate.Format(dto.DueDateLayout)
+		res.DueDate = &dueDate
+	}
+
+	return res
+}
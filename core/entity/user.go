@@ -1,14 +1,47 @@
 package entity
 
-import "github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+import (
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+)
 
 type User struct {
-	Id    int    `db:"id"`
-	Name  string `db:"name"`
-	Email string `db:"email"`
+	Id              int        `db:"id"`
+	Name            string     `db:"name"`
+	Email           string     `db:"email"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
 
 	Username     string `db:"username"`
 	PasswordHash string `db:"password_hash"`
+
+	// TOTPSecret is encrypted and set once the enrollment starts,
+	// TOTPEnabledAt once it is confirmed with a first code
+	TOTPSecret    *string    `db:"totp_secret"`
+	TOTPEnabledAt *time.Time `db:"totp_enabled_at"`
+	TOTPLastStep  *int64     `db:"totp_last_step"`
+
+	Role       string     `db:"role"`
+	DisabledAt *time.Time `db:"disabled_at"`
+}
+
+func (u *User) ToResponseDTO() *dto.UserResponseDTO {
+	return &dto.UserResponseDTO{
+		Id:              int64(u.Id),
+		Name:            u.Name,
+		Email:           u.Email,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		Username:        u.Username,
+		TwoFactor:       u.TOTPEnabledAt != nil,
+		Role:            u.Role,
+	}
+}
+
+func (u *User) ToAdminDTO() dto.AdminUserDTO {
+	return dto.AdminUserDTO{
+		UserResponseDTO: *u.ToResponseDTO(),
+		DisabledAt:      u.DisabledAt,
+	}
 }
 
 func FromSignUpDTO(u dto.SignUpDTO, passwordHash string) *User {
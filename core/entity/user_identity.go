@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// UserIdentity links the subject of an OpenID Connect provider to a user.
+// Email is the email of the subject when it was linked.
+type UserIdentity struct {
+	Id        int64     `db:"id"`
+	UserId    int64     `db:"user_id"`
+	Provider  string    `db:"provider"`
+	Subject   string    `db:"subject"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeTwoFactor         = "two_factor"
+)
+
+// UserToken is a single-use token sent to the email of the user,
+// e.g. to prove that the address belongs to them or to reset the password.
+type UserToken struct {
+	Id        int64     `db:"id"`
+	UserId    int64     `db:"user_id"`
+	Purpose   string    `db:"purpose"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
@@ -9,10 +9,21 @@ import (
 )
 
 type Config struct {
-	ServerPort string `mapstructure:"server_port"`
-	DB         DB     `mapstructure:"db"`
-	Auth       Auth   `mapstructure:"tokens_ttl"`
-	Cookie     Cookie `mapstructure:"cookie"`
+	ServerPort string    `mapstructure:"server_port"`
+	DB         DB        `mapstructure:"db"`
+	Auth       Auth      `mapstructure:"tokens_ttl"`
+	Cookie     Cookie    `mapstructure:"cookie"`
+	Refresh    Refresh   `mapstructure:"refresh_token"`
+	Password   Password  `mapstructure:"password"`
+	JWT        JWT       `mapstructure:"jwt"`
+	Mailer     Mailer    `mapstructure:"mailer"`
+	SignIn     SignIn    `mapstructure:"sign_in"`
+	TwoFactor  TwoFactor `mapstructure:"two_factor"`
+	Audit      Audit     `mapstructure:"audit"`
+	OIDC       OIDC      `mapstructure:"oidc"`
+	// TrustedProxies lists the addresses or CIDRs of the reverse proxies whose
+	// X-Forwarded-For is trusted, without them the client IP is the peer address
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type DB struct {
@@ -31,8 +42,136 @@ type DBPassword struct {
 type Auth struct {
 	Salt      string
 	Signature string
-	JWT       time.Duration `mapstructure:"jwt"`
-	Refresh   time.Duration `mapstructure:"refresh"`
+	// RefreshSecret is the HMAC key refresh tokens are hashed with before they are stored
+	RefreshSecret string        `split_words:"true" required:"true"`
+	JWT           time.Duration `mapstructure:"jwt"`
+	Refresh       time.Duration `mapstructure:"refresh"`
+	Verification  time.Duration `mapstructure:"verification"`
+	PasswordReset time.Duration `mapstructure:"password_reset"`
+	TwoFactor     time.Duration `mapstructure:"two_factor"`
+	OIDC          time.Duration `mapstructure:"oidc"`
+}
+
+// JWT selects the algorithm access tokens are signed with. HS256 uses the
+// AUTH_SIGNATURE secret, the asymmetric algorithms use the PEM files of Keys.
+type JWT struct {
+	Algorithm  string   `mapstructure:"algorithm"`
+	SigningKey string   `mapstructure:"signing_key"`
+	Keys       []JWTKey `mapstructure:"keys"`
+}
+
+// JWTKey is a key pair identified by the kid header of tokens. Keys without
+// a private key only verify tokens, e.g. the previous key during a rotation.
+// Algorithm is the one of JWT unless set, e.g. for an ES256 key kept after
+// switching to EdDSA.
+type JWTKey struct {
+	Kid        string `mapstructure:"kid"`
+	Algorithm  string `mapstructure:"algorithm"`
+	PrivateKey string `mapstructure:"private_key"`
+	PublicKey  string `mapstructure:"public_key"`
+}
+
+type Password struct {
+	Memory      uint32         `mapstructure:"memory"`
+	Iterations  uint32         `mapstructure:"iterations"`
+	Parallelism uint8          `mapstructure:"parallelism"`
+	SaltLength  uint32         `mapstructure:"salt_length"`
+	KeyLength   uint32         `mapstructure:"key_length"`
+	Policy      PasswordPolicy `mapstructure:"policy"`
+}
+
+// PasswordPolicy is checked on sign up and on every password change. BreachedFile
+// lists the SHA-1 hashes of breached passwords, one per line in the format of
+// Pwned Passwords ("HASH" or "HASH:COUNT"), no list is checked if it is empty.
+type PasswordPolicy struct {
+	MinLength     int    `mapstructure:"min_length"`
+	RequireUpper  bool   `mapstructure:"require_upper"`
+	RequireLower  bool   `mapstructure:"require_lower"`
+	RequireDigit  bool   `mapstructure:"require_digit"`
+	RequireSymbol bool   `mapstructure:"require_symbol"`
+	BreachedFile  string `mapstructure:"breached_file"`
+}
+
+// Mailer selects how emails are delivered: smtp, file or log.
+// BaseURL is the address links in emails point to.
+type Mailer struct {
+	Driver  string `mapstructure:"driver"`
+	From    string `mapstructure:"from"`
+	BaseURL string `mapstructure:"base_url"`
+	File    string `mapstructure:"file"`
+	SMTP    SMTP   `mapstructure:"smtp"`
+}
+
+type SMTP struct {
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password SMTPPassword
+}
+
+type SMTPPassword struct {
+	Password string
+}
+
+type SignIn struct {
+	RequireVerifiedEmail bool    `mapstructure:"require_verified_email"`
+	Lockout              Lockout `mapstructure:"lockout"`
+}
+
+// Lockout locks a username or a client IP after Threshold failed sign ins within Window.
+// The lock lasts BaseDelay and doubles with every further failure up to MaxDelay.
+// Driver selects where failures are counted: postgres or memory. Attempts that
+// no longer count are deleted every PurgeInterval.
+type Lockout struct {
+	Driver        string        `mapstructure:"driver"`
+	Threshold     int           `mapstructure:"threshold"`
+	Window        time.Duration `mapstructure:"window"`
+	BaseDelay     time.Duration `mapstructure:"base_delay"`
+	MaxDelay      time.Duration `mapstructure:"max_delay"`
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+}
+
+// TwoFactor configures TOTP, Issuer is the name authenticator apps show for the account.
+type TwoFactor struct {
+	Issuer string `mapstructure:"issuer"`
+	Key    TwoFactorKey
+}
+
+// TwoFactorKey is read from TWO_FACTOR_ENCRYPTION_KEY, TOTP secrets are encrypted
+// and recovery codes are hashed with keys derived from it.
+type TwoFactorKey struct {
+	EncryptionKey string `split_words:"true" required:"true"`
+}
+
+// Audit configures the audit log of authentication events. Events older than
+// Retention are deleted every PurgeInterval, a Retention of 0 keeps them forever.
+type Audit struct {
+	Retention     time.Duration `mapstructure:"retention"`
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+}
+
+// OIDC configures the OpenID Connect providers users can sign in with, by name.
+type OIDC struct {
+	Providers map[string]OIDCProvider `mapstructure:"providers"`
+}
+
+// OIDCProvider is a provider of the authorization code flow. The endpoints are
+// discovered from the issuer unless they are set. RedirectURL is the callback
+// of the provider, e.g. https://api.example.com/auth/oidc/corp/callback.
+type OIDCProvider struct {
+	Issuer      string   `mapstructure:"issuer"`
+	ClientId    string   `mapstructure:"client_id"`
+	RedirectURL string   `mapstructure:"redirect_url"`
+	Scopes      []string `mapstructure:"scopes"`
+	AuthURL     string   `mapstructure:"auth_url"`
+	TokenURL    string   `mapstructure:"token_url"`
+	JWKSURL     string   `mapstructure:"jwks_url"`
+	Secret      OIDCSecret
+}
+
+// OIDCSecret is read from OIDC_<PROVIDER>_CLIENT_SECRET.
+type OIDCSecret struct {
+	ClientSecret string `split_words:"true"`
 }
 
 type Cookie struct {
@@ -42,6 +181,14 @@ type Cookie struct {
 	Domain   string `mapstructure:"domain"`
 	Secure   bool   `mapstructure:"secure"`
 	HttpOnly bool   `mapstructure:"http_only"`
+	// SameSite is lax, strict or none, lax if it is empty
+	SameSite string `mapstructure:"same_site"`
+}
+
+// Refresh selects how refresh tokens travel between the client and the server:
+// cookie for browsers or body for clients without cookies, e.g. mobile apps.
+type Refresh struct {
+	Transport string `mapstructure:"transport"`
 }
 
 func InitConfig(folder, file string) (*Config, error) {
@@ -64,20 +211,54 @@ func InitConfig(folder, file string) (*Config, error) {
 	return cfg, nil
 }
 
+// parseConfig decodes every section into its own field, sections of the whole
+// Config would collide with keys of other sections, e.g. tokens_ttl.jwt and jwt.
 func parseConfig(cfg *Config) error {
 	if err := viper.Unmarshal(cfg); err != nil {
 		return err
 	}
 
-	if err := viper.UnmarshalKey("db", cfg); err != nil {
+	if err := viper.UnmarshalKey("db", &cfg.DB); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("tokens_ttl", &cfg.Auth); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("cookie", &cfg.Cookie); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("refresh_token", &cfg.Refresh); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("password", &cfg.Password); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("jwt", &cfg.JWT); err != nil {
 		return err
 	}
 
-	if err := viper.UnmarshalKey("tokens_ttl", cfg); err != nil {
+	if err := viper.UnmarshalKey("mailer", &cfg.Mailer); err != nil {
 		return err
 	}
 
-	if err := viper.UnmarshalKey("cookie", cfg); err != nil {
+	if err := viper.UnmarshalKey("sign_in", &cfg.SignIn); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("two_factor", &cfg.TwoFactor); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("audit", &cfg.Audit); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("oidc", &cfg.OIDC); err != nil {
 		return err
 	}
 
@@ -97,5 +278,20 @@ func parseEnv(cfg *Config) error {
 		return err
 	}
 
+	if err := envconfig.Process("smtp", &cfg.Mailer.SMTP.Password); err != nil {
+		return err
+	}
+
+	if err := envconfig.Process("two_factor", &cfg.TwoFactor.Key); err != nil {
+		return err
+	}
+
+	for name, provider := range cfg.OIDC.Providers {
+		if err := envconfig.Process("oidc_"+name, &provider.Secret); err != nil {
+			return err
+		}
+		cfg.OIDC.Providers[name] = provider
+	}
+
 	return nil
 }
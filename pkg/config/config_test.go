@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.AddConfigPath("../../configs")
+	viper.SetConfigName("main")
+	assert.NoError(t, viper.ReadInConfig())
+
+	cfg := new(Config)
+	assert.NoError(t, parseConfig(cfg))
+
+	assert.Equal(t, cfg.ServerPort, "8000")
+	assert.Empty(t, cfg.TrustedProxies)
+	assert.Equal(t, cfg.DB.DBName, "postgres")
+	assert.Equal(t, cfg.Auth.JWT, 15*time.Minute)
+	assert.Equal(t, cfg.Auth.TwoFactor, 5*time.Minute)
+	assert.Equal(t, cfg.Auth.OIDC, 10*time.Minute)
+	assert.Equal(t, cfg.JWT.Algorithm, "HS256")
+	assert.Equal(t, cfg.TwoFactor.Issuer, "crud_app")
+	assert.Empty(t, cfg.OIDC.Providers)
+	assert.Equal(t, cfg.SignIn.Lockout.Threshold, 5)
+	assert.Equal(t, cfg.Password.Policy.MinLength, 8)
+	assert.Equal(t, cfg.Refresh.Transport, "cookie")
+	assert.Equal(t, cfg.Cookie.SameSite, "lax")
+	assert.Equal(t, cfg.Mailer.Driver, "log")
+	assert.Equal(t, cfg.Audit.Retention, 2160*time.Hour)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// getAccessTokens godoc
+//
+//	@Summary		getAccessTokens
+//	@Security		ApiKeyAuth
+//	@Description	list personal access tokens of the user
+//	@Tags			me
+//	@Produce		json
+//	@Success		200		{array}		dto.AccessTokenDTO
+//	@Failure		401		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/me/tokens [get]
+func (h *Handler) getAccessTokens(ctx *gin.Context) {
+	userId := ctx.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(ctx, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	tokens, err := h.service.AuthService.GetAccessTokens(userId)
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// createAccessToken godoc
+//
+//	@Summary		createAccessToken
+//	@Security		ApiKeyAuth
+//	@Description	create a personal access token for automation, the token is only shown once.
+//	@Description	It is sent as "Bearer pat_..." and allows the routes of its scopes.
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		dto.CreateAccessTokenDTO	true	"name, scopes and expiry"
+//	@Success		201		{object}	dto.CreatedAccessTokenDTO
+//	@Failure		400		{object}	errResponse
+//	@Failure		401		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/me/tokens [post]
+func (h *Handler) createAccessToken(ctx *gin.Context) {
+	userId := ctx.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(ctx, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	var input dto.CreateAccessTokenDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	token, err := h.service.AuthService.CreateAccessToken(userId, input)
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, token)
+}
+
+// deleteAccessToken godoc
+//
+//	@Summary		deleteAccessToken
+//	@Security		ApiKeyAuth
+//	@Description	revoke a personal access token of the user by id
+//	@Tags			me
+//	@Produce		json
+//	@Param			id		path		integer	true	"token id"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		401		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/me/tokens/{id} [delete]
+func (h *Handler) deleteAccessToken(ctx *gin.Context) {
+	userId := ctx.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(ctx, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	tokenId, err := pathIdParam(ctx, "id")
+	if err != nil {
+		newErrResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.AuthService.DeleteAccessToken(tokenId, userId); err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	mock_services "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_getAccessTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	s := mock_services.NewMockAuthService(ctrl)
+	s.EXPECT().GetAccessTokens(int64(1)).Return([]dto.AccessTokenDTO{
+		{Id: 3, Name: "ci", Scopes: []string{dto.ScopeProjectsRead}, CreatedAt: createdAt, ExpiresAt: expiresAt},
+	}, nil)
+
+	serv := services.AbstractService{AuthService: s}
+	h := Handler{service: &serv}
+
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("user_id", int64(1))
+	})
+	r.GET("/me/tokens", h.getAccessTokens)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/me/tokens", nil)
+
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.Equal(t, rec.Body.String(), `[{"id":3,"name":"ci","scopes":["projects:read"],`+
+		`"created_at":"2024-12-01T00:00:00Z","expires_at":"2025-01-01T00:00:00Z","last_used_at":null}]`)
+}
+
+func TestHandler_createAccessToken(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService)
+
+	cases := []struct {
+		name           string
+		userId         int64
+		body           string
+		mockBehavior   mockBehavior
+		expectedStatus int
+		expectedFields []string
+	}{
+		{
+			name:   "OK",
+			userId: 1,
+			body:   `{"name":"ci","scopes":["projects:read"],"expires_in_days":30}`,
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().CreateAccessToken(int64(1), dto.CreateAccessTokenDTO{
+					Name: "ci", Scopes: []string{dto.ScopeProjectsRead}, ExpiresInDays: 30,
+				}).Return(dto.CreatedAccessTokenDTO{AccessTokenDTO: dto.AccessTokenDTO{Id: 3}, Token: "pat_token"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Unauthorized",
+			body:           `{"name":"ci","scopes":["projects:read"],"expires_in_days":30}`,
+			mockBehavior:   func(s *mock_services.MockAuthService) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Unknown scope",
+			userId:         1,
+			body:           `{"name":"ci","scopes":["admin"],"expires_in_days":30}`,
+			mockBehavior:   func(s *mock_services.MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"scopes[0]"},
+		},
+		{
+			name:           "Without scopes and too long expiry",
+			userId:         1,
+			body:           `{"name":"ci","scopes":[],"expires_in_days":366}`,
+			mockBehavior:   func(s *mock_services.MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"scopes", "expires_in_days"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s)
+
+			serv := services.AbstractService{AuthService: s}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			r.POST("/me/tokens", h.createAccessToken)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/me/tokens", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedFields != nil {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				fields := make([]string, len(responseBody.Errors))
+				for i, fe := range responseBody.Errors {
+					fields[i] = fe.Field
+				}
+				assert.Equal(t, fields, c.expectedFields)
+			}
+		})
+	}
+}
+
+func TestHandler_deleteAccessToken(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService)
+
+	cases := []struct {
+		name           string
+		id             string
+		mockBehavior   mockBehavior
+		expectedStatus int
+	}{
+		{
+			name: "OK",
+			id:   "3",
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().DeleteAccessToken(int64(3), int64(1)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid id",
+			id:             "abc",
+			mockBehavior:   func(s *mock_services.MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Not found",
+			id:   "3",
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().DeleteAccessToken(int64(3), int64(1)).
+					Return(apperr.NotFound("access_token_not_found", "access token not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s)
+
+			serv := services.AbstractService{AuthService: s}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", int64(1))
+			})
+			r.DELETE("/me/tokens/:id", h.deleteAccessToken)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/me/tokens/"+c.id, nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+		})
+	}
+}
+
+func TestHandler_requireScope(t *testing.T) {
+	cases := []struct {
+		name           string
+		scopes         []string
+		expectedStatus int
+	}{
+		{
+			name:           "Sign in token",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Token with the scope",
+			scopes:         []string{dto.ScopeProjectsRead, dto.ScopeProjectsWrite},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Token without the scope",
+			scopes:         []string{dto.ScopeProjectsRead},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := Handler{}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				if c.scopes != nil {
+					ctx.Set("scopes", c.scopes)
+				}
+			})
+			r.POST("/projects", h.requireScope(dto.ScopeProjectsWrite), func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/projects", nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+		})
+	}
+}
+
+func TestHandler_requireSession(t *testing.T) {
+	h := Handler{}
+
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") != "" {
+			ctx.Set("scopes", []string{dto.ScopeProjectsRead})
+		}
+	})
+	r.GET("/me", h.requireSession, func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/me", nil))
+
+	assert.Equal(t, rec.Code, http.StatusOK)
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer pat_token")
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, rec.Code, http.StatusForbidden)
+}
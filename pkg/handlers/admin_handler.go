@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// adminListUsers godoc
+//
+//	@Summary		adminListUsers
+//	@Security		ApiKeyAuth
+//	@Description	get a page of users ordered by id
+//	@Tags			admin
+//	@Produce		json
+//	@Param			limit		query		integer	false	"page size (1-100)"
+//	@Param			cursor		query		string	false	"next_cursor of the previous page"
+//	@Param			search		query		string	false	"search in name, email and username"
+//	@Param			role		query		string	false	"filter by role"	Enums(user, admin)
+//	@Param			disabled	query		boolean	false	"filter by disabled accounts"
+//	@Success		200			{object}	dto.UserPageDTO
+//	@Failure		400			{object}	errResponse
+//	@Failure		401			{object}	errResponse
+//	@Failure		403			{object}	errResponse
+//	@Failure		default		{object}	errResponse
+//	@Router			/api/admin/users [get]
+func (h *Handler) adminListUsers(ctx *gin.Context) {
+	var query dto.UserQueryDTO
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	if err := query.Validate(); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	page, err := h.service.AdminService.ListUsers(query)
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// adminGetUser godoc
+//
+//	@Summary		adminGetUser
+//	@Security		ApiKeyAuth
+//	@Description	get any user by id
+//	@Tags			admin
+//	@Produce		json
+//	@Param			id		path		integer	true	"user id"
+//	@Success		200		{object}	dto.AdminUserDTO
+//	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/admin/users/{id} [get]
+func (h *Handler) adminGetUser(ctx *gin.Context) {
+	userId, err := pathIdParam(ctx, "id")
+	if err != nil {
+		newErrResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.service.AdminService.GetUserById(userId)
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// adminDisableUser godoc
+//
+//	@Summary		adminDisableUser
+//	@Security		ApiKeyAuth
+//	@Description	disable an account and revoke its sessions together with their access tokens
+//	@Tags			admin
+//	@Produce		json
+//	@Param			id		path		integer	true	"user id"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		409		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/admin/users/{id}/disable [post]
+func (h *Handler) adminDisableUser(ctx *gin.Context) {
+	userId, err := pathIdParam(ctx, "id")
+	if err != nil {
+		newErrResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.AdminService.DisableUser(ctx.GetInt64("user_id"), userId); err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+	h.revokeUserTokens(userId)
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// adminEnableUser godoc
+//
+//	@Summary		adminEnableUser
+//	@Security		ApiKeyAuth
+//	@Description	enable a disabled account
+//	@Tags			admin
+//	@Produce		json
+//	@Param			id		path		integer	true	"user id"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/admin/users/{id}/enable [post]
+func (h *Handler) adminEnableUser(ctx *gin.Context) {
+	userId, err := pathIdParam(ctx, "id")
+	if err != nil {
+		newErrResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.AdminService.EnableUser(userId); err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// adminSignOutUser godoc
+//
+//	@Summary		adminSignOutUser
+//	@Security		ApiKeyAuth
+//	@Description	revoke every session of a user
+//	@Tags			admin
+//	@Produce		json
+//	@Param			id		path		integer	true	"user id"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/admin/users/{id}/sign-out [post]
+func (h *Handler) adminSignOutUser(ctx *gin.Context) {
+	userId, err := pathIdParam(ctx, "id")
+	if err != nil {
+		newErrResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.AdminService.SignOutUser(userId); err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+	h.revokeUserTokens(userId)
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// adminListProjects godoc
+//
+//	@Summary		adminListProjects
+//	@Security		ApiKeyAuth
+//	@Description	get a page of projects of any user
+//	@Tags			admin
+//	@Produce		json
+//	@Param			id				path		integer	true	"user id"
+//	@Param			limit			query		integer	false	"page size (1-100)"
+//	@Param			cursor			query		string	false	"next_cursor of the previous page"
+//	@Param			done			query		boolean	false	"filter by done flag"
+//	@Param			search			query		string	false	"search in title and description"
+//	@Param			created_from	query		string	false	"created at or after (RFC 3339)"
+//	@Param			created_to		query		string	false	"created at or before (RFC 3339)"
+//	@Param			sort			query		string	false	"sort field"	Enums(title, created_at, updated_at)
+//	@Param			order			query		string	false	"sort order"	Enums(asc, desc)
+//	@Success		200				{object}	dto.ProjectPageDTO
+//	@Failure		400				{object}	errResponse
+//	@Failure		403				{object}	errResponse
+//	@Failure		default			{object}	errResponse
+//	@Router			/api/admin/users/{id}/projects [get]
+func (h *Handler) adminListProjects(ctx *gin.Context) {
+	userId, err := pathIdParam(ctx, "id")
+	if err != nil {
+		newErrResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var query dto.ProjectQueryDTO
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	query.SetDefaults()
+	if err := query.Validate(); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	page, err := h.service.ProjectService.List(userId, query)
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// adminListAuditEvents godoc
+//
+//	@Summary		adminListAuditEvents
+//	@Security		ApiKeyAuth
+//	@Description	get a page of the audit log of authentication events, newest first
+//	@Tags			admin
+//	@Produce		json
+//	@Param			limit	query		integer	false	"page size (1-100)"
+//	@Param			cursor	query		string	false	"next_cursor of the previous page"
+//	@Param			user_id	query		integer	false	"filter by user id"
+//	@Param			event	query		string	false	"filter by event"	Enums(sign_up, sign_in, sign_in_failed, sign_in_locked, sign_in_unlocked, token_refreshed, refresh_rejected, refresh_token_reuse, password_changed, password_reset, identity_linked)
+//	@Param			ip		query		string	false	"filter by client IP"
+//	@Param			from	query		string	false	"events at or after the time (RFC 3339)"
+//	@Param			to		query		string	false	"events before the time (RFC 3339)"
+//	@Success		200		{object}	dto.AuditPageDTO
+//	@Failure		400		{object}	errResponse
+//	@Failure		401		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/admin/audit-events [get]
+func (h *Handler) adminListAuditEvents(ctx *gin.Context) {
+	var query dto.AuditQueryDTO
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	if err := query.Validate(); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	page, err := h.service.AdminService.ListAuditEvents(query)
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
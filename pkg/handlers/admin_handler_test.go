@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	mock_services "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/mocks"
+	"github.com/DmytroBeliasnyk/in_memory_cache/memory"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_adminListUsers(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAdminService)
+
+	cases := []struct {
+		name           string
+		query          string
+		mockBehavior   mockBehavior
+		expectedStatus int
+	}{
+		{
+			name:  "OK",
+			query: "?search=name&role=admin&cursor=" + dto.EncodeIdCursor(3),
+			mockBehavior: func(s *mock_services.MockAdminService) {
+				s.EXPECT().ListUsers(dto.UserQueryDTO{
+					Limit: dto.DefaultUsersLimit, Cursor: dto.EncodeIdCursor(3), Search: "name", Role: "admin", AfterId: 3,
+				}).Return(dto.UserPageDTO{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unknown role",
+			query:          "?role=root",
+			mockBehavior:   func(s *mock_services.MockAdminService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid cursor",
+			query:          "?cursor=invalid",
+			mockBehavior:   func(s *mock_services.MockAdminService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAdminService(ctrl)
+			c.mockBehavior(s)
+
+			serv := services.AbstractService{AdminService: s}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.GET("/admin/users", h.adminListUsers)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/admin/users"+c.query, nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+		})
+	}
+}
+
+func TestHandler_adminDisableUser(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAdminService)
+
+	cases := []struct {
+		name           string
+		id             string
+		mockBehavior   mockBehavior
+		expectedStatus int
+		expectedCode   string
+		revoked        bool
+	}{
+		{
+			name: "OK",
+			id:   "2",
+			mockBehavior: func(s *mock_services.MockAdminService) {
+				s.EXPECT().DisableUser(int64(1), int64(2)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			revoked:        true,
+		},
+		{
+			name:           "Invalid id",
+			id:             "abc",
+			mockBehavior:   func(s *mock_services.MockAdminService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_request",
+		},
+		{
+			name: "Own account",
+			id:   "1",
+			mockBehavior: func(s *mock_services.MockAdminService) {
+				s.EXPECT().DisableUser(int64(1), int64(1)).
+					Return(apperr.Conflict("disable_self", "admins can not disable their own account"))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "disable_self",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAdminService(ctrl)
+			c.mockBehavior(s)
+
+			serv := services.AbstractService{AdminService: s}
+			h := Handler{service: &serv, cache: new(memory.Cache), tokenTTL: time.Hour}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", int64(1))
+			})
+			r.POST("/admin/users/:id/disable", h.adminDisableUser)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/admin/users/"+c.id+"/disable", nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			assert.Equal(t, userTokensRevoked(&h, 2), c.revoked)
+			if c.expectedCode != "" {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, responseBody.Code, c.expectedCode)
+			}
+		})
+	}
+}
+
+func TestHandler_adminSignOutUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := mock_services.NewMockAdminService(ctrl)
+	s.EXPECT().SignOutUser(int64(2)).Return(nil)
+	s.EXPECT().SignOutUser(int64(3)).Return(apperr.NotFound("user_not_found", "user not found"))
+
+	serv := services.AbstractService{AdminService: s}
+	h := Handler{service: &serv, cache: new(memory.Cache), tokenTTL: time.Hour}
+
+	r := gin.New()
+	r.POST("/admin/users/:id/sign-out", h.adminSignOutUser)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/users/2/sign-out", nil))
+
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.True(t, userTokensRevoked(&h, 2))
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/users/3/sign-out", nil))
+
+	assert.Equal(t, rec.Code, http.StatusNotFound)
+	assert.False(t, userTokensRevoked(&h, 3))
+}
+
+func TestHandler_adminListProjects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := mock_services.NewMockProjectService(ctrl)
+	s.EXPECT().List(int64(2), dto.ProjectQueryDTO{Limit: dto.DefaultProjectsLimit, Sort: "created_at", Order: "asc"}).
+		Return(dto.ProjectPageDTO{Items: []dto.ProjectResponseDTO{}}, nil)
+
+	serv := services.AbstractService{ProjectService: s}
+	h := Handler{service: &serv}
+
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("user_id", int64(1))
+	})
+	r.GET("/admin/users/:id/projects", h.adminListProjects)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/users/2/projects", nil))
+
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.Equal(t, rec.Body.String(), `{"items":[]}`)
+}
+
+func TestHandler_requireRole(t *testing.T) {
+	cases := []struct {
+		name           string
+		role           string
+		expectedStatus int
+	}{
+		{
+			name:           "Admin",
+			role:           dto.RoleAdmin,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "User",
+			role:           dto.RoleUser,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Personal access token",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := Handler{}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				if c.role != "" {
+					ctx.Set("role", c.role)
+				}
+			})
+			r.GET("/admin/users", h.requireRole(dto.RoleAdmin), func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/users", nil))
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+		})
+	}
+}
+
+func TestHandler_adminListAuditEvents(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAdminService)
+
+	from := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name           string
+		query          string
+		mockBehavior   mockBehavior
+		expectedStatus int
+	}{
+		{
+			name:  "OK",
+			query: "?user_id=1&event=sign_in&from=2024-11-01T00:00:00Z&cursor=" + dto.EncodeIdCursor(9),
+			mockBehavior: func(s *mock_services.MockAdminService) {
+				s.EXPECT().ListAuditEvents(dto.AuditQueryDTO{
+					Limit: dto.DefaultAuditEventsLimit, Cursor: dto.EncodeIdCursor(9), UserId: 1, Event: "sign_in",
+					From: &from, BeforeId: 9,
+				}).Return(dto.AuditPageDTO{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid time",
+			query:          "?from=yesterday",
+			mockBehavior:   func(s *mock_services.MockAdminService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid IP",
+			query:          "?ip=localhost",
+			mockBehavior:   func(s *mock_services.MockAdminService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid cursor",
+			query:          "?cursor=invalid",
+			mockBehavior:   func(s *mock_services.MockAdminService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAdminService(ctrl)
+			c.mockBehavior(s)
+
+			serv := services.AbstractService{AdminService: s}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.GET("/admin/audit-events", h.adminListAuditEvents)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/admin/audit-events"+c.query, nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+		})
+	}
+}
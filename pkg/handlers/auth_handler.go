@@ -1,7 +1,10 @@
 package handlers
 
 import (
-	"database/sql"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -9,6 +12,16 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// refreshTransportBody is the transport of refresh tokens for clients without cookies
+	refreshTransportBody = "body"
+	refreshTokenHeader   = "X-Refresh-Token"
+
+	// csrfCookie is echoed by the client in csrfHeader when it uses the refresh cookie
+	csrfCookie = "csrf-token"
+	csrfHeader = "X-CSRF-Token"
+)
+
 // signUp godoc
 //
 //	@Summary	signUp
@@ -18,24 +31,25 @@ import (
 //	@Param		SignUpDTO	body		dto.SignUpDTO	true	"user details"
 //	@Success	201			{integer}	integer			user_id
 //	@Failure	400			{object}	errResponse
+//	@Failure	409			{object}	errResponse
 //	@Failure	500			{object}	errResponse
 //	@Failure	default		{object}	errResponse
 //	@Router		/auth/sign-up [post]
 func (h *Handler) signUp(ctx *gin.Context) {
 	var input dto.SignUpDTO
-	if err := ctx.BindJSON(&input); err != nil {
-		newErrResponse(ctx, http.StatusBadRequest, err.Error())
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(ctx, err)
 		return
 	}
 
 	if err := input.Validate(); err != nil {
-		newErrResponse(ctx, http.StatusBadRequest, err.Error())
+		newValidationErrResponse(ctx, err)
 		return
 	}
 
-	id, err := h.service.AuthService.SignUp(input)
+	id, err := h.service.AuthService.SignUp(input, clientInfo(ctx))
 	if err != nil {
-		newErrResponse(ctx, http.StatusInternalServerError, err.Error())
+		newServiceErrResponse(ctx, err)
 		return
 	}
 
@@ -46,77 +60,268 @@ func (h *Handler) signUp(ctx *gin.Context) {
 
 // signIn godoc
 //
-//	@Summary	signIn
-//	@Tags		auth
-//	@Accept		json
-//	@Produce	json
-//	@Param		SignInDTO	body		dto.SignInDTO	true	"user details"
-//	@Header		200			{string}	Set-Cookie		"set new refresh token"
-//	@Success	200			{string}	string			jwt
-//	@Failure	400			{object}	errResponse
-//	@Failure	401			{object}	errResponse
-//	@Failure	default		{object}	errResponse
-//	@Router		/auth/sign-in [post]
+//	@Summary		signIn
+//	@Description	sign in with either the username or the email, both are matched case-insensitively.
+//	@Description	Users with two-factor authentication get a challenge token for /auth/sign-in/2fa instead of tokens.
+//	@Description	Repeated failures lock the account and the client IP out, 429 tells when to retry in Retry-After.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			SignInDTO	body		dto.SignInDTO	true	"user details"
+//	@Header			200			{string}	Set-Cookie		"set new refresh token"
+//	@Success		200			{string}	string			jwt
+//	@Success		202			{object}	dto.TwoFactorChallengeDTO
+//	@Failure		400			{object}	errResponse
+//	@Failure		401			{object}	errResponse
+//	@Failure		429			{object}	errResponse
+//	@Failure		default		{object}	errResponse
+//	@Router			/auth/sign-in [post]
 func (h *Handler) signIn(ctx *gin.Context) {
 	var input dto.SignInDTO
-	if err := ctx.BindJSON(&input); err != nil {
-		newErrResponse(ctx, http.StatusBadRequest, err.Error())
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(ctx, err)
 		return
 	}
 
-	userId, err := h.service.AuthService.SignIn(input)
+	userId, challenge, err := h.service.AuthService.SignIn(input, clientInfo(ctx))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			newErrResponse(ctx, http.StatusUnauthorized, "invalid username or password")
-			return
-		}
+		newServiceErrResponse(ctx, err)
+		return
+	}
 
-		newErrResponse(ctx, http.StatusInternalServerError, err.Error())
+	if challenge != "" {
+		ctx.JSON(http.StatusAccepted, dto.TwoFactorChallengeDTO{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		})
 		return
 	}
 
-	jt, rt, err := h.service.AuthService.GenerateTokens(userId)
+	h.issueTokens(ctx, userId)
+}
+
+// issueTokens starts a session of the signed in user.
+func (h *Handler) issueTokens(ctx *gin.Context, userId int64) {
+	jt, rt, err := h.service.AuthService.GenerateTokens(userId, clientInfo(ctx))
 	if err != nil {
-		newErrResponse(ctx, http.StatusInternalServerError, err.Error())
+		newServiceErrResponse(ctx, err)
 		return
 	}
 
-	ctx.SetCookie(h.cfg.name, rt, h.cfg.age, h.cfg.path, h.cfg.domain, h.cfg.secure, h.cfg.httpOnly)
-	ctx.JSON(http.StatusOK, map[string]interface{}{
-		"Bearer": jt,
-	})
+	h.respondTokens(ctx, jt, rt)
 }
 
 // refresh godoc
 //
 //	@Summary		refresh
-//	@Description	refreshing jwt
+//	@Description	refreshing jwt. In cookie mode the refresh token is read from the cookie and X-CSRF-Token
+//	@Description	has to match the csrf-token cookie. In body mode it is read from X-Refresh-Token or the body
+//	@Description	and the new one is returned as refresh_token.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
-//	@Param			refresh-token	header		string		true	"refresh token from cookie"
-//	@Header			200				{string}	Set-Cookie	"Set new refresh token"
-//	@Success		200				{string}	string		jwt
+//	@Param			X-CSRF-Token	header		string				false	"value of the csrf-token cookie in cookie mode"
+//	@Param			X-Refresh-Token	header		string				false	"refresh token in body mode"
+//	@Param			input			body		dto.RefreshTokenDTO	false	"refresh token in body mode"
+//	@Header			200				{string}	Set-Cookie			"Set new refresh token"
+//	@Success		200				{string}	string				jwt
 //	@Failure		400				{object}	errResponse
+//	@Failure		401				{object}	errResponse
+//	@Failure		403				{object}	errResponse
 //	@Failure		default			{object}	errResponse
 //	@Router			/auth/refresh [get]
+//	@Router			/auth/refresh [post]
 func (h *Handler) refresh(ctx *gin.Context) {
-	rt, err := ctx.Cookie("refresh-token")
-	if err != nil {
-		newErrResponse(ctx, http.StatusBadRequest, err.Error())
+	rt, ok := h.refreshToken(ctx)
+	if !ok {
+		newErrResponse(ctx, http.StatusBadRequest, "refresh token is missing")
 		return
 	}
 
-	jt, rt, err := h.service.AuthService.UpdateTokens(rt)
+	jt, rt, err := h.service.AuthService.UpdateTokens(rt, clientInfo(ctx))
 	if err != nil {
-		newErrResponse(ctx, http.StatusBadRequest, err.Error())
+		newServiceErrResponse(ctx, err)
 		return
 	}
 
-	ctx.SetCookie(h.cfg.name, rt, h.cfg.age, h.cfg.path, h.cfg.domain, h.cfg.secure, h.cfg.httpOnly)
-	ctx.JSON(http.StatusOK, map[string]interface{}{
+	h.respondTokens(ctx, jt, rt)
+}
+
+// signOut godoc
+//
+//	@Summary		signOut
+//	@Description	revoking the refresh token of the current session, it is read like by refresh.
+//	@Description	The access tokens of the session are revoked as well when one is sent as "Bearer ...".
+//	@Tags			auth
+//	@Produce		json
+//	@Param			Authorization	header		string				false	"access token of the session"
+//	@Param			X-CSRF-Token	header		string				false	"value of the csrf-token cookie in cookie mode"
+//	@Param			X-Refresh-Token	header		string				false	"refresh token in body mode"
+//	@Param			input			body		dto.RefreshTokenDTO	false	"refresh token in body mode"
+//	@Header			200				{string}	Set-Cookie			"expired refresh token"
+//	@Success		200				{object}	statusResponse
+//	@Failure		400				{object}	errResponse
+//	@Failure		403				{object}	errResponse
+//	@Failure		default			{object}	errResponse
+//	@Router			/auth/sign-out [post]
+func (h *Handler) signOut(ctx *gin.Context) {
+	rt, ok := h.refreshToken(ctx)
+	if !ok {
+		newErrResponse(ctx, http.StatusBadRequest, "refresh token is missing")
+		return
+	}
+
+	if err := h.service.AuthService.SignOut(rt); err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	// the access tokens of the session are revoked too when one is sent along
+	if token, ok := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer "); ok &&
+		!strings.HasPrefix(token, dto.AccessTokenPrefix) {
+		if claims, err := h.service.AuthService.ParseToken(token); err == nil {
+			h.revokeToken(claims)
+			if claims.SessionId != 0 {
+				h.revokeSession(claims.SessionId)
+			}
+		}
+	}
+
+	h.clearRefreshCookie(ctx)
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// signOutAll godoc
+//
+//	@Summary		signOutAll
+//	@Security		ApiKeyAuth
+//	@Description	revoking the refresh tokens of all sessions of the user
+//	@Tags			auth
+//	@Produce		json
+//	@Header			200		{string}	Set-Cookie	"expired refresh token"
+//	@Success		200		{object}	statusResponse
+//	@Failure		401		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/auth/sign-out-all [post]
+func (h *Handler) signOutAll(ctx *gin.Context) {
+	userId := ctx.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(ctx, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	if err := h.service.AuthService.SignOutAll(userId); err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+	h.revokeUserTokens(userId)
+
+	h.clearRefreshCookie(ctx)
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// jwks godoc
+//
+//	@Summary		jwks
+//	@Description	public keys access tokens can be verified with
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200	{object}	dto.JWKSetDTO
+//	@Router			/.well-known/jwks.json [get]
+func (h *Handler) jwks(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, h.service.AuthService.JWKS())
+}
+
+// respondTokens sends the access token and the refresh token of a session. In cookie
+// mode the refresh token is set as a cookie together with the CSRF cookie,
+// otherwise it is sent in the body.
+func (h *Handler) respondTokens(ctx *gin.Context, jt, rt string) {
+	res := map[string]interface{}{
 		"Bearer": jt,
-	})
+	}
+
+	if h.cfg.bodyTransport {
+		res["refresh_token"] = rt
+	} else {
+		csrf, err := newCSRFToken()
+		if err != nil {
+			newErrResponse(ctx, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		ctx.SetSameSite(h.cfg.sameSite)
+		ctx.SetCookie(h.cfg.name, rt, h.cfg.age, h.cfg.path, h.cfg.domain, h.cfg.secure, h.cfg.httpOnly)
+		// scripts of the client read it on any page, so it is neither limited to the path nor HttpOnly
+		ctx.SetCookie(csrfCookie, csrf, h.cfg.age, "/", h.cfg.domain, h.cfg.secure, false)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// refreshToken reads the refresh token of the request: the cookie in cookie mode,
+// otherwise the X-Refresh-Token header or the refresh_token of the JSON body.
+func (h *Handler) refreshToken(ctx *gin.Context) (string, bool) {
+	if !h.cfg.bodyTransport {
+		rt, err := ctx.Cookie(h.cfg.name)
+		return rt, err == nil && rt != ""
+	}
+
+	if rt := ctx.GetHeader(refreshTokenHeader); rt != "" {
+		return rt, true
+	}
+
+	var input dto.RefreshTokenDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		return "", false
+	}
+
+	return input.RefreshToken, true
+}
+
+func (h *Handler) clearRefreshCookie(ctx *gin.Context) {
+	if h.cfg.bodyTransport {
+		return
+	}
+
+	ctx.SetSameSite(h.cfg.sameSite)
+	ctx.SetCookie(h.cfg.name, "", -1, h.cfg.path, h.cfg.domain, h.cfg.secure, h.cfg.httpOnly)
+	ctx.SetCookie(csrfCookie, "", -1, "/", h.cfg.domain, h.cfg.secure, false)
+}
+
+// requireCSRF protects the routes that read the refresh cookie from cross-site requests
+// with a double-submit token: the X-CSRF-Token header has to match the CSRF cookie,
+// which only scripts of the site the cookies belong to can read.
+func (h *Handler) requireCSRF(ctx *gin.Context) {
+	if h.cfg.bodyTransport {
+		return
+	}
+
+	cookie, err := ctx.Cookie(csrfCookie)
+	header := ctx.GetHeader(csrfHeader)
+	if err != nil || cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+		abortWithProblem(ctx, http.StatusForbidden, "invalid_csrf_token",
+			"CSRF token is missing or does not match the cookie", nil)
+		return
+	}
+}
+
+func newCSRFToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// clientInfo describes the client of the request for the session it starts or uses.
+func clientInfo(ctx *gin.Context) dto.ClientInfo {
+	return dto.ClientInfo{
+		UserAgent: ctx.Request.UserAgent(),
+		IP:        ctx.ClientIP(),
+		RequestId: ctx.GetString("request_id"),
+	}
 }
 
 func (h *Handler) middlewareAuth(ctx *gin.Context) {
@@ -132,11 +337,66 @@ func (h *Handler) middlewareAuth(ctx *gin.Context) {
 		return
 	}
 
-	id, err := h.service.AuthService.ParseToken(auth[1])
+	if strings.HasPrefix(auth[1], dto.AccessTokenPrefix) {
+		id, scopes, err := h.service.AuthService.ParseAccessToken(auth[1])
+		if err != nil {
+			newServiceErrResponse(ctx, err)
+			return
+		}
+
+		ctx.Set("user_id", id)
+		ctx.Set("scopes", scopes)
+		return
+	}
+
+	claims, err := h.service.AuthService.ParseToken(auth[1])
 	if err != nil {
 		newErrResponse(ctx, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	ctx.Set("user_id", id)
+	if h.tokenRevoked(claims) {
+		newErrResponse(ctx, http.StatusUnauthorized, "token has been revoked")
+		return
+	}
+
+	ctx.Set("user_id", claims.UserId)
+	ctx.Set("role", claims.Role)
+}
+
+// requireRole rejects users without the role. Personal access tokens carry
+// no role, so they are rejected as well.
+func (h *Handler) requireRole(role string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetString("role") != role {
+			newErrResponse(ctx, http.StatusForbidden, fmt.Sprintf("route requires the %s role", role))
+		}
+	}
+}
+
+// requireScope rejects personal access tokens without the scope.
+// Access tokens of a sign in have no scopes and are allowed every route.
+func (h *Handler) requireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scopes, ok := ctx.Get("scopes")
+		if !ok {
+			return
+		}
+
+		for _, s := range scopes.([]string) {
+			if s == scope {
+				return
+			}
+		}
+
+		newErrResponse(ctx, http.StatusForbidden, fmt.Sprintf("access token has no %s scope", scope))
+	}
+}
+
+// requireSession rejects personal access tokens on routes that manage the account,
+// so that a leaked token can not be used to issue more tokens or take the account over.
+func (h *Handler) requireSession(ctx *gin.Context) {
+	if _, ok := ctx.Get("scopes"); ok {
+		newErrResponse(ctx, http.StatusForbidden, "personal access tokens can not be used for this route")
+	}
 }
@@ -2,16 +2,18 @@ package handlers
 
 import (
 	"bytes"
-	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
 	mock_services "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/mocks"
 	"github.com/DmytroBeliasnyk/in_memory_cache/memory"
 	"github.com/gin-gonic/gin"
@@ -19,6 +21,9 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// testClient is the client of requests built by httptest.NewRequest.
+var testClient = dto.ClientInfo{IP: "192.0.2.1"}
+
 func TestHandler_signUp(t *testing.T) {
 	type mockBehavior func(s *mock_services.MockAuthService, input dto.SignUpDTO)
 
@@ -41,7 +46,7 @@ func TestHandler_signUp(t *testing.T) {
 				Password: "password",
 			},
 			mockBehavior: func(s *mock_services.MockAuthService, input dto.SignUpDTO) {
-				s.EXPECT().SignUp(input).Return(int64(1), nil)
+				s.EXPECT().SignUp(input, testClient).Return(int64(1), nil)
 			},
 			expectedStatus: http.StatusCreated,
 		},
@@ -73,11 +78,27 @@ func TestHandler_signUp(t *testing.T) {
 				Password: "password",
 			},
 			mockBehavior: func(s *mock_services.MockAuthService, input dto.SignUpDTO) {
-				s.EXPECT().SignUp(input).Return(int64(0), errors.New("some error"))
+				s.EXPECT().SignUp(input, testClient).Return(int64(0), errors.New("some error"))
 			},
 			expectedStatus:      http.StatusInternalServerError,
 			expectedErrResponse: true,
 		},
+		{
+			name: "Username taken",
+			body: `{"name":"Name","email":"aaa@bbb.ccc",
+				"username":"username","password":"password"}`,
+			input: dto.SignUpDTO{
+				Name:     "Name",
+				Email:    "aaa@bbb.ccc",
+				Username: "username",
+				Password: "password",
+			},
+			mockBehavior: func(s *mock_services.MockAuthService, input dto.SignUpDTO) {
+				s.EXPECT().SignUp(input, testClient).Return(int64(0), apperr.Conflict("username_taken", "username is already taken"))
+			},
+			expectedStatus:      http.StatusConflict,
+			expectedErrResponse: true,
+		},
 	}
 
 	for _, c := range cases {
@@ -101,14 +122,13 @@ func TestHandler_signUp(t *testing.T) {
 
 			assert.Equal(t, rec.Code, c.expectedStatus)
 			if c.expectedErrResponse {
-				var responseBody map[string]string
+				var responseBody errResponse
 				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
 				assert.NoError(t, err)
 
-				value, ok := responseBody["message"]
-
-				assert.True(t, ok)
-				assert.NotEmpty(t, value)
+				assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+				assert.Equal(t, responseBody.Status, c.expectedStatus)
+				assert.NotEmpty(t, responseBody.Code)
 			} else {
 				assert.Equal(t, rec.Body.String(), `{"id":1}`)
 			}
@@ -135,8 +155,33 @@ func TestHandler_signIn(t *testing.T) {
 				Password: "password",
 			},
 			mockBehavior: func(s *mock_services.MockAuthService, input dto.SignInDTO) {
-				s.EXPECT().SignIn(input).Return(int64(1), nil)
-				s.EXPECT().GenerateTokens(int64(1)).Return(gomock.Any().String(), gomock.Any().String(), nil)
+				s.EXPECT().SignIn(input, testClient).Return(int64(1), "", nil)
+				s.EXPECT().GenerateTokens(int64(1), testClient).Return(gomock.Any().String(), gomock.Any().String(), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Two-factor challenge",
+			body: `{"username":"username","password":"password"}`,
+			input: dto.SignInDTO{
+				Username: "username",
+				Password: "password",
+			},
+			mockBehavior: func(s *mock_services.MockAuthService, input dto.SignInDTO) {
+				s.EXPECT().SignIn(input, testClient).Return(int64(0), "challenge", nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "Email",
+			body: `{"email":"email@gmail.com","password":"password"}`,
+			input: dto.SignInDTO{
+				Email:    "email@gmail.com",
+				Password: "password",
+			},
+			mockBehavior: func(s *mock_services.MockAuthService, input dto.SignInDTO) {
+				s.EXPECT().SignIn(input, testClient).Return(int64(1), "", nil)
+				s.EXPECT().GenerateTokens(int64(1), testClient).Return("access", "refresh", nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -148,6 +193,20 @@ func TestHandler_signIn(t *testing.T) {
 			expectedStatus:      http.StatusBadRequest,
 			expectedErrResponse: true,
 		},
+		{
+			name:                "Without username and email",
+			body:                `{"password":"password"}`,
+			mockBehavior:        func(s *mock_services.MockAuthService, input dto.SignInDTO) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedErrResponse: true,
+		},
+		{
+			name:                "Both username and email",
+			body:                `{"username":"username","email":"email@gmail.com","password":"password"}`,
+			mockBehavior:        func(s *mock_services.MockAuthService, input dto.SignInDTO) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedErrResponse: true,
+		},
 		{
 			name: "Invalid username or password",
 			body: `{"username":"invalid_username","password":"invalid_password"}`,
@@ -156,11 +215,26 @@ func TestHandler_signIn(t *testing.T) {
 				Password: "invalid_password",
 			},
 			mockBehavior: func(s *mock_services.MockAuthService, input dto.SignInDTO) {
-				s.EXPECT().SignIn(input).Return(int64(0), sql.ErrNoRows)
+				s.EXPECT().SignIn(input, testClient).
+					Return(int64(0), "", apperr.Unauthorized("invalid_credentials", "invalid username or password"))
 			},
 			expectedStatus:      http.StatusUnauthorized,
 			expectedErrResponse: true,
 		},
+		{
+			name: "Locked out",
+			body: `{"username":"username","password":"password"}`,
+			input: dto.SignInDTO{
+				Username: "username",
+				Password: "password",
+			},
+			mockBehavior: func(s *mock_services.MockAuthService, input dto.SignInDTO) {
+				s.EXPECT().SignIn(input, testClient).Return(int64(0), "",
+					apperr.TooManyRequests("sign_in_locked", "too many failed sign in attempts", 89500*time.Millisecond))
+			},
+			expectedStatus:      http.StatusTooManyRequests,
+			expectedErrResponse: true,
+		},
 		{
 			name: "Service failed",
 			body: `{"username":"username","password":"password"}`,
@@ -169,7 +243,7 @@ func TestHandler_signIn(t *testing.T) {
 				Password: "password",
 			},
 			mockBehavior: func(s *mock_services.MockAuthService, input dto.SignInDTO) {
-				s.EXPECT().SignIn(input).Return(int64(0), errors.New("some error"))
+				s.EXPECT().SignIn(input, testClient).Return(int64(0), "", errors.New("some error"))
 			},
 			expectedStatus:      http.StatusInternalServerError,
 			expectedErrResponse: true,
@@ -182,8 +256,8 @@ func TestHandler_signIn(t *testing.T) {
 				Password: "password",
 			},
 			mockBehavior: func(s *mock_services.MockAuthService, input dto.SignInDTO) {
-				s.EXPECT().SignIn(input).Return(int64(1), nil)
-				s.EXPECT().GenerateTokens(int64(1)).Return("", "", errors.New("some error"))
+				s.EXPECT().SignIn(input, testClient).Return(int64(1), "", nil)
+				s.EXPECT().GenerateTokens(int64(1), testClient).Return("", "", errors.New("some error"))
 			},
 			expectedStatus:      http.StatusInternalServerError,
 			expectedErrResponse: true,
@@ -222,17 +296,23 @@ func TestHandler_signIn(t *testing.T) {
 
 			assert.Equal(t, rec.Code, c.expectedStatus)
 			if c.expectedErrResponse {
-				var responseBody map[string]string
+				var responseBody errResponse
 				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
 				assert.NoError(t, err)
 
-				value, ok := responseBody["message"]
-
-				assert.True(t, ok)
-				assert.NotEmpty(t, value)
+				assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+				assert.Equal(t, responseBody.Status, c.expectedStatus)
+				assert.NotEmpty(t, responseBody.Code)
+				if c.expectedStatus == http.StatusTooManyRequests {
+					assert.Equal(t, rec.Header().Get("Retry-After"), "90")
+				}
+			} else if c.expectedStatus == http.StatusAccepted {
+				assert.Empty(t, rec.Result().Cookies())
+				assert.Equal(t, rec.Body.String(), `{"two_factor_required":true,"challenge_token":"challenge"}`)
 			} else {
 				cookie := rec.Result().Cookies()
-				assert.Len(t, cookie, 1)
+				assert.Len(t, cookie, 2)
+				assert.Equal(t, cookie[1].Name, csrfCookie)
 
 				var responseBody map[string]string
 				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
@@ -269,7 +349,7 @@ func TestHandler_refresh(t *testing.T) {
 				HttpOnly: true,
 			},
 			mockBehavior: func(s *mock_services.MockAuthService, token string) {
-				s.EXPECT().UpdateTokens(token).Return(gomock.Any().String(), gomock.Any().String(), nil)
+				s.EXPECT().UpdateTokens(token, testClient).Return(gomock.Any().String(), gomock.Any().String(), nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -307,9 +387,27 @@ func TestHandler_refresh(t *testing.T) {
 				HttpOnly: true,
 			},
 			mockBehavior: func(s *mock_services.MockAuthService, token string) {
-				s.EXPECT().UpdateTokens(token).Return("", "", errors.New("some error"))
+				s.EXPECT().UpdateTokens(token, testClient).Return("", "", errors.New("some error"))
 			},
-			expectedStatus:      http.StatusBadRequest,
+			expectedStatus:      http.StatusInternalServerError,
+			expectedErrResponse: true,
+		},
+		{
+			name: "Invalid token",
+			cookie: http.Cookie{
+				Name:     "refresh-token",
+				Value:    "token",
+				MaxAge:   1000,
+				Path:     "/",
+				Domain:   "/localhost",
+				Secure:   false,
+				HttpOnly: true,
+			},
+			mockBehavior: func(s *mock_services.MockAuthService, token string) {
+				s.EXPECT().UpdateTokens(token, testClient).
+					Return("", "", apperr.Unauthorized("invalid_refresh_token", "invalid refresh token"))
+			},
+			expectedStatus:      http.StatusUnauthorized,
 			expectedErrResponse: true,
 		},
 	}
@@ -347,17 +445,19 @@ func TestHandler_refresh(t *testing.T) {
 
 			assert.Equal(t, rec.Code, c.expectedStatus)
 			if c.expectedErrResponse {
-				var responseBody map[string]string
+				var responseBody errResponse
 				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
 				assert.NoError(t, err)
 
-				value, ok := responseBody["message"]
-
-				assert.True(t, ok)
-				assert.NotEmpty(t, value)
+				assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+				assert.Equal(t, responseBody.Status, c.expectedStatus)
+				assert.NotEmpty(t, responseBody.Code)
 			} else {
 				cookies := rec.Result().Cookies()
-				assert.Len(t, cookies, 1)
+				assert.Len(t, cookies, 2)
+				assert.Equal(t, cookies[0].SameSite, http.SameSiteLaxMode)
+				assert.Equal(t, cookies[1].Name, csrfCookie)
+				assert.False(t, cookies[1].HttpOnly)
 
 				var responseBody map[string]string
 				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
@@ -372,25 +472,275 @@ func TestHandler_refresh(t *testing.T) {
 	}
 }
 
+func TestHandler_signOut(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService)
+
+	claims := dto.TokenClaimsDTO{UserId: 1, Id: "jti", SessionId: 3, ExpiresAt: time.Now().Add(time.Hour)}
+	// another access token refreshed within the same session
+	sibling := dto.TokenClaimsDTO{UserId: 1, Id: "other", SessionId: 3, IssuedAt: time.Now()}
+
+	cases := []struct {
+		name                string
+		cookie              *http.Cookie
+		authorization       string
+		mockBehavior        mockBehavior
+		expectedStatus      int
+		expectedErrResponse bool
+		expectedRevoked     bool
+	}{
+		{
+			name:   "OK",
+			cookie: &http.Cookie{Name: "refresh-token", Value: "token"},
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().SignOut("token").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:          "With access token",
+			cookie:        &http.Cookie{Name: "refresh-token", Value: "token"},
+			authorization: "Bearer access",
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().SignOut("token").Return(nil)
+				s.EXPECT().ParseToken("access").Return(claims, nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedRevoked: true,
+		},
+		{
+			name:          "With personal access token",
+			cookie:        &http.Cookie{Name: "refresh-token", Value: "token"},
+			authorization: "Bearer pat_token",
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().SignOut("token").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:                "Without cookie",
+			mockBehavior:        func(s *mock_services.MockAuthService) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedErrResponse: true,
+		},
+		{
+			name:   "Service failed",
+			cookie: &http.Cookie{Name: "refresh-token", Value: "token"},
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().SignOut("token").Return(errors.New("some error"))
+			},
+			expectedStatus:      http.StatusInternalServerError,
+			expectedErrResponse: true,
+		},
+	}
+
+	cfg := &config.Config{
+		Auth:   config.Auth{JWT: time.Hour},
+		Cookie: config.Cookie{Name: "refresh-token", Path: "/"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s)
+
+			serv := services.AbstractService{AuthService: s}
+			h := NewHandler(&serv, cfg, new(memory.Cache))
+
+			r := gin.New()
+			r.POST("/sign-out", h.signOut)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/sign-out", nil)
+			if c.cookie != nil {
+				req.AddCookie(c.cookie)
+			}
+			if c.authorization != "" {
+				req.Header.Set("Authorization", c.authorization)
+			}
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			assert.Equal(t, h.tokenRevoked(claims), c.expectedRevoked)
+			assert.Equal(t, h.tokenRevoked(sibling), c.expectedRevoked)
+			if c.expectedErrResponse {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, responseBody.Status, c.expectedStatus)
+			} else {
+				cookies := rec.Result().Cookies()
+				assert.Len(t, cookies, 2)
+				assert.Equal(t, cookies[0].Name, "refresh-token")
+				assert.Empty(t, cookies[0].Value)
+				assert.Equal(t, cookies[0].MaxAge, -1)
+				assert.Equal(t, cookies[1].Name, csrfCookie)
+				assert.Equal(t, cookies[1].MaxAge, -1)
+				assert.Equal(t, rec.Body.String(), `{"message":"ok"}`)
+			}
+		})
+	}
+}
+
+func TestHandler_signOutAll(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService, userId int64)
+
+	cases := []struct {
+		name           string
+		userId         int64
+		mockBehavior   mockBehavior
+		expectedStatus int
+	}{
+		{
+			name:   "OK",
+			userId: 1,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().SignOutAll(userId).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unauthorized",
+			mockBehavior:   func(s *mock_services.MockAuthService, userId int64) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "Service failed",
+			userId: 1,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().SignOutAll(userId).Return(errors.New("some error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	cfg := &config.Config{
+		Auth:   config.Auth{JWT: time.Hour},
+		Cookie: config.Cookie{Name: "refresh-token", Path: "/"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s, c.userId)
+
+			serv := services.AbstractService{AuthService: s}
+			h := NewHandler(&serv, cfg, new(memory.Cache))
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			r.POST("/sign-out-all", h.signOutAll)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/sign-out-all", nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			assert.Equal(t, userTokensRevoked(h, 1), c.expectedStatus == http.StatusOK)
+			if c.expectedStatus == http.StatusOK {
+				cookies := rec.Result().Cookies()
+				assert.Len(t, cookies, 2)
+				assert.Equal(t, cookies[0].MaxAge, -1)
+			}
+		})
+	}
+}
+
 func TestHandler_middlewareAuth(t *testing.T) {
 	type mockBehavior func(s *mock_services.MockAuthService, token string)
 
+	claims := dto.TokenClaimsDTO{
+		UserId:    1,
+		Role:      dto.RoleUser,
+		Id:        "jti",
+		SessionId: 3,
+		IssuedAt:  time.Now().Add(-time.Minute),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
 	cases := []struct {
 		name                string
 		header              string
 		token               string
 		mockBehavior        mockBehavior
+		revoke              func(h *Handler)
 		expectedStatus      int
 		expectedErrResponse bool
+		expectedRole        string
 	}{
 		{
 			name:   "OK",
 			header: "Authorization",
 			token:  "Bearer token",
 			mockBehavior: func(s *mock_services.MockAuthService, token string) {
-				s.EXPECT().ParseToken(token).Return(int64(1), nil)
+				s.EXPECT().ParseToken(token).Return(claims, nil)
 			},
 			expectedStatus: http.StatusOK,
+			expectedRole:   dto.RoleUser,
+		},
+		{
+			name:   "Revoked token",
+			header: "Authorization",
+			token:  "Bearer token",
+			mockBehavior: func(s *mock_services.MockAuthService, token string) {
+				s.EXPECT().ParseToken(token).Return(claims, nil)
+			},
+			revoke: func(h *Handler) {
+				h.revokeToken(claims)
+			},
+			expectedStatus:      http.StatusUnauthorized,
+			expectedErrResponse: true,
+		},
+		{
+			name:   "Revoked session",
+			header: "Authorization",
+			token:  "Bearer token",
+			mockBehavior: func(s *mock_services.MockAuthService, token string) {
+				s.EXPECT().ParseToken(token).Return(claims, nil)
+			},
+			revoke: func(h *Handler) {
+				h.revokeSession(3)
+			},
+			expectedStatus:      http.StatusUnauthorized,
+			expectedErrResponse: true,
+		},
+		{
+			name:   "Revoked tokens of the user",
+			header: "Authorization",
+			token:  "Bearer token",
+			mockBehavior: func(s *mock_services.MockAuthService, token string) {
+				s.EXPECT().ParseToken(token).Return(claims, nil)
+			},
+			revoke: func(h *Handler) {
+				h.revokeUserTokens(1)
+			},
+			expectedStatus:      http.StatusUnauthorized,
+			expectedErrResponse: true,
+		},
+		{
+			name:   "Issued after the tokens of the user were revoked",
+			header: "Authorization",
+			token:  "Bearer token",
+			mockBehavior: func(s *mock_services.MockAuthService, token string) {
+				s.EXPECT().ParseToken(token).Return(claims, nil)
+			},
+			revoke: func(h *Handler) {
+				h.setRevoked(fmt.Sprintf(tokensInvalidBeforeKey, 1), time.Now().Add(-time.Hour).UnixMilli(), time.Hour)
+				h.revokeUserTokens(2)
+				h.revokeSession(4)
+			},
+			expectedStatus: http.StatusOK,
+			expectedRole:   dto.RoleUser,
 		},
 		{
 			name:                "Emty header",
@@ -421,7 +771,27 @@ func TestHandler_middlewareAuth(t *testing.T) {
 			header: "Authorization",
 			token:  "Bearer token",
 			mockBehavior: func(s *mock_services.MockAuthService, token string) {
-				s.EXPECT().ParseToken(token).Return(int64(0), errors.New("some error"))
+				s.EXPECT().ParseToken(token).Return(dto.TokenClaimsDTO{}, errors.New("some error"))
+			},
+			expectedStatus:      http.StatusUnauthorized,
+			expectedErrResponse: true,
+		},
+		{
+			name:   "Personal access token",
+			header: "Authorization",
+			token:  "Bearer pat_token",
+			mockBehavior: func(s *mock_services.MockAuthService, token string) {
+				s.EXPECT().ParseAccessToken("pat_"+token).Return(int64(1), []string{dto.ScopeProjectsRead}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Invalid personal access token",
+			header: "Authorization",
+			token:  "Bearer pat_token",
+			mockBehavior: func(s *mock_services.MockAuthService, token string) {
+				s.EXPECT().ParseAccessToken("pat_"+token).
+					Return(int64(0), nil, apperr.Unauthorized("invalid_access_token", "invalid or expired access token"))
 			},
 			expectedStatus:      http.StatusUnauthorized,
 			expectedErrResponse: true,
@@ -437,7 +807,10 @@ func TestHandler_middlewareAuth(t *testing.T) {
 			c.mockBehavior(s, "token")
 
 			serv := services.AbstractService{AuthService: s}
-			h := Handler{service: &serv}
+			h := Handler{service: &serv, cache: new(memory.Cache), tokenTTL: time.Hour}
+			if c.revoke != nil {
+				c.revoke(&h)
+			}
 
 			r := gin.New()
 			r.GET("/auth", h.middlewareAuth)
@@ -452,19 +825,223 @@ func TestHandler_middlewareAuth(t *testing.T) {
 
 			assert.Equal(t, rec.Code, c.expectedStatus)
 			if c.expectedErrResponse {
-				var responseBody map[string]string
+				var responseBody errResponse
 				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
 				assert.NoError(t, err)
 
-				value, ok := responseBody["message"]
-
-				assert.True(t, ok)
-				assert.NotEmpty(t, value)
+				assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+				assert.Equal(t, responseBody.Status, c.expectedStatus)
+				assert.NotEmpty(t, responseBody.Code)
 			} else {
 				id, _ := ctx.Get("user_id")
 				assert.Equal(t, id, int64(1))
+				assert.Equal(t, ctx.GetString("role"), c.expectedRole)
+			}
+		})
+	}
+
+}
+
+func TestHandler_jwks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := mock_services.NewMockAuthService(ctrl)
+	s.EXPECT().JWKS().Return(dto.JWKSetDTO{Keys: []dto.JWKDTO{{
+		Kty: "OKP",
+		Use: "sig",
+		Alg: "EdDSA",
+		Kid: "current",
+		Crv: "Ed25519",
+		X:   "x",
+	}}})
+
+	serv := services.AbstractService{AuthService: s}
+	h := Handler{service: &serv}
+
+	r := gin.New()
+	r.GET("/.well-known/jwks.json", h.jwks)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.Equal(t, rec.Body.String(), `{"keys":[{"kty":"OKP","use":"sig","alg":"EdDSA","kid":"current","crv":"Ed25519","x":"x"}]}`)
+}
+
+func TestHandler_refreshCookieName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := mock_services.NewMockAuthService(ctrl)
+	s.EXPECT().UpdateTokens("token", testClient).Return("access", "refresh", nil)
+
+	serv := services.AbstractService{AuthService: s}
+	h := NewHandler(&serv, &config.Config{Cookie: config.Cookie{Name: "rt", Path: "/auth", SameSite: "strict"}},
+		new(memory.Cache))
+
+	r := gin.New()
+	r.GET("/refresh", h.refresh)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: "rt", Value: "token"})
+
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, rec.Code, http.StatusOK)
+
+	cookies := rec.Result().Cookies()
+	assert.Len(t, cookies, 2)
+	assert.Equal(t, cookies[0].Name, "rt")
+	assert.Equal(t, cookies[0].Value, "refresh")
+	assert.Equal(t, cookies[0].SameSite, http.SameSiteStrictMode)
+	assert.Equal(t, cookies[1].Path, "/")
+}
+
+func TestHandler_refreshBodyTransport(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService)
+
+	cases := []struct {
+		name                 string
+		header               string
+		body                 string
+		mockBehavior         mockBehavior
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name:   "Header",
+			header: "token",
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().UpdateTokens("token", testClient).Return("access", "refresh", nil)
+			},
+			expectedStatus:       http.StatusOK,
+			expectedResponseBody: `{"Bearer":"access","refresh_token":"refresh"}`,
+		},
+		{
+			name: "Body",
+			body: `{"refresh_token":"token"}`,
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().UpdateTokens("token", testClient).Return("access", "refresh", nil)
+			},
+			expectedStatus:       http.StatusOK,
+			expectedResponseBody: `{"Bearer":"access","refresh_token":"refresh"}`,
+		},
+		{
+			name:           "Without token",
+			body:           `{}`,
+			mockBehavior:   func(s *mock_services.MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	cfg := &config.Config{
+		Cookie:  config.Cookie{Name: "refresh-token"},
+		Refresh: config.Refresh{Transport: "body"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s)
+
+			serv := services.AbstractService{AuthService: s}
+			h := NewHandler(&serv, cfg, new(memory.Cache))
+
+			r := gin.New()
+			r.POST("/refresh", h.requireCSRF, h.refresh)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/refresh", bytes.NewBufferString(c.body))
+			if c.header != "" {
+				req.Header.Set(refreshTokenHeader, c.header)
+			}
+			// the cookie is ignored in body mode
+			req.AddCookie(&http.Cookie{Name: "refresh-token", Value: "cookie"})
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedResponseBody != "" {
+				assert.Equal(t, rec.Body.String(), c.expectedResponseBody)
+				assert.Empty(t, rec.Result().Cookies())
 			}
 		})
 	}
+}
 
+func TestHandler_requireCSRF(t *testing.T) {
+	cases := []struct {
+		name           string
+		transport      string
+		cookie         string
+		header         string
+		expectedStatus int
+	}{
+		{
+			name:           "Matching token",
+			cookie:         "csrf",
+			header:         "csrf",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Without header",
+			cookie:         "csrf",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Without cookie",
+			header:         "csrf",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Different token",
+			cookie:         "csrf",
+			header:         "other",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Body transport",
+			transport:      "body",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := NewHandler(&services.AbstractService{}, &config.Config{Refresh: config.Refresh{Transport: c.transport}},
+				new(memory.Cache))
+
+			r := gin.New()
+			r.POST("/refresh", h.requireCSRF, func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/refresh", nil)
+			if c.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: csrfCookie, Value: c.cookie})
+			}
+			if c.header != "" {
+				req.Header.Set(csrfHeader, c.header)
+			}
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedStatus == http.StatusForbidden {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, responseBody.Code, "invalid_csrf_token")
+			}
+		})
+	}
 }
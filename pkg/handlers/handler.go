@@ -1,8 +1,15 @@
 package handlers
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
 	_ "github.com/DmytroBeliasnyk/crud_app_rest_api/docs"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
@@ -11,6 +18,8 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+const requestIdHeader = "X-Request-Id"
+
 //go:generate mockgen -source=handler.go -destination=mocks/mock.go
 type Cache interface {
 	Set(key string, value interface{}, ttl time.Duration) error
@@ -22,6 +31,10 @@ type Handler struct {
 	service *services.AbstractService
 	cfg     cookieConfig
 	cache   Cache
+	// tokenTTL is the lifetime of access tokens, revocations are kept as long
+	tokenTTL time.Duration
+	// trustedProxies are the only peers the client IP is read from X-Forwarded-For of
+	trustedProxies []string
 }
 
 type cookieConfig struct {
@@ -31,49 +44,202 @@ type cookieConfig struct {
 	domain   string
 	secure   bool
 	httpOnly bool
+	sameSite http.SameSite
+	// bodyTransport sends and accepts refresh tokens in JSON bodies instead of cookies
+	bodyTransport bool
+}
+
+// sameSiteModes maps the SameSite values of the config to the attributes of cookies.
+var sameSiteModes = map[string]http.SameSite{
+	"lax":    http.SameSiteLaxMode,
+	"strict": http.SameSiteStrictMode,
+	"none":   http.SameSiteNoneMode,
 }
 
 func NewHandler(service *services.AbstractService, config *config.Config, cache Cache) *Handler {
 	cooks := config.Cookie
+
+	sameSite, ok := sameSiteModes[strings.ToLower(cooks.SameSite)]
+	if !ok {
+		sameSite = http.SameSiteLaxMode
+	}
+
 	return &Handler{
-		service: service,
-		cache:   cache,
+		service:        service,
+		cache:          cache,
+		tokenTTL:       config.Auth.JWT,
+		trustedProxies: config.TrustedProxies,
 		cfg: cookieConfig{
-			name:     cooks.Name,
-			age:      cooks.Age,
-			path:     cooks.Path,
-			domain:   cooks.Domain,
-			secure:   cooks.Secure,
-			httpOnly: cooks.HttpOnly,
+			name:          cooks.Name,
+			age:           cooks.Age,
+			path:          cooks.Path,
+			domain:        cooks.Domain,
+			secure:        cooks.Secure,
+			httpOnly:      cooks.HttpOnly,
+			sameSite:      sameSite,
+			bodyTransport: config.Refresh.Transport == refreshTransportBody,
 		},
 	}
 }
 
-func (h *Handler) InitRoutes() *gin.Engine {
+func (h *Handler) InitRoutes() (*gin.Engine, error) {
 	router := gin.New()
+	if err := router.SetTrustedProxies(h.trustedProxies); err != nil {
+		return nil, err
+	}
+
+	router.Use(h.requestId)
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/.well-known/jwks.json", h.jwks)
 
 	auth := router.Group("/auth")
 	{
 		auth.POST("/sign-up", h.signUp)
 		auth.POST("/sign-in", h.signIn)
-		auth.GET("/refresh", h.refresh)
+		auth.POST("/sign-in/2fa", h.signInTwoFactor)
+		auth.GET("/oidc/:provider/start", h.oidcStart)
+		auth.GET("/oidc/:provider/callback", h.oidcCallback)
+		auth.GET("/refresh", h.requireCSRF, h.refresh)
+		auth.POST("/refresh", h.requireCSRF, h.refresh)
+		auth.POST("/sign-out", h.requireCSRF, h.signOut)
+		auth.POST("/verify-email", h.verifyEmail)
+		auth.POST("/resend-verification", h.resendVerification)
+		auth.POST("/forgot-password", h.forgotPassword)
+		auth.POST("/reset-password", h.resetPassword)
+
+		sessions := auth.Group("", h.middlewareAuth, h.requireSession)
+		{
+			sessions.POST("/sign-out-all", h.signOutAll)
+			sessions.GET("/sessions", h.getSessions)
+			sessions.DELETE("/sessions/:id", h.deleteSession)
+		}
 	}
 
 	api := router.Group("/api")
 	{
 		api.Use(h.middlewareAuth)
 
-		projects := api.Group("/projects")
+		// scopes of personal access tokens, access tokens of a sign in pass both
+		read, write := h.requireScope(dto.ScopeProjectsRead), h.requireScope(dto.ScopeProjectsWrite)
+
+		me := api.Group("/me", h.requireSession)
+		{
+			me.GET("", h.getMe)
+			me.PATCH("", h.updateMe)
+			me.DELETE("", h.deleteMe)
+			me.PUT("/password", h.changePassword)
+			me.POST("/2fa", h.enrollTwoFactor)
+			me.POST("/2fa/confirm", h.confirmTwoFactor)
+			me.DELETE("/2fa", h.disableTwoFactor)
+			me.GET("/tokens", h.getAccessTokens)
+			me.POST("/tokens", h.createAccessToken)
+			me.DELETE("/tokens/:id", h.deleteAccessToken)
+		}
+
+		admin := api.Group("/admin", h.requireSession, h.requireRole(dto.RoleAdmin))
 		{
-			projects.POST("/", h.create)
-			projects.GET("/", h.getAll)
-			projects.GET("", h.getById)
-			projects.POST("", h.updateById)
-			projects.DELETE("", h.deleteById)
+			admin.GET("/users", h.adminListUsers)
+			admin.GET("/users/:id", h.adminGetUser)
+			admin.POST("/users/:id/disable", h.adminDisableUser)
+			admin.POST("/users/:id/enable", h.adminEnableUser)
+			admin.POST("/users/:id/sign-out", h.adminSignOutUser)
+			admin.GET("/users/:id/projects", h.adminListProjects)
+			admin.GET("/audit-events", h.adminListAuditEvents)
+		}
+
+		v1 := api.Group("/v1")
+		{
+			projects := v1.Group("/projects")
+			{
+				projects.POST("", write, h.create)
+				projects.GET("", read, h.list)
+				projects.GET("/:id", read, h.getById)
+				projects.PUT("/:id", write, h.replaceById)
+				projects.PATCH("/:id", write, h.updateById)
+				projects.DELETE("/:id", write, h.deleteById)
+				projects.POST("/:id/transfer", write, h.transferProject)
+				projects.GET("/:id/members", read, h.getMembers)
+				projects.POST("/:id/members", write, h.addMember)
+				projects.PATCH("/:id/members/:user_id", write, h.updateMember)
+				projects.DELETE("/:id/members/:user_id", write, h.removeMember)
+				projects.GET("/:id/tasks", read, h.listTasks)
+				projects.POST("/:id/tasks", write, h.createTask)
+				projects.GET("/:id/tasks/:task_id", read, h.getTask)
+				projects.PATCH("/:id/tasks/:task_id", write, h.updateTask)
+				projects.DELETE("/:id/tasks/:task_id", write, h.deleteTask)
+			}
+		}
+
+		// query parameter routes are kept until all clients move to /api/v1/projects
+		projects := api.Group("/projects", h.deprecated("/api/v1/projects"))
+		{
+			projects.POST("/", write, h.create)
+			projects.GET("/", read, h.getAll)
+			projects.GET("", read, idFromQuery, h.getById)
+			projects.POST("", write, idFromQuery, h.updateById)
+			projects.DELETE("", write, idFromQuery, h.deleteById)
+		}
+	}
+
+	return router, nil
+}
+
+// idFromQuery serves the id query parameter of the deprecated routes as the id
+// path parameter, so that their handlers read it like the ones of /api/v1/projects.
+func idFromQuery(ctx *gin.Context) {
+	ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: ctx.Query("id")})
+}
+
+// pathIdParam reads a positive id from the path parameter name.
+func pathIdParam(ctx *gin.Context, name string) (int64, error) {
+	param := ctx.Param(name)
+
+	id, err := strconv.ParseInt(param, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s param: %q", name, param)
+	}
+
+	return id, nil
+}
+
+// requestId propagates the X-Request-Id of the client or generates a new one,
+// so that a problem reported by a client can be found in the logs.
+func (h *Handler) requestId(ctx *gin.Context) {
+	id := ctx.GetHeader(requestIdHeader)
+	if !validRequestId(id) {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			newErrResponse(ctx, http.StatusInternalServerError, err.Error())
+			return
 		}
+
+		id = hex.EncodeToString(buf)
+	}
+
+	ctx.Set("request_id", id)
+	ctx.Header(requestIdHeader, id)
+}
+
+func validRequestId(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
 	}
 
-	return router
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// deprecated marks responses of legacy routes with a Deprecation header
+// and a link to the route that replaces them.
+func (h *Handler) deprecated(successor string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Deprecation", "true")
+		ctx.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+	}
 }
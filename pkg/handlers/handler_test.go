@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_deprecated(t *testing.T) {
+	h := Handler{}
+
+	r := gin.New()
+	r.GET("/legacy", h.deprecated("/api/v1/projects"), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/legacy", nil)
+
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.Equal(t, rec.Header().Get("Deprecation"), "true")
+	assert.Equal(t, rec.Header().Get("Link"), `</api/v1/projects>; rel="successor-version"`)
+}
+
+func TestHandler_requestId(t *testing.T) {
+	cases := []struct {
+		name       string
+		header     string
+		expectedId string
+	}{
+		{
+			name:       "Propagated",
+			header:     "abc-123",
+			expectedId: "abc-123",
+		},
+		{
+			name: "Generated",
+		},
+		{
+			name:   "Invalid header",
+			header: "abc 123\r\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := Handler{}
+
+			var stored string
+			r := gin.New()
+			r.GET("/", h.requestId, func(ctx *gin.Context) {
+				stored = ctx.GetString("request_id")
+				ctx.Status(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/", nil)
+			if c.header != "" {
+				req.Header.Set(requestIdHeader, c.header)
+			}
+
+			r.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(requestIdHeader)
+			assert.Equal(t, got, stored)
+			if c.expectedId != "" {
+				assert.Equal(t, got, c.expectedId)
+			} else {
+				assert.Len(t, got, 32)
+			}
+		})
+	}
+}
+
+func TestHandler_InitRoutesTrustedProxies(t *testing.T) {
+	cases := []struct {
+		name           string
+		trustedProxies []string
+		expectedIP     string
+	}{
+		{
+			name:       "Forged header is ignored",
+			expectedIP: "192.0.2.1",
+		},
+		{
+			name:           "Header of a trusted proxy",
+			trustedProxies: []string{"192.0.2.0/24"},
+			expectedIP:     "203.0.113.7",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := Handler{trustedProxies: c.trustedProxies}
+
+			r, err := h.InitRoutes()
+			assert.NoError(t, err)
+
+			var ip string
+			r.GET("/ip", func(ctx *gin.Context) {
+				ip = clientInfo(ctx).IP
+				ctx.Status(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/ip", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, http.StatusOK)
+			assert.Equal(t, ip, c.expectedIP)
+		})
+	}
+}
+
+func TestHandler_InitRoutesInvalidProxy(t *testing.T) {
+	h := Handler{trustedProxies: []string{"proxy"}}
+
+	_, err := h.InitRoutes()
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcFlowCookie = "oidc-flow"
+	oidcCookiePath = "/auth/oidc"
+)
+
+// oidcStart godoc
+//
+//	@Summary		oidcStart
+//	@Description	start a sign in with an OpenID Connect provider of the config. Redirects to the provider,
+//	@Description	which redirects back to /auth/oidc/{provider}/callback.
+//	@Tags			auth
+//	@Param			provider	path		string	true	"provider name"
+//	@Header			302			{string}	Location	"sign in page of the provider"
+//	@Success		302
+//	@Failure		404		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/auth/oidc/{provider}/start [get]
+func (h *Handler) oidcStart(ctx *gin.Context) {
+	start, err := h.service.AuthService.StartOIDC(ctx.Param("provider"))
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	// lax, the callback is a navigation from the site of the provider
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcFlowCookie, start.Flow, int(time.Until(start.ExpiresAt).Seconds()), oidcCookiePath,
+		h.cfg.domain, h.cfg.secure, true)
+
+	ctx.Redirect(http.StatusFound, start.AuthURL)
+}
+
+// oidcCallback godoc
+//
+//	@Summary		oidcCallback
+//	@Description	finish a sign in with an OpenID Connect provider. The account of the provider is linked
+//	@Description	to the user with the same verified email or a new user is created.
+//	@Description	Users with two-factor authentication get a challenge token for /auth/sign-in/2fa instead of tokens.
+//	@Tags			auth
+//	@Produce		json
+//	@Param			provider			path		string	true	"provider name"
+//	@Param			code				query		string	false	"authorization code"
+//	@Param			state				query		string	true	"state of the sign in"
+//	@Param			error				query		string	false	"error of the provider"
+//	@Param			error_description	query		string	false	"description of the error of the provider"
+//	@Header			200					{string}	Set-Cookie	"set new refresh token"
+//	@Success		200					{string}	string		jwt
+//	@Success		202					{object}	dto.TwoFactorChallengeDTO
+//	@Failure		400					{object}	errResponse
+//	@Failure		401					{object}	errResponse
+//	@Failure		403					{object}	errResponse
+//	@Failure		404					{object}	errResponse
+//	@Failure		409					{object}	errResponse
+//	@Failure		default				{object}	errResponse
+//	@Router			/auth/oidc/{provider}/callback [get]
+func (h *Handler) oidcCallback(ctx *gin.Context) {
+	var cb dto.OIDCCallbackDTO
+	if err := ctx.ShouldBindQuery(&cb); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	// the flow is used once, a missing one is rejected by the service
+	flow, _ := ctx.Cookie(oidcFlowCookie)
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcFlowCookie, "", -1, oidcCookiePath, h.cfg.domain, h.cfg.secure, true)
+
+	userId, challenge, err := h.service.AuthService.SignInOIDC(ctx.Param("provider"), cb, flow, clientInfo(ctx))
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	if challenge != "" {
+		ctx.JSON(http.StatusAccepted, dto.TwoFactorChallengeDTO{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		})
+		return
+	}
+
+	h.issueTokens(ctx, userId)
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	mock_services "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/mocks"
+	"github.com/DmytroBeliasnyk/in_memory_cache/memory"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_oidcStart(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService)
+
+	cases := []struct {
+		name             string
+		provider         string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{
+			name:     "OK",
+			provider: "corp",
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().StartOIDC("corp").Return(dto.OIDCStartDTO{
+					AuthURL:   "https://login.example.com/authorize?state=state",
+					Flow:      "flow",
+					ExpiresAt: time.Now().Add(10 * time.Minute),
+				}, nil)
+			},
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "https://login.example.com/authorize?state=state",
+		},
+		{
+			name:     "Unknown provider",
+			provider: "other",
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().StartOIDC("other").
+					Return(dto.OIDCStartDTO{}, apperr.NotFound("unknown_oidc_provider", "OIDC provider not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s)
+
+			serv := services.AbstractService{AuthService: s}
+			h := NewHandler(&serv, &config.Config{}, new(memory.Cache))
+
+			r := gin.New()
+			r.GET("/oidc/:provider/start", h.oidcStart)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/oidc/"+c.provider+"/start", nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedLocation != "" {
+				assert.Equal(t, rec.Header().Get("Location"), c.expectedLocation)
+
+				cookies := rec.Result().Cookies()
+				assert.Len(t, cookies, 1)
+				assert.Equal(t, cookies[0].Name, oidcFlowCookie)
+				assert.Equal(t, cookies[0].Value, "flow")
+				assert.Equal(t, cookies[0].Path, oidcCookiePath)
+				assert.True(t, cookies[0].HttpOnly)
+				assert.Equal(t, cookies[0].SameSite, http.SameSiteLaxMode)
+			}
+		})
+	}
+}
+
+func TestHandler_oidcCallback(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService)
+
+	cb := dto.OIDCCallbackDTO{Code: "code", State: "state"}
+
+	cases := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatus       int
+		expectedCode         string
+		expectedResponseBody string
+	}{
+		{
+			name:  "OK",
+			query: "?code=code&state=state",
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().SignInOIDC("corp", cb, "flow", testClient).Return(int64(1), "", nil)
+				s.EXPECT().GenerateTokens(int64(1), testClient).Return("access", "refresh", nil)
+			},
+			expectedStatus:       http.StatusOK,
+			expectedResponseBody: `{"Bearer":"access"}`,
+		},
+		{
+			name:  "Two-factor challenge",
+			query: "?code=code&state=state",
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().SignInOIDC("corp", cb, "flow", testClient).Return(int64(0), "challenge", nil)
+			},
+			expectedStatus:       http.StatusAccepted,
+			expectedResponseBody: `{"two_factor_required":true,"challenge_token":"challenge"}`,
+		},
+		{
+			name:           "Without state",
+			query:          "?code=code",
+			mockBehavior:   func(s *mock_services.MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "validation_failed",
+		},
+		{
+			name:  "Invalid state",
+			query: "?code=code&state=state",
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().SignInOIDC("corp", cb, "flow", testClient).
+					Return(int64(0), "", apperr.Unauthorized("invalid_oidc_state", "invalid or expired OIDC sign in"))
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "invalid_oidc_state",
+		},
+	}
+
+	cfg := &config.Config{
+		Cookie: config.Cookie{Name: "refresh-token", Age: 1000, Path: "/", HttpOnly: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s)
+
+			serv := services.AbstractService{AuthService: s}
+			h := NewHandler(&serv, cfg, new(memory.Cache))
+
+			r := gin.New()
+			r.GET("/oidc/:provider/callback", h.oidcCallback)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/oidc/corp/callback"+c.query, nil)
+			req.AddCookie(&http.Cookie{Name: oidcFlowCookie, Value: "flow"})
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedCode != "" {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, responseBody.Code, c.expectedCode)
+			}
+			if c.expectedResponseBody != "" {
+				assert.Equal(t, rec.Body.String(), c.expectedResponseBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// forgotPassword godoc
+//
+//	@Summary		forgotPassword
+//	@Description	send a password reset token, the response is the same whether the email is registered or not
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		dto.ForgotPasswordDTO	true	"email"
+//	@Success		202		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/auth/forgot-password [post]
+func (h *Handler) forgotPassword(ctx *gin.Context) {
+	var input dto.ForgotPasswordDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	if err := h.service.AuthService.ForgotPassword(input.Email); err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, statusResponse{"ok"})
+}
+
+// resetPassword godoc
+//
+//	@Summary		resetPassword
+//	@Description	set a new password with the token sent by forgotPassword, revoking all sessions of the user
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		dto.ResetPasswordDTO	true	"reset token and new password"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/auth/reset-password [post]
+func (h *Handler) resetPassword(ctx *gin.Context) {
+	var input dto.ResetPasswordDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	userId, err := h.service.AuthService.ResetPassword(input, clientInfo(ctx))
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+	h.revokeUserTokens(userId)
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// changePassword godoc
+//
+//	@Summary		changePassword
+//	@Security		ApiKeyAuth
+//	@Description	change the password of the user, revoking all sessions of the user.
+//	@Description	Accounts without a password, e.g. signed up with OpenID Connect, set the first one without current_password.
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		dto.ChangePasswordDTO	true	"current and new password"
+//	@Header			200		{string}	Set-Cookie				"expired refresh token"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		401		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/me/password [put]
+func (h *Handler) changePassword(ctx *gin.Context) {
+	userId := ctx.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(ctx, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	var input dto.ChangePasswordDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	if err := h.service.AuthService.ChangePassword(userId, input, clientInfo(ctx)); err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+	h.revokeUserTokens(userId)
+
+	h.clearRefreshCookie(ctx)
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
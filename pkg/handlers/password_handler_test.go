@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	mock_services "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/mocks"
+	"github.com/DmytroBeliasnyk/in_memory_cache/memory"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_forgotPassword(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService)
+
+	cases := []struct {
+		name           string
+		body           string
+		mockBehavior   mockBehavior
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "OK",
+			body: `{"email":"aaa@bbb.ccc"}`,
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().ForgotPassword("aaa@bbb.ccc").Return(nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "Invalid email",
+			body:           `{"email":"invalid_email"}`,
+			mockBehavior:   func(s *mock_services.MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "validation_failed",
+		},
+		{
+			name: "Service failed",
+			body: `{"email":"aaa@bbb.ccc"}`,
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().ForgotPassword("aaa@bbb.ccc").Return(errors.New("some error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "internal_error",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s)
+
+			serv := services.AbstractService{AuthService: s}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.POST("/forgot-password", h.forgotPassword)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/forgot-password", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedCode != "" {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, responseBody.Code, c.expectedCode)
+			} else {
+				assert.Equal(t, rec.Body.String(), `{"message":"ok"}`)
+			}
+		})
+	}
+}
+
+func TestHandler_resetPassword(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService)
+
+	cases := []struct {
+		name           string
+		body           string
+		mockBehavior   mockBehavior
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "OK",
+			body: `{"token":"token","password":"new_password"}`,
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().ResetPassword(dto.ResetPasswordDTO{Token: "token", Password: "new_password"}, testClient).
+					Return(int64(1), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Short password",
+			body:           `{"token":"token","password":"short"}`,
+			mockBehavior:   func(s *mock_services.MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "validation_failed",
+		},
+		{
+			name: "Expired token",
+			body: `{"token":"token","password":"new_password"}`,
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().ResetPassword(dto.ResetPasswordDTO{Token: "token", Password: "new_password"}, testClient).
+					Return(int64(0), apperr.Validation("expired_reset_token", "password reset token is expired"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "expired_reset_token",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s)
+
+			serv := services.AbstractService{AuthService: s}
+			h := Handler{service: &serv, cache: new(memory.Cache), tokenTTL: time.Hour}
+
+			r := gin.New()
+			r.POST("/reset-password", h.resetPassword)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/reset-password", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			assert.Equal(t, userTokensRevoked(&h, 1), c.expectedCode == "")
+			if c.expectedCode != "" {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, responseBody.Code, c.expectedCode)
+			} else {
+				assert.Equal(t, rec.Body.String(), `{"message":"ok"}`)
+			}
+		})
+	}
+}
+
+func TestHandler_changePassword(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService, userId int64)
+
+	cases := []struct {
+		name           string
+		userId         int64
+		body           string
+		mockBehavior   mockBehavior
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:   "OK",
+			userId: 1,
+			body:   `{"current_password":"password","new_password":"new_password"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().ChangePassword(userId,
+					dto.ChangePasswordDTO{CurrentPassword: "password", NewPassword: "new_password"}, testClient).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unauthorized",
+			body:           `{"current_password":"password","new_password":"new_password"}`,
+			mockBehavior:   func(s *mock_services.MockAuthService, userId int64) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "unauthorized",
+		},
+		{
+			name:   "Without current password",
+			userId: 1,
+			body:   `{"new_password":"new_password"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().ChangePassword(userId, dto.ChangePasswordDTO{NewPassword: "new_password"}, testClient).
+					Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Without new password",
+			userId:         1,
+			body:           `{"current_password":"password"}`,
+			mockBehavior:   func(s *mock_services.MockAuthService, userId int64) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "validation_failed",
+		},
+		{
+			name:   "Invalid current password",
+			userId: 1,
+			body:   `{"current_password":"invalid","new_password":"new_password"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().ChangePassword(userId,
+					dto.ChangePasswordDTO{CurrentPassword: "invalid", NewPassword: "new_password"}, testClient).
+					Return(apperr.Validation("invalid_current_password", "current password is invalid"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_current_password",
+		},
+	}
+
+	cfg := &config.Config{
+		Auth:   config.Auth{JWT: time.Hour},
+		Cookie: config.Cookie{Name: "refresh-token", Path: "/"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s, c.userId)
+
+			serv := services.AbstractService{AuthService: s}
+			h := NewHandler(&serv, cfg, new(memory.Cache))
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			r.PUT("/me/password", h.changePassword)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/me/password", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			assert.Equal(t, userTokensRevoked(h, 1), c.expectedCode == "")
+			if c.expectedCode != "" {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, responseBody.Code, c.expectedCode)
+			} else {
+				cookies := rec.Result().Cookies()
+				assert.Len(t, cookies, 2)
+				assert.Equal(t, cookies[0].MaxAge, -1)
+			}
+		})
+	}
+}
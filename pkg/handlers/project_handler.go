@@ -3,11 +3,11 @@ package handlers
 import (
 	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
 // Create godoc
@@ -23,11 +23,11 @@ import (
 //	@Failure		400		{object}	errResponse
 //	@Failure		500		{object}	errResponse
 //	@Failure		default	{object}	errResponse
-//	@Router			/api/projects/ [post]
+//	@Router			/api/v1/projects [post]
 func (h *Handler) create(c *gin.Context) {
 	var input dto.ProjectDTO
-	if err := c.BindJSON(&input); err != nil {
-		newErrResponse(c, http.StatusBadRequest, err.Error())
+	if err := c.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(c, err)
 		return
 	}
 
@@ -39,7 +39,7 @@ func (h *Handler) create(c *gin.Context) {
 
 	projectId, err := h.service.ProjectService.Create(input, userId)
 	if err != nil {
-		newErrResponse(c, http.StatusInternalServerError, err.Error())
+		newServiceErrResponse(c, err)
 		return
 	}
 
@@ -58,16 +58,17 @@ func (h *Handler) create(c *gin.Context) {
 //	@Security		ApiKeyAuth
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		query		integer	true	"project id"
-//	@Success		200		{object}	dto.ProjectDTO
+//	@Param			id		path		integer	true	"project id"
+//	@Success		200		{object}	dto.ProjectResponseDTO
 //	@Failure		400		{object}	errResponse
+//	@Failure		404		{object}	errResponse
 //	@Failure		500		{object}	errResponse
 //	@Failure		default	{object}	errResponse
-//	@Router			/api/projects [get]
+//	@Router			/api/v1/projects/{id} [get]
 func (h *Handler) getById(c *gin.Context) {
-	projectId, err := strconv.Atoi(c.Query("id"))
-	if err != nil || projectId == 0 {
-		newErrResponse(c, http.StatusBadRequest, fmt.Sprintf("%s: message: invalid id param", err))
+	projectId, err := pathIdParam(c, "id")
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -77,13 +78,13 @@ func (h *Handler) getById(c *gin.Context) {
 		return
 	}
 
-	cache := fmt.Sprintf("%d%d", projectId, userId)
+	cache := projectCacheKey(projectId, userId)
 
 	project, err := h.cache.Get(cache)
 	if err != nil {
-		project, err = h.service.ProjectService.GetById(int64(projectId), userId)
+		project, err = h.service.ProjectService.GetById(projectId, userId)
 		if err != nil {
-			newErrResponse(c, http.StatusInternalServerError, err.Error())
+			newServiceErrResponse(c, err)
 			return
 		}
 
@@ -97,17 +98,78 @@ func (h *Handler) getById(c *gin.Context) {
 	c.JSON(http.StatusOK, project)
 }
 
+// List godoc
+//
+//	@Summary		List
+//	@Description	get a page of projects
+//	@Tags			projects
+//	@Security		ApiKeyAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			limit			query		integer	false	"page size (1-100)"
+//	@Param			cursor			query		string	false	"next_cursor of the previous page"
+//	@Param			done			query		boolean	false	"filter by done flag"
+//	@Param			search			query		string	false	"search in title and description"
+//	@Param			created_from	query		string	false	"created at or after (RFC 3339)"
+//	@Param			created_to		query		string	false	"created at or before (RFC 3339)"
+//	@Param			sort			query		string	false	"sort field"	Enums(title, created_at, updated_at)
+//	@Param			order			query		string	false	"sort order"	Enums(asc, desc)
+//	@Success		200				{object}	dto.ProjectPageDTO
+//	@Failure		400				{object}	errResponse
+//	@Failure		500				{object}	errResponse
+//	@Failure		default			{object}	errResponse
+//	@Router			/api/v1/projects [get]
+func (h *Handler) list(c *gin.Context) {
+	userId := c.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(c, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	var query dto.ProjectQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		newValidationErrResponse(c, err)
+		return
+	}
+
+	query.SetDefaults()
+	if err := query.Validate(); err != nil {
+		newValidationErrResponse(c, err)
+		return
+	}
+
+	cache := h.projectsCacheKey(userId, query.CacheKey())
+
+	page, err := h.cache.Get(cache)
+	if err != nil {
+		page, err = h.service.ProjectService.List(userId, query)
+		if err != nil {
+			newServiceErrResponse(c, err)
+			return
+		}
+
+		if err = h.cache.Set(cache, page, time.Hour); err != nil {
+			h.cache.Delete(cache)
+			newErrResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
 // GetAll godoc
 //
 //	@Summary		GetAll
-//	@Description	get all projects
+//	@Description	get all projects, replaced by the paginated GET /api/v1/projects
 //	@Tags			projects
 //	@Security		ApiKeyAuth
 //	@Accept			json
 //	@Produce		json
-//	@Success		200		{array}		dto.ProjectDTO
+//	@Success		200		{array}		dto.ProjectResponseDTO
 //	@Failure		500		{object}	errResponse
 //	@Failure		default	{object}	errResponse
+//	@Deprecated
 //	@Router			/api/projects/ [get]
 func (h *Handler) getAll(c *gin.Context) {
 	userId := c.GetInt64("user_id")
@@ -116,13 +178,13 @@ func (h *Handler) getAll(c *gin.Context) {
 		return
 	}
 
-	cache := fmt.Sprintf("all%d", userId)
+	cache := h.projectsCacheKey(userId, "all")
 
 	projects, err := h.cache.Get(cache)
 	if err != nil {
 		projects, err = h.service.ProjectService.GetAll(userId)
 		if err != nil {
-			newErrResponse(c, http.StatusInternalServerError, err.Error())
+			newServiceErrResponse(c, err)
 			return
 		}
 
@@ -136,21 +198,75 @@ func (h *Handler) getAll(c *gin.Context) {
 	c.JSON(http.StatusOK, projects)
 }
 
+// ReplaceById godoc
+//
+//	@Summary		ReplaceById
+//	@Description	replace all fields of project by id
+//	@Tags			projects
+//	@Security		ApiKeyAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		integer			true	"project id"
+//	@Param			input	body		dto.ProjectDTO	true	"project info"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		500		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/v1/projects/{id} [put]
+func (h *Handler) replaceById(c *gin.Context) {
+	userId := c.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(c, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	projectId, err := pathIdParam(c, "id")
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var input dto.ProjectDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(c, err)
+		return
+	}
+
+	update := dto.UpdateProjectDTO{
+		Title:         &input.Title,
+		Description:   &input.Description,
+		Done:          &input.Done,
+		DoneFromTasks: &input.DoneFromTasks,
+	}
+	if err = h.service.ProjectService.UpdateById(projectId, update, userId); err != nil {
+		newServiceErrResponse(c, err)
+		return
+	}
+
+	h.invalidateProject(projectId, h.projectMemberIds(projectId, userId))
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
 // UpdateById godoc
 //
 //	@Summary		UpdateById
-//	@Description	update project by id
+//	@Description	update provided fields of project by id
 //	@Tags			projects
 //	@Security		ApiKeyAuth
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		query		integer			true	"project id"
-//	@Param			input	body		dto.ProjectDTO	true	"project info"
+//	@Param			id		path		integer					true	"project id"
+//	@Param			input	body		dto.UpdateProjectDTO	true	"project info"
 //	@Success		200		{object}	statusResponse
 //	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
 //	@Failure		500		{object}	errResponse
 //	@Failure		default	{object}	errResponse
-//	@Router			/api/projects [post]
+//	@Router			/api/v1/projects/{id} [patch]
 func (h *Handler) updateById(c *gin.Context) {
 	userId := c.GetInt64("user_id")
 	if userId == 0 {
@@ -158,30 +274,29 @@ func (h *Handler) updateById(c *gin.Context) {
 		return
 	}
 
-	projectId, err := strconv.Atoi(c.Query("id"))
-	if err != nil || projectId == 0 {
-		newErrResponse(c, http.StatusBadRequest, fmt.Sprintf("%s: message: invalid id param", err))
+	projectId, err := pathIdParam(c, "id")
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	var input dto.UpdateProjectDTO
-	if err := c.BindJSON(&input); err != nil {
-		newErrResponse(c, http.StatusBadRequest, err.Error())
+	if err := c.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(c, err)
 		return
 	}
 
 	if err := input.Validate(); err != nil {
-		newErrResponse(c, http.StatusBadRequest, err.Error())
+		newValidationErrResponse(c, err)
 		return
 	}
 
-	if err = h.service.ProjectService.UpdateById(int64(projectId), input, userId); err != nil {
-		newErrResponse(c, http.StatusInternalServerError, err.Error())
+	if err = h.service.ProjectService.UpdateById(projectId, input, userId); err != nil {
+		newServiceErrResponse(c, err)
 		return
 	}
 
-	h.cache.Delete(fmt.Sprintf("%d%d", projectId, userId))
-	h.cache.Delete(fmt.Sprintf("all%d", userId))
+	h.invalidateProject(projectId, h.projectMemberIds(projectId, userId))
 
 	c.JSON(http.StatusOK, statusResponse{"ok"})
 }
@@ -189,17 +304,19 @@ func (h *Handler) updateById(c *gin.Context) {
 // DeleteById godoc
 //
 //	@Summary		DeleteById
-//	@Description	delete project by id
+//	@Description	delete project by id, only its owner may delete it
 //	@Tags			projects
 //	@Security		ApiKeyAuth
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		query		integer	true	"project id"
+//	@Param			id		path		integer	true	"project id"
 //	@Success		200		{object}	statusResponse
 //	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
 //	@Failure		500		{object}	errResponse
 //	@Failure		default	{object}	errResponse
-//	@Router			/api/projects [delete]
+//	@Router			/api/v1/projects/{id} [delete]
 func (h *Handler) deleteById(c *gin.Context) {
 	userId := c.GetInt64("user_id")
 	if userId == 0 {
@@ -207,19 +324,70 @@ func (h *Handler) deleteById(c *gin.Context) {
 		return
 	}
 
-	projectId, err := strconv.Atoi(c.Query("id"))
-	if err != nil || projectId == 0 {
-		newErrResponse(c, http.StatusBadRequest, fmt.Sprintf("%s: message: invalid id param", err))
+	projectId, err := pathIdParam(c, "id")
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if err = h.service.ProjectService.DeleteById(int64(projectId), userId); err != nil {
-		newErrResponse(c, http.StatusInternalServerError, err.Error())
+	// the members are gone together with the project
+	memberIds := h.projectMemberIds(projectId, userId)
+
+	if err = h.service.ProjectService.DeleteById(projectId, userId); err != nil {
+		newServiceErrResponse(c, err)
 		return
 	}
 
-	h.cache.Delete(fmt.Sprintf("%d%d", projectId, userId))
-	h.cache.Delete(fmt.Sprintf("all%d", userId))
+	h.invalidateProject(projectId, memberIds)
 
 	c.JSON(http.StatusOK, statusResponse{"ok"})
 }
+
+// projectCacheKey returns the cache key of the project as seen by the user, the ids
+// are delimited so that different pairs of ids never share a key.
+func projectCacheKey(projectId, userId int64) string {
+	return fmt.Sprintf("project:%d:%d", projectId, userId)
+}
+
+// projectsCacheKey returns the cache key of a list of projects of the user.
+// Every list is stored under the generation kept at all<userId>, so deleting
+// that single key invalidates all cached pages of the user.
+func (h *Handler) projectsCacheKey(userId int64, query string) string {
+	key := fmt.Sprintf("all%d", userId)
+
+	generation, err := h.cache.Get(key)
+	if err != nil {
+		generation = time.Now().UnixNano()
+		if err = h.cache.Set(key, generation, time.Hour); err != nil {
+			h.cache.Delete(key)
+		}
+	}
+
+	return fmt.Sprintf("%s:%v:%s", key, generation, query)
+}
+
+// projectMemberIds returns the members of the project, whose cached projects a change
+// of the project affects. If they can not be looked up, only the user is returned.
+func (h *Handler) projectMemberIds(projectId, userId int64) []int64 {
+	ids, err := h.service.ProjectService.MemberIds(projectId)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"project_id": projectId,
+			"error":      err,
+		}).Error("failed to get project members")
+	}
+
+	if len(ids) == 0 {
+		return []int64{userId}
+	}
+
+	return ids
+}
+
+// invalidateProject deletes the cached project and lists of projects of the members.
+func (h *Handler) invalidateProject(projectId int64, memberIds []int64) {
+	for _, id := range memberIds {
+		h.cache.Delete(projectCacheKey(projectId, id))
+		h.cache.Delete(fmt.Sprintf("all%d", id))
+	}
+}
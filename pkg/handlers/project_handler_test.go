@@ -12,12 +12,15 @@ import (
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
 	mock_handlers "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/handlers/mocks"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
 	mock_services "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/mocks"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
+var errNotFound = apperr.NotFound("project_not_found", "project not found")
+
 func TestHandler_create(t *testing.T) {
 	type serviceBehavior func(s *mock_services.MockProjectService, input dto.ProjectDTO, userId int64)
 	type cacheBehavior func(s *mock_handlers.MockCache, userId int64)
@@ -114,14 +117,13 @@ func TestHandler_create(t *testing.T) {
 
 			assert.Equal(t, rec.Code, c.expectedStatus)
 			if c.expectedErrResponse {
-				var responseBody map[string]string
+				var responseBody errResponse
 				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
 				assert.NoError(t, err)
 
-				msg, ok := responseBody["message"]
-
-				assert.True(t, ok)
-				assert.NotEmpty(t, msg)
+				assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+				assert.Equal(t, responseBody.Status, c.expectedStatus)
+				assert.NotEmpty(t, responseBody.Code)
 			} else {
 				assert.Equal(t, rec.Body.String(), `{"id":1}`)
 			}
@@ -147,13 +149,13 @@ func TestHandler_getById(t *testing.T) {
 			projectId: 1,
 			userId:    2,
 			serviceBehavior: func(s *mock_services.MockProjectService, projectId, userId int64) {
-				s.EXPECT().GetById(projectId, userId).Return(dto.ProjectDTO{Title: "title"}, nil)
+				s.EXPECT().GetById(projectId, userId).Return(dto.ProjectResponseDTO{Id: 1, Title: "title"}, nil)
 			},
 			cacheBehavior: func(s *mock_handlers.MockCache, projectId, userId int64) {
-				cache := fmt.Sprintf("%d%d", projectId, userId)
+				cache := fmt.Sprintf("project:%d:%d", projectId, userId)
 
 				s.EXPECT().Get(cache).Return(gomock.Any(), errors.New("some error"))
-				s.EXPECT().Set(cache, dto.ProjectDTO{Title: "title"}, gomock.Any()).Return(nil)
+				s.EXPECT().Set(cache, dto.ProjectResponseDTO{Id: 1, Title: "title"}, gomock.Any()).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -163,8 +165,8 @@ func TestHandler_getById(t *testing.T) {
 			userId:          2,
 			serviceBehavior: func(s *mock_services.MockProjectService, projectId, userId int64) {},
 			cacheBehavior: func(s *mock_handlers.MockCache, projectId, userId int64) {
-				s.EXPECT().Get(fmt.Sprintf("%d%d", projectId, userId)).
-					Return(dto.ProjectDTO{Title: "title"}, nil)
+				s.EXPECT().Get(fmt.Sprintf("project:%d:%d", projectId, userId)).
+					Return(dto.ProjectResponseDTO{Id: 1, Title: "title"}, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -189,28 +191,42 @@ func TestHandler_getById(t *testing.T) {
 			projectId: 1,
 			userId:    2,
 			serviceBehavior: func(s *mock_services.MockProjectService, projectId, userId int64) {
-				s.EXPECT().GetById(projectId, userId).Return(dto.ProjectDTO{}, errors.New("some error"))
+				s.EXPECT().GetById(projectId, userId).Return(dto.ProjectResponseDTO{}, errors.New("some error"))
 			},
 			cacheBehavior: func(s *mock_handlers.MockCache, projectId, userId int64) {
-				s.EXPECT().Get(fmt.Sprintf("%d%d", projectId, userId)).
+				s.EXPECT().Get(fmt.Sprintf("project:%d:%d", projectId, userId)).
 					Return(gomock.Any, errors.New("some error"))
 			},
 			expectedStatus:      http.StatusInternalServerError,
 			expectedErrResponse: true,
 		},
+		{
+			name:      "Not found",
+			projectId: 1,
+			userId:    2,
+			serviceBehavior: func(s *mock_services.MockProjectService, projectId, userId int64) {
+				s.EXPECT().GetById(projectId, userId).Return(dto.ProjectResponseDTO{}, errNotFound)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache, projectId, userId int64) {
+				s.EXPECT().Get(fmt.Sprintf("project:%d:%d", projectId, userId)).
+					Return(gomock.Any, errors.New("some error"))
+			},
+			expectedStatus:      http.StatusNotFound,
+			expectedErrResponse: true,
+		},
 		{
 			name:      "Set cache failed",
 			projectId: 1,
 			userId:    2,
 			serviceBehavior: func(s *mock_services.MockProjectService, projectId, userId int64) {
 				s.EXPECT().GetById(projectId, userId).
-					Return(dto.ProjectDTO{Title: "title"}, nil)
+					Return(dto.ProjectResponseDTO{Id: 1, Title: "title"}, nil)
 			},
 			cacheBehavior: func(s *mock_handlers.MockCache, projectId, userId int64) {
-				cache := fmt.Sprintf("%d%d", projectId, userId)
+				cache := fmt.Sprintf("project:%d:%d", projectId, userId)
 
 				s.EXPECT().Get(cache).Return(gomock.Any, errors.New("some error"))
-				s.EXPECT().Set(cache, dto.ProjectDTO{Title: "title"}, gomock.Any()).Return(errors.New("some error"))
+				s.EXPECT().Set(cache, dto.ProjectResponseDTO{Id: 1, Title: "title"}, gomock.Any()).Return(errors.New("some error"))
 				s.EXPECT().Delete(cache)
 			},
 			expectedStatus:      http.StatusInternalServerError,
@@ -240,7 +256,7 @@ func TestHandler_getById(t *testing.T) {
 				ctx.Set("user_id", c.userId)
 			})
 			target := "/get-by-id"
-			r.GET(target, h.getById)
+			r.GET(target, idFromQuery, h.getById)
 
 			rec := httptest.NewRecorder()
 			req := httptest.NewRequest("GET", fmt.Sprintf("%s?id=%d", target, c.projectId), nil)
@@ -249,16 +265,15 @@ func TestHandler_getById(t *testing.T) {
 
 			assert.Equal(t, rec.Code, c.expectedStatus)
 			if c.expectedErrResponse {
-				var responseBody map[string]string
+				var responseBody errResponse
 				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
 				assert.NoError(t, err)
 
-				msg, ok := responseBody["message"]
-
-				assert.True(t, ok)
-				assert.NotEmpty(t, msg)
+				assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+				assert.Equal(t, responseBody.Status, c.expectedStatus)
+				assert.NotEmpty(t, responseBody.Code)
 			} else {
-				assert.Equal(t, rec.Body.String(), `{"title":"title","description":"","done":false}`)
+				assert.Equal(t, rec.Body.String(), `{"id":1,"user_id":0,"role":"","title":"title","description":"","done":false,"done_from_tasks":false,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","completed_at":null}`)
 			}
 		})
 	}
@@ -280,13 +295,13 @@ func TestHandler_getAll(t *testing.T) {
 			name:   "OK",
 			userId: 2,
 			serviceBehavior: func(s *mock_services.MockProjectService, userId int64) {
-				s.EXPECT().GetAll(userId).Return([]dto.ProjectDTO{{Title: "title"}}, nil)
+				s.EXPECT().GetAll(userId).Return([]dto.ProjectResponseDTO{{Id: 1, Title: "title"}}, nil)
 			},
 			cacheBehavior: func(s *mock_handlers.MockCache, userId int64) {
-				cache := fmt.Sprintf("all%d", userId)
+				cache := expectGeneration(s, userId) + "all"
 
 				s.EXPECT().Get(cache).Return(gomock.Any(), errors.New("some error"))
-				s.EXPECT().Set(cache, []dto.ProjectDTO{{Title: "title"}}, gomock.Any()).Return(nil)
+				s.EXPECT().Set(cache, []dto.ProjectResponseDTO{{Id: 1, Title: "title"}}, gomock.Any()).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -295,8 +310,8 @@ func TestHandler_getAll(t *testing.T) {
 			userId:          2,
 			serviceBehavior: func(s *mock_services.MockProjectService, userId int64) {},
 			cacheBehavior: func(s *mock_handlers.MockCache, userId int64) {
-				s.EXPECT().Get(fmt.Sprintf("all%d", userId)).
-					Return([]dto.ProjectDTO{{Title: "title"}}, nil)
+				s.EXPECT().Get(expectGeneration(s, userId)+"all").
+					Return([]dto.ProjectResponseDTO{{Id: 1, Title: "title"}}, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -311,10 +326,10 @@ func TestHandler_getAll(t *testing.T) {
 			name:   "Service failed",
 			userId: 2,
 			serviceBehavior: func(s *mock_services.MockProjectService, userId int64) {
-				s.EXPECT().GetAll(userId).Return([]dto.ProjectDTO{}, errors.New("some error"))
+				s.EXPECT().GetAll(userId).Return([]dto.ProjectResponseDTO{}, errors.New("some error"))
 			},
 			cacheBehavior: func(s *mock_handlers.MockCache, userId int64) {
-				s.EXPECT().Get(fmt.Sprintf("all%d", userId)).
+				s.EXPECT().Get(expectGeneration(s, userId)+"all").
 					Return(gomock.Any, errors.New("some error"))
 			},
 			expectedStatus:      http.StatusInternalServerError,
@@ -324,13 +339,13 @@ func TestHandler_getAll(t *testing.T) {
 			name:   "Set cache failed",
 			userId: 2,
 			serviceBehavior: func(s *mock_services.MockProjectService, userId int64) {
-				s.EXPECT().GetAll(userId).Return([]dto.ProjectDTO{{Title: "title"}}, nil)
+				s.EXPECT().GetAll(userId).Return([]dto.ProjectResponseDTO{{Id: 1, Title: "title"}}, nil)
 			},
 			cacheBehavior: func(s *mock_handlers.MockCache, userId int64) {
-				cache := fmt.Sprintf("all%d", userId)
+				cache := expectGeneration(s, userId) + "all"
 
 				s.EXPECT().Get(cache).Return(gomock.Any, errors.New("some error"))
-				s.EXPECT().Set(cache, []dto.ProjectDTO{{Title: "title"}}, gomock.Any()).Return(errors.New("some error"))
+				s.EXPECT().Set(cache, []dto.ProjectResponseDTO{{Id: 1, Title: "title"}}, gomock.Any()).Return(errors.New("some error"))
 				s.EXPECT().Delete(cache)
 			},
 			expectedStatus:      http.StatusInternalServerError,
@@ -369,16 +384,332 @@ func TestHandler_getAll(t *testing.T) {
 
 			assert.Equal(t, rec.Code, c.expectedStatus)
 			if c.expectedErrResponse {
-				var responseBody map[string]string
+				var responseBody errResponse
 				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
 				assert.NoError(t, err)
 
-				msg, ok := responseBody["message"]
+				assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+				assert.Equal(t, responseBody.Status, c.expectedStatus)
+				assert.NotEmpty(t, responseBody.Code)
+			} else {
+				assert.Equal(t, rec.Body.String(), `[{"id":1,"user_id":0,"role":"","title":"title","description":"","done":false,"done_from_tasks":false,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","completed_at":null}]`)
+			}
+		})
+	}
+}
+
+func TestHandler_list(t *testing.T) {
+	type mockService func(s *mock_services.MockProjectService, userId int64, query dto.ProjectQueryDTO)
+	type mockCache func(s *mock_handlers.MockCache, userId int64, query dto.ProjectQueryDTO)
+
+	page := dto.ProjectPageDTO{
+		Items:      []dto.ProjectResponseDTO{{Id: 1, Title: "title"}},
+		NextCursor: "cursor",
+	}
+	cursor := dto.ProjectCursor{Sort: "title", Value: "title", Id: 1}
+
+	cases := []struct {
+		name                string
+		rawQuery            string
+		query               dto.ProjectQueryDTO
+		userId              int64
+		serviceBehavior     mockService
+		cacheBehavior       mockCache
+		expectedStatus      int
+		expectedErrResponse bool
+	}{
+		{
+			name:   "OK",
+			userId: 2,
+			query:  dto.ProjectQueryDTO{Limit: 20, Sort: "created_at", Order: "asc"},
+			serviceBehavior: func(s *mock_services.MockProjectService, userId int64, query dto.ProjectQueryDTO) {
+				s.EXPECT().List(userId, query).Return(page, nil)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache, userId int64, query dto.ProjectQueryDTO) {
+				s.EXPECT().Get(fmt.Sprintf("all%d", userId)).Return(gomock.Any(), errors.New("some error"))
+				s.EXPECT().Set(fmt.Sprintf("all%d", userId), gomock.Any(), gomock.Any()).Return(nil)
+				s.EXPECT().Get(gomock.Any()).Return(gomock.Any(), errors.New("some error"))
+				s.EXPECT().Set(gomock.Any(), page, gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "OK with filters and cursor",
+			rawQuery: "limit=5&done=true&search=abc&sort=title&order=desc&cursor=" + cursor.Encode(),
+			userId:   2,
+			query: dto.ProjectQueryDTO{
+				Limit:  5,
+				Cursor: cursor.Encode(),
+				Done:   boolPointer(true),
+				Search: "abc",
+				Sort:   "title",
+				Order:  "desc",
+				After:  &cursor,
+			},
+			serviceBehavior: func(s *mock_services.MockProjectService, userId int64, query dto.ProjectQueryDTO) {
+				s.EXPECT().List(userId, query).Return(page, nil)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache, userId int64, query dto.ProjectQueryDTO) {
+				cache := expectGeneration(s, userId) + query.CacheKey()
+
+				s.EXPECT().Get(cache).Return(gomock.Any(), errors.New("some error"))
+				s.EXPECT().Set(cache, page, gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "OK from cache",
+			userId:          2,
+			query:           dto.ProjectQueryDTO{Limit: 20, Sort: "created_at", Order: "asc"},
+			serviceBehavior: func(s *mock_services.MockProjectService, userId int64, query dto.ProjectQueryDTO) {},
+			cacheBehavior: func(s *mock_handlers.MockCache, userId int64, query dto.ProjectQueryDTO) {
+				s.EXPECT().Get(expectGeneration(s, userId)+query.CacheKey()).Return(page, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:                "Invalid sort",
+			rawQuery:            "sort=user_id",
+			userId:              2,
+			serviceBehavior:     func(s *mock_services.MockProjectService, userId int64, query dto.ProjectQueryDTO) {},
+			cacheBehavior:       func(s *mock_handlers.MockCache, userId int64, query dto.ProjectQueryDTO) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedErrResponse: true,
+		},
+		{
+			name:                "Invalid cursor",
+			rawQuery:            "cursor=invalid",
+			userId:              2,
+			serviceBehavior:     func(s *mock_services.MockProjectService, userId int64, query dto.ProjectQueryDTO) {},
+			cacheBehavior:       func(s *mock_handlers.MockCache, userId int64, query dto.ProjectQueryDTO) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedErrResponse: true,
+		},
+		{
+			name:                "Cursor of another sorting",
+			rawQuery:            "sort=updated_at&cursor=" + cursor.Encode(),
+			userId:              2,
+			serviceBehavior:     func(s *mock_services.MockProjectService, userId int64, query dto.ProjectQueryDTO) {},
+			cacheBehavior:       func(s *mock_handlers.MockCache, userId int64, query dto.ProjectQueryDTO) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedErrResponse: true,
+		},
+		{
+			name:                "Invalid created range",
+			rawQuery:            "created_from=2024-02-01T00:00:00Z&created_to=2024-01-01T00:00:00Z",
+			userId:              2,
+			serviceBehavior:     func(s *mock_services.MockProjectService, userId int64, query dto.ProjectQueryDTO) {},
+			cacheBehavior:       func(s *mock_handlers.MockCache, userId int64, query dto.ProjectQueryDTO) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedErrResponse: true,
+		},
+		{
+			name:                "User unauthorized",
+			serviceBehavior:     func(s *mock_services.MockProjectService, userId int64, query dto.ProjectQueryDTO) {},
+			cacheBehavior:       func(s *mock_handlers.MockCache, userId int64, query dto.ProjectQueryDTO) {},
+			expectedStatus:      http.StatusUnauthorized,
+			expectedErrResponse: true,
+		},
+		{
+			name:   "Service failed",
+			userId: 2,
+			query:  dto.ProjectQueryDTO{Limit: 20, Sort: "created_at", Order: "asc"},
+			serviceBehavior: func(s *mock_services.MockProjectService, userId int64, query dto.ProjectQueryDTO) {
+				s.EXPECT().List(userId, query).Return(dto.ProjectPageDTO{}, errors.New("some error"))
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache, userId int64, query dto.ProjectQueryDTO) {
+				s.EXPECT().Get(expectGeneration(s, userId)+query.CacheKey()).
+					Return(gomock.Any(), errors.New("some error"))
+			},
+			expectedStatus:      http.StatusInternalServerError,
+			expectedErrResponse: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			serviceMock := mock_services.NewMockProjectService(ctrl)
+			c.serviceBehavior(serviceMock, c.userId, c.query)
 
-				assert.True(t, ok)
-				assert.NotEmpty(t, msg)
+			cacheMock := mock_handlers.NewMockCache(ctrl)
+			c.cacheBehavior(cacheMock, c.userId, c.query)
+
+			serv := services.AbstractService{ProjectService: serviceMock}
+			h := Handler{
+				service: &serv,
+				cache:   cacheMock,
+			}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			target := "/list"
+			r.GET(target, h.list)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", target+"?"+c.rawQuery, nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedErrResponse {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+				assert.Equal(t, responseBody.Status, c.expectedStatus)
+				assert.NotEmpty(t, responseBody.Code)
 			} else {
-				assert.Equal(t, rec.Body.String(), `[{"title":"title","description":"","done":false}]`)
+				var responseBody dto.ProjectPageDTO
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+				assert.Equal(t, responseBody, page)
+			}
+		})
+	}
+}
+
+func TestHandler_replaceById(t *testing.T) {
+	type mockService func(s *mock_services.MockProjectService, projectId int64,
+		input dto.UpdateProjectDTO, userId int64)
+	type mockCache func(s *mock_handlers.MockCache, projectId, userId int64)
+
+	cases := []struct {
+		name                string
+		path                string
+		projectId           int64
+		userId              int64
+		body                string
+		input               dto.UpdateProjectDTO
+		serviceBehavior     mockService
+		cacheBehavior       mockCache
+		expectedStatus      int
+		expectedErrResponse bool
+	}{
+		{
+			name:      "OK",
+			path:      "1",
+			projectId: 1,
+			userId:    2,
+			body:      `{"title":"title"}`,
+			input: dto.UpdateProjectDTO{
+				Title:         stringPointer("title"),
+				Description:   stringPointer(""),
+				Done:          boolPointer(false),
+				DoneFromTasks: boolPointer(false),
+			},
+			serviceBehavior: func(s *mock_services.MockProjectService, projectId int64,
+				input dto.UpdateProjectDTO, userId int64) {
+				s.EXPECT().UpdateById(projectId, input, userId).Return(nil)
+				s.EXPECT().MemberIds(projectId).Return([]int64{userId}, nil)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache, projectId, userId int64) {
+				s.EXPECT().Delete(fmt.Sprintf("project:%d:%d", projectId, userId))
+				s.EXPECT().Delete(fmt.Sprintf("all%d", userId))
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Invalid path",
+			path:   "invalid",
+			userId: 2,
+			body:   `{"title":"title"}`,
+			serviceBehavior: func(s *mock_services.MockProjectService, projectId int64,
+				input dto.UpdateProjectDTO, userId int64) {
+			},
+			cacheBehavior:       func(s *mock_handlers.MockCache, projectId, userId int64) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedErrResponse: true,
+		},
+		{
+			name:   "Missing required field",
+			path:   "1",
+			userId: 2,
+			body:   `{"done":true}`,
+			serviceBehavior: func(s *mock_services.MockProjectService, projectId int64,
+				input dto.UpdateProjectDTO, userId int64) {
+			},
+			cacheBehavior:       func(s *mock_handlers.MockCache, projectId, userId int64) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedErrResponse: true,
+		},
+		{
+			name: "User unauthorized",
+			path: "1",
+			body: `{"title":"title"}`,
+			serviceBehavior: func(s *mock_services.MockProjectService, projectId int64,
+				input dto.UpdateProjectDTO, userId int64) {
+			},
+			cacheBehavior:       func(s *mock_handlers.MockCache, projectId, userId int64) {},
+			expectedStatus:      http.StatusUnauthorized,
+			expectedErrResponse: true,
+		},
+		{
+			name:      "Service failed",
+			path:      "1",
+			projectId: 1,
+			userId:    2,
+			body:      `{"title":"title","description":"description","done":true}`,
+			input: dto.UpdateProjectDTO{
+				Title:         stringPointer("title"),
+				Description:   stringPointer("description"),
+				Done:          boolPointer(true),
+				DoneFromTasks: boolPointer(false),
+			},
+			serviceBehavior: func(s *mock_services.MockProjectService, projectId int64,
+				input dto.UpdateProjectDTO, userId int64) {
+				s.EXPECT().UpdateById(projectId, input, userId).Return(errors.New("some error"))
+			},
+			cacheBehavior:       func(s *mock_handlers.MockCache, projectId, userId int64) {},
+			expectedStatus:      http.StatusInternalServerError,
+			expectedErrResponse: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			serviceMock := mock_services.NewMockProjectService(ctrl)
+			c.serviceBehavior(serviceMock, c.projectId, c.input, c.userId)
+
+			cacheMock := mock_handlers.NewMockCache(ctrl)
+			c.cacheBehavior(cacheMock, c.projectId, c.userId)
+
+			serv := services.AbstractService{ProjectService: serviceMock}
+			h := Handler{
+				service: &serv,
+				cache:   cacheMock,
+			}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			r.PUT("/projects/:id", h.replaceById)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/projects/"+c.path, bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedErrResponse {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+				assert.Equal(t, responseBody.Status, c.expectedStatus)
+				assert.NotEmpty(t, responseBody.Code)
+			} else {
+				assert.Equal(t, rec.Body.String(), `{"message":"ok"}`)
 			}
 		})
 	}
@@ -409,9 +740,29 @@ func TestHandler_updateById(t *testing.T) {
 			serviceBehavior: func(s *mock_services.MockProjectService, projectId int64,
 				input dto.UpdateProjectDTO, userId int64) {
 				s.EXPECT().UpdateById(projectId, input, userId).Return(nil)
+				s.EXPECT().MemberIds(projectId).Return([]int64{userId}, nil)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache, projectId, userId int64) {
+				s.EXPECT().Delete(fmt.Sprintf("project:%d:%d", projectId, userId))
+				s.EXPECT().Delete(fmt.Sprintf("all%d", userId))
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "Shared project",
+			projectId: 1,
+			userId:    2,
+			body:      `{"done":true}`,
+			input:     dto.UpdateProjectDTO{Done: boolPointer(true)},
+			serviceBehavior: func(s *mock_services.MockProjectService, projectId int64,
+				input dto.UpdateProjectDTO, userId int64) {
+				s.EXPECT().UpdateById(projectId, input, userId).Return(nil)
+				s.EXPECT().MemberIds(projectId).Return([]int64{3, userId}, nil)
 			},
 			cacheBehavior: func(s *mock_handlers.MockCache, projectId, userId int64) {
-				s.EXPECT().Delete(fmt.Sprintf("%d%d", projectId, userId))
+				s.EXPECT().Delete(fmt.Sprintf("project:%d:%d", projectId, 3))
+				s.EXPECT().Delete("all3")
+				s.EXPECT().Delete(fmt.Sprintf("project:%d:%d", projectId, userId))
 				s.EXPECT().Delete(fmt.Sprintf("all%d", userId))
 			},
 			expectedStatus: http.StatusOK,
@@ -452,6 +803,20 @@ func TestHandler_updateById(t *testing.T) {
 			expectedStatus:      http.StatusInternalServerError,
 			expectedErrResponse: true,
 		},
+		{
+			name:      "Not found",
+			projectId: 1,
+			userId:    2,
+			input:     dto.UpdateProjectDTO{Done: boolPointer(true)},
+			body:      `{"done":true}`,
+			serviceBehavior: func(s *mock_services.MockProjectService, projectId int64,
+				input dto.UpdateProjectDTO, userId int64) {
+				s.EXPECT().UpdateById(projectId, input, userId).Return(errNotFound)
+			},
+			cacheBehavior:       func(s *mock_handlers.MockCache, projectId, userId int64) {},
+			expectedStatus:      http.StatusNotFound,
+			expectedErrResponse: true,
+		},
 	}
 
 	for _, c := range cases {
@@ -476,7 +841,7 @@ func TestHandler_updateById(t *testing.T) {
 				ctx.Set("user_id", c.userId)
 			})
 			target := "/update"
-			r.POST(target, h.updateById)
+			r.POST(target, idFromQuery, h.updateById)
 
 			rec := httptest.NewRecorder()
 			req := httptest.NewRequest("POST", fmt.Sprintf("%s?id=%d", target, c.projectId),
@@ -486,14 +851,13 @@ func TestHandler_updateById(t *testing.T) {
 
 			assert.Equal(t, rec.Code, c.expectedStatus)
 			if c.expectedErrResponse {
-				var responseBody map[string]string
+				var responseBody errResponse
 				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
 				assert.NoError(t, err)
 
-				msg, ok := responseBody["message"]
-
-				assert.True(t, ok)
-				assert.NotEmpty(t, msg)
+				assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+				assert.Equal(t, responseBody.Status, c.expectedStatus)
+				assert.NotEmpty(t, responseBody.Code)
 			} else {
 				assert.Equal(t, rec.Body.String(), `{"message":"ok"}`)
 			}
@@ -519,10 +883,11 @@ func TestHandler_deleteById(t *testing.T) {
 			projectId: 1,
 			userId:    2,
 			serviceBehavior: func(s *mock_services.MockProjectService, projectId, userId int64) {
+				s.EXPECT().MemberIds(projectId).Return([]int64{userId}, nil)
 				s.EXPECT().DeleteById(projectId, userId).Return(nil)
 			},
 			cacheBehavior: func(s *mock_handlers.MockCache, projectId, userId int64) {
-				s.EXPECT().Delete(fmt.Sprintf("%d%d", projectId, userId))
+				s.EXPECT().Delete(fmt.Sprintf("project:%d:%d", projectId, userId))
 				s.EXPECT().Delete(fmt.Sprintf("all%d", userId))
 			},
 			expectedStatus: http.StatusOK,
@@ -548,12 +913,25 @@ func TestHandler_deleteById(t *testing.T) {
 			projectId: 1,
 			userId:    2,
 			serviceBehavior: func(s *mock_services.MockProjectService, projectId, userId int64) {
+				s.EXPECT().MemberIds(projectId).Return([]int64{userId}, nil)
 				s.EXPECT().DeleteById(projectId, userId).Return(errors.New("some error"))
 			},
 			cacheBehavior:       func(s *mock_handlers.MockCache, projectId, userId int64) {},
 			expectedStatus:      http.StatusInternalServerError,
 			expectedErrResponse: true,
 		},
+		{
+			name:      "Not found",
+			projectId: 1,
+			userId:    2,
+			serviceBehavior: func(s *mock_services.MockProjectService, projectId, userId int64) {
+				s.EXPECT().MemberIds(projectId).Return(nil, nil)
+				s.EXPECT().DeleteById(projectId, userId).Return(errNotFound)
+			},
+			cacheBehavior:       func(s *mock_handlers.MockCache, projectId, userId int64) {},
+			expectedStatus:      http.StatusNotFound,
+			expectedErrResponse: true,
+		},
 	}
 
 	for _, c := range cases {
@@ -578,7 +956,7 @@ func TestHandler_deleteById(t *testing.T) {
 				ctx.Set("user_id", c.userId)
 			})
 			target := "/delete"
-			r.DELETE(target, h.deleteById)
+			r.DELETE(target, idFromQuery, h.deleteById)
 
 			rec := httptest.NewRecorder()
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("%s?id=%d", target, c.projectId), nil)
@@ -587,14 +965,13 @@ func TestHandler_deleteById(t *testing.T) {
 
 			assert.Equal(t, rec.Code, c.expectedStatus)
 			if c.expectedErrResponse {
-				var responseBody map[string]string
+				var responseBody errResponse
 				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
 				assert.NoError(t, err)
 
-				msg, ok := responseBody["message"]
-
-				assert.True(t, ok)
-				assert.NotEmpty(t, msg)
+				assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+				assert.Equal(t, responseBody.Status, c.expectedStatus)
+				assert.NotEmpty(t, responseBody.Code)
 			} else {
 				assert.Equal(t, rec.Body.String(), `{"message":"ok"}`)
 			}
@@ -602,6 +979,18 @@ func TestHandler_deleteById(t *testing.T) {
 	}
 }
 
+// expectGeneration expects a lookup of the list generation of the user
+// and returns the prefix of the list cache keys.
+func expectGeneration(s *mock_handlers.MockCache, userId int64) string {
+	s.EXPECT().Get(fmt.Sprintf("all%d", userId)).Return(int64(1), nil)
+
+	return fmt.Sprintf("all%d:1:", userId)
+}
+
 func boolPointer(b bool) *bool {
 	return &b
 }
+
+func stringPointer(str string) *string {
+	return &str
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// getMembers godoc
+//
+//	@Summary		getMembers
+//	@Description	get the members of a project
+//	@Tags			project members
+//	@Security		ApiKeyAuth
+//	@Produce		json
+//	@Param			id		path		integer	true	"project id"
+//	@Success		200		{array}		dto.ProjectMemberDTO
+//	@Failure		400		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/v1/projects/{id}/members [get]
+func (h *Handler) getMembers(c *gin.Context) {
+	userId := c.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(c, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	projectId, err := pathIdParam(c, "id")
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	members, err := h.service.ProjectService.GetMembers(projectId, userId)
+	if err != nil {
+		newServiceErrResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// addMember godoc
+//
+//	@Summary		addMember
+//	@Description	invite a user to a project by username or email, only the owner may add members
+//	@Tags			project members
+//	@Security		ApiKeyAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		integer					true	"project id"
+//	@Param			input	body		dto.AddProjectMemberDTO	true	"user and role"
+//	@Success		201		{object}	dto.ProjectMemberDTO
+//	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		409		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/v1/projects/{id}/members [post]
+func (h *Handler) addMember(c *gin.Context) {
+	userId := c.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(c, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	projectId, err := pathIdParam(c, "id")
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var input dto.AddProjectMemberDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(c, err)
+		return
+	}
+
+	member, err := h.service.ProjectService.AddMember(projectId, userId, input)
+	if err != nil {
+		newServiceErrResponse(c, err)
+		return
+	}
+
+	h.invalidateProject(projectId, []int64{member.UserId})
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// updateMember godoc
+//
+//	@Summary		updateMember
+//	@Description	change the role of a member, only the owner may change roles
+//	@Tags			project members
+//	@Security		ApiKeyAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		integer						true	"project id"
+//	@Param			user_id	path		integer						true	"user id of the member"
+//	@Param			input	body		dto.UpdateProjectMemberDTO	true	"new role"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		409		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/v1/projects/{id}/members/{user_id} [patch]
+func (h *Handler) updateMember(c *gin.Context) {
+	userId := c.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(c, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	projectId, memberId, err := memberParams(c)
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var input dto.UpdateProjectMemberDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(c, err)
+		return
+	}
+
+	if err := h.service.ProjectService.UpdateMemberRole(projectId, userId, memberId, input); err != nil {
+		newServiceErrResponse(c, err)
+		return
+	}
+
+	h.invalidateProject(projectId, []int64{memberId})
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// removeMember godoc
+//
+//	@Summary		removeMember
+//	@Description	remove a member from a project, the owner may remove any other member and members may leave
+//	@Tags			project members
+//	@Security		ApiKeyAuth
+//	@Produce		json
+//	@Param			id		path		integer	true	"project id"
+//	@Param			user_id	path		integer	true	"user id of the member"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		409		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/v1/projects/{id}/members/{user_id} [delete]
+func (h *Handler) removeMember(c *gin.Context) {
+	userId := c.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(c, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	projectId, memberId, err := memberParams(c)
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.ProjectService.RemoveMember(projectId, userId, memberId); err != nil {
+		newServiceErrResponse(c, err)
+		return
+	}
+
+	h.invalidateProject(projectId, []int64{memberId})
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// transferProject godoc
+//
+//	@Summary		transferProject
+//	@Description	make another member the owner of a project, the previous owner stays an editor
+//	@Tags			project members
+//	@Security		ApiKeyAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		integer					true	"project id"
+//	@Param			input	body		dto.TransferProjectDTO	true	"user id of the new owner"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/v1/projects/{id}/transfer [post]
+func (h *Handler) transferProject(c *gin.Context) {
+	userId := c.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(c, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	projectId, err := pathIdParam(c, "id")
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var input dto.TransferProjectDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(c, err)
+		return
+	}
+
+	if err := h.service.ProjectService.TransferOwnership(projectId, userId, input); err != nil {
+		newServiceErrResponse(c, err)
+		return
+	}
+
+	// the owner is part of every member's copy of the project
+	h.invalidateProject(projectId, h.projectMemberIds(projectId, userId))
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// memberParams reads the project id and the user id of a member from the path.
+func memberParams(c *gin.Context) (int64, int64, error) {
+	projectId, err := pathIdParam(c, "id")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	memberId, err := pathIdParam(c, "user_id")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return projectId, memberId, nil
+}
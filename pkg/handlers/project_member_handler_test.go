@@ -0,0 +1,415 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	mock_handlers "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/handlers/mocks"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	mock_services "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errProjectForbidden = apperr.Forbidden("project_forbidden", "the role in the project does not allow this")
+	errOwnerMembership  = apperr.Conflict("project_owner",
+		"the owner can not be changed or removed, transfer the ownership first")
+)
+
+func TestHandler_getMembers(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockProjectService)
+
+	cases := []struct {
+		name                 string
+		path                 string
+		userId               int64
+		mockBehavior         mockBehavior
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name:   "OK",
+			path:   "1",
+			userId: 2,
+			mockBehavior: func(s *mock_services.MockProjectService) {
+				s.EXPECT().GetMembers(int64(1), int64(2)).
+					Return([]dto.ProjectMemberDTO{{UserId: 2, Username: "owner", Role: dto.ProjectRoleOwner}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponseBody: `[{"user_id":2,"username":"owner","name":"","role":"owner",` +
+				`"created_at":"0001-01-01T00:00:00Z"}]`,
+		},
+		{
+			name:           "Invalid path",
+			path:           "invalid",
+			userId:         2,
+			mockBehavior:   func(s *mock_services.MockProjectService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Not found",
+			path:   "1",
+			userId: 2,
+			mockBehavior: func(s *mock_services.MockProjectService) {
+				s.EXPECT().GetMembers(int64(1), int64(2)).Return(nil, errNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			serviceMock := mock_services.NewMockProjectService(ctrl)
+			c.mockBehavior(serviceMock)
+
+			serv := services.AbstractService{ProjectService: serviceMock}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			r.GET("/projects/:id/members", h.getMembers)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/projects/"+c.path+"/members", nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedResponseBody != "" {
+				assert.Equal(t, rec.Body.String(), c.expectedResponseBody)
+			}
+		})
+	}
+}
+
+func TestHandler_addMember(t *testing.T) {
+	type mockService func(s *mock_services.MockProjectService)
+	type mockCache func(s *mock_handlers.MockCache)
+
+	cases := []struct {
+		name            string
+		body            string
+		serviceBehavior mockService
+		cacheBehavior   mockCache
+		expectedStatus  int
+		expectedCode    string
+	}{
+		{
+			name: "OK",
+			body: `{"email":"editor@mail.com","role":"editor"}`,
+			serviceBehavior: func(s *mock_services.MockProjectService) {
+				s.EXPECT().AddMember(int64(1), int64(2), dto.AddProjectMemberDTO{Email: "editor@mail.com", Role: "editor"}).
+					Return(dto.ProjectMemberDTO{UserId: 3, Username: "editor", Role: dto.ProjectRoleEditor}, nil)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache) {
+				s.EXPECT().Delete("project:1:3")
+				s.EXPECT().Delete("all3")
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:            "Username and email",
+			body:            `{"username":"editor","email":"editor@mail.com","role":"editor"}`,
+			serviceBehavior: func(s *mock_services.MockProjectService) {},
+			cacheBehavior:   func(s *mock_handlers.MockCache) {},
+			expectedStatus:  http.StatusBadRequest,
+			expectedCode:    "validation_failed",
+		},
+		{
+			name:            "Owner role",
+			body:            `{"username":"editor","role":"owner"}`,
+			serviceBehavior: func(s *mock_services.MockProjectService) {},
+			cacheBehavior:   func(s *mock_handlers.MockCache) {},
+			expectedStatus:  http.StatusBadRequest,
+			expectedCode:    "validation_failed",
+		},
+		{
+			name: "Not the owner",
+			body: `{"username":"editor","role":"viewer"}`,
+			serviceBehavior: func(s *mock_services.MockProjectService) {
+				s.EXPECT().AddMember(int64(1), int64(2), dto.AddProjectMemberDTO{Username: "editor", Role: "viewer"}).
+					Return(dto.ProjectMemberDTO{}, errProjectForbidden)
+			},
+			cacheBehavior:  func(s *mock_handlers.MockCache) {},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "project_forbidden",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			serviceMock := mock_services.NewMockProjectService(ctrl)
+			c.serviceBehavior(serviceMock)
+
+			cacheMock := mock_handlers.NewMockCache(ctrl)
+			c.cacheBehavior(cacheMock)
+
+			serv := services.AbstractService{ProjectService: serviceMock}
+			h := Handler{service: &serv, cache: cacheMock}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", int64(2))
+			})
+			r.POST("/projects/:id/members", h.addMember)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/projects/1/members", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedCode != "" {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, responseBody.Code, c.expectedCode)
+			}
+		})
+	}
+}
+
+func TestHandler_updateMember(t *testing.T) {
+	type mockService func(s *mock_services.MockProjectService)
+	type mockCache func(s *mock_handlers.MockCache)
+
+	input := dto.UpdateProjectMemberDTO{Role: dto.ProjectRoleViewer}
+
+	cases := []struct {
+		name            string
+		path            string
+		body            string
+		serviceBehavior mockService
+		cacheBehavior   mockCache
+		expectedStatus  int
+	}{
+		{
+			name: "OK",
+			path: "1/members/3",
+			body: `{"role":"viewer"}`,
+			serviceBehavior: func(s *mock_services.MockProjectService) {
+				s.EXPECT().UpdateMemberRole(int64(1), int64(2), int64(3), input).Return(nil)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache) {
+				s.EXPECT().Delete("project:1:3")
+				s.EXPECT().Delete("all3")
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "Invalid member",
+			path:            "1/members/invalid",
+			body:            `{"role":"viewer"}`,
+			serviceBehavior: func(s *mock_services.MockProjectService) {},
+			cacheBehavior:   func(s *mock_handlers.MockCache) {},
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			name: "Owner",
+			path: "1/members/2",
+			body: `{"role":"viewer"}`,
+			serviceBehavior: func(s *mock_services.MockProjectService) {
+				s.EXPECT().UpdateMemberRole(int64(1), int64(2), int64(2), input).Return(errOwnerMembership)
+			},
+			cacheBehavior:  func(s *mock_handlers.MockCache) {},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			serviceMock := mock_services.NewMockProjectService(ctrl)
+			c.serviceBehavior(serviceMock)
+
+			cacheMock := mock_handlers.NewMockCache(ctrl)
+			c.cacheBehavior(cacheMock)
+
+			serv := services.AbstractService{ProjectService: serviceMock}
+			h := Handler{service: &serv, cache: cacheMock}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", int64(2))
+			})
+			r.PATCH("/projects/:id/members/:user_id", h.updateMember)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/projects/"+c.path, bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+		})
+	}
+}
+
+func TestHandler_removeMember(t *testing.T) {
+	type mockService func(s *mock_services.MockProjectService, memberId int64)
+	type mockCache func(s *mock_handlers.MockCache, memberId int64)
+
+	cases := []struct {
+		name            string
+		memberId        int64
+		serviceBehavior mockService
+		cacheBehavior   mockCache
+		expectedStatus  int
+	}{
+		{
+			name:     "OK",
+			memberId: 3,
+			serviceBehavior: func(s *mock_services.MockProjectService, memberId int64) {
+				s.EXPECT().RemoveMember(int64(1), int64(2), memberId).Return(nil)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache, memberId int64) {
+				s.EXPECT().Delete(fmt.Sprintf("project:1:%d", memberId))
+				s.EXPECT().Delete(fmt.Sprintf("all%d", memberId))
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "Leave",
+			memberId: 2,
+			serviceBehavior: func(s *mock_services.MockProjectService, memberId int64) {
+				s.EXPECT().RemoveMember(int64(1), int64(2), memberId).Return(nil)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache, memberId int64) {
+				s.EXPECT().Delete(fmt.Sprintf("project:1:%d", memberId))
+				s.EXPECT().Delete(fmt.Sprintf("all%d", memberId))
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "Not the owner",
+			memberId: 3,
+			serviceBehavior: func(s *mock_services.MockProjectService, memberId int64) {
+				s.EXPECT().RemoveMember(int64(1), int64(2), memberId).Return(errProjectForbidden)
+			},
+			cacheBehavior:  func(s *mock_handlers.MockCache, memberId int64) {},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			serviceMock := mock_services.NewMockProjectService(ctrl)
+			c.serviceBehavior(serviceMock, c.memberId)
+
+			cacheMock := mock_handlers.NewMockCache(ctrl)
+			c.cacheBehavior(cacheMock, c.memberId)
+
+			serv := services.AbstractService{ProjectService: serviceMock}
+			h := Handler{service: &serv, cache: cacheMock}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", int64(2))
+			})
+			r.DELETE("/projects/:id/members/:user_id", h.removeMember)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", fmt.Sprintf("/projects/1/members/%d", c.memberId), nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+		})
+	}
+}
+
+func TestHandler_transferProject(t *testing.T) {
+	type mockService func(s *mock_services.MockProjectService)
+	type mockCache func(s *mock_handlers.MockCache)
+
+	cases := []struct {
+		name            string
+		body            string
+		serviceBehavior mockService
+		cacheBehavior   mockCache
+		expectedStatus  int
+	}{
+		{
+			name: "OK",
+			body: `{"user_id":3}`,
+			serviceBehavior: func(s *mock_services.MockProjectService) {
+				s.EXPECT().TransferOwnership(int64(1), int64(2), dto.TransferProjectDTO{UserId: 3}).Return(nil)
+				s.EXPECT().MemberIds(int64(1)).Return([]int64{2, 3}, nil)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache) {
+				s.EXPECT().Delete("project:1:2")
+				s.EXPECT().Delete("all2")
+				s.EXPECT().Delete("project:1:3")
+				s.EXPECT().Delete("all3")
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "Without user",
+			body:            `{}`,
+			serviceBehavior: func(s *mock_services.MockProjectService) {},
+			cacheBehavior:   func(s *mock_handlers.MockCache) {},
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			name: "Not the owner",
+			body: `{"user_id":3}`,
+			serviceBehavior: func(s *mock_services.MockProjectService) {
+				s.EXPECT().TransferOwnership(int64(1), int64(2), dto.TransferProjectDTO{UserId: 3}).
+					Return(errProjectForbidden)
+			},
+			cacheBehavior:  func(s *mock_handlers.MockCache) {},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			serviceMock := mock_services.NewMockProjectService(ctrl)
+			c.serviceBehavior(serviceMock)
+
+			cacheMock := mock_handlers.NewMockCache(ctrl)
+			c.cacheBehavior(cacheMock)
+
+			serv := services.AbstractService{ProjectService: serviceMock}
+			h := Handler{service: &serv, cache: cacheMock}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", int64(2))
+			})
+			r.POST("/projects/:id/transfer", h.transferProject)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/projects/1/transfer", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+		})
+	}
+}
@@ -1,23 +1,211 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	"github.com/sirupsen/logrus"
 )
 
+const problemContentType = "application/problem+json"
+
+// errResponse is an RFC 7807 problem details object
+// extended with a machine-readable code, the request id and failed fields.
 type errResponse struct {
-	Message string `json:"message"`
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      string              `json:"code"`
+	RequestId string              `json:"request_id,omitempty"`
+	Errors    []apperr.FieldError `json:"errors,omitempty"`
 }
 
 type statusResponse struct {
 	Message string `json:"message"`
 }
 
+// statusCodes are the error codes of responses that are not caused by a domain error.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          "invalid_request",
+	http.StatusUnauthorized:        "unauthorized",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not_found",
+	http.StatusConflict:            "conflict",
+	http.StatusTooManyRequests:     "too_many_requests",
+	http.StatusInternalServerError: "internal_error",
+}
+
+// kindStatuses maps the kinds of domain errors to HTTP statuses.
+var kindStatuses = map[apperr.Kind]int{
+	apperr.KindNotFound:        http.StatusNotFound,
+	apperr.KindConflict:        http.StatusConflict,
+	apperr.KindValidation:      http.StatusBadRequest,
+	apperr.KindUnauthorized:    http.StatusUnauthorized,
+	apperr.KindForbidden:       http.StatusForbidden,
+	apperr.KindTooManyRequests: http.StatusTooManyRequests,
+}
+
+func init() {
+	// report fields by the names clients send instead of the names of struct fields
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
 func newErrResponse(ctx *gin.Context, status int, err string) {
-	logrus.WithFields(logrus.Fields{
-		"uri":    ctx.Request.RequestURI,
-		"method": ctx.Request.Method,
-	}).Error(err)
+	code, ok := statusCodes[status]
+	if !ok {
+		code = "error"
+	}
+
+	abortWithProblem(ctx, status, code, err, nil)
+}
+
+// newServiceErrResponse responds with the status and code of the domain error
+// returned by a service, any other error is treated as internal.
+func newServiceErrResponse(ctx *gin.Context, err error) {
+	var domainErr *apperr.Error
+	if !errors.As(err, &domainErr) {
+		newErrResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	status, ok := kindStatuses[domainErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	if domainErr.RetryAfter > 0 {
+		// whole seconds, rounded up so the client does not retry too early
+		ctx.Header("Retry-After", strconv.FormatInt(int64(math.Ceil(domainErr.RetryAfter.Seconds())), 10))
+	}
+
+	abortWithProblem(ctx, status, domainErr.Code, domainErr.Message, domainErr.Fields)
+}
+
+// newValidationErrResponse responds with 400 to input that could not be bound
+// or validated and lists every field and rule that failed.
+func newValidationErrResponse(ctx *gin.Context, err error) {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+		syntaxErr      *json.SyntaxError
+	)
+
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]apperr.FieldError, len(validationErrs))
+		for i, fe := range validationErrs {
+			fields[i] = apperr.FieldError{
+				Field:  fieldPath(fe),
+				Rule:   fe.Tag(),
+				Detail: ruleDetail(fe),
+			}
+		}
+
+		abortWithProblem(ctx, http.StatusBadRequest, "validation_failed", "request has invalid fields", fields)
+	case errors.As(err, &typeErr):
+		abortWithProblem(ctx, http.StatusBadRequest, "validation_failed", "request has invalid fields",
+			[]apperr.FieldError{{
+				Field:  typeErr.Field,
+				Rule:   "type",
+				Detail: fmt.Sprintf("must be of type %s", typeErr.Type),
+			}})
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		abortWithProblem(ctx, http.StatusBadRequest, "malformed_body", "request body is not valid JSON", nil)
+	default:
+		newErrResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+}
+
+// abortWithProblem logs the error and writes the problem details.
+// The detail of internal errors stays in the log and is not sent to the client.
+func abortWithProblem(ctx *gin.Context, status int, code, detail string, fields []apperr.FieldError) {
+	requestId := ctx.GetString("request_id")
+
+	entry := logrus.WithFields(logrus.Fields{
+		"uri":        ctx.Request.RequestURI,
+		"method":     ctx.Request.Method,
+		"code":       code,
+		"request_id": requestId,
+	})
+
+	if status >= http.StatusInternalServerError {
+		entry.Error(detail)
+		detail = "internal server error"
+	} else {
+		entry.Info(detail)
+	}
+
+	ctx.Header("Content-Type", problemContentType)
+	ctx.AbortWithStatusJSON(status, errResponse{
+		Type:      "/problems/" + strings.ReplaceAll(code, "_", "-"),
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  ctx.Request.URL.Path,
+		Code:      code,
+		RequestId: requestId,
+		Errors:    fields,
+	})
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+// fieldPath drops the name of the top-level struct from the namespace of the field.
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+
+	return ns
+}
 
-	ctx.AbortWithStatusJSON(status, errResponse{err})
+func ruleDetail(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "gte", "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "lte", "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	default:
+		return fmt.Sprintf("failed on the %s rule", fe.Tag())
+	}
 }
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServiceErrResponse(t *testing.T) {
+	cases := []struct {
+		name             string
+		err              error
+		expectedStatus   int
+		expectedResponse errResponse
+	}{
+		{
+			name:           "Not found",
+			err:            apperr.NotFound("project_not_found", "project not found"),
+			expectedStatus: http.StatusNotFound,
+			expectedResponse: errResponse{
+				Type:     "/problems/project-not-found",
+				Title:    "Not Found",
+				Status:   http.StatusNotFound,
+				Detail:   "project not found",
+				Instance: "/projects/1",
+				Code:     "project_not_found",
+			},
+		},
+		{
+			name:           "Conflict",
+			err:            apperr.Conflict("email_taken", "email is already taken"),
+			expectedStatus: http.StatusConflict,
+			expectedResponse: errResponse{
+				Type:     "/problems/email-taken",
+				Title:    "Conflict",
+				Status:   http.StatusConflict,
+				Detail:   "email is already taken",
+				Instance: "/projects/1",
+				Code:     "email_taken",
+			},
+		},
+		{
+			name:           "Validation",
+			err:            apperr.Validation("invalid_cursor", "invalid cursor"),
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: errResponse{
+				Type:     "/problems/invalid-cursor",
+				Title:    "Bad Request",
+				Status:   http.StatusBadRequest,
+				Detail:   "invalid cursor",
+				Instance: "/projects/1",
+				Code:     "invalid_cursor",
+			},
+		},
+		{
+			name:           "Unauthorized",
+			err:            fmt.Errorf("wrapped: %w", apperr.Unauthorized("invalid_credentials", "invalid credentials")),
+			expectedStatus: http.StatusUnauthorized,
+			expectedResponse: errResponse{
+				Type:     "/problems/invalid-credentials",
+				Title:    "Unauthorized",
+				Status:   http.StatusUnauthorized,
+				Detail:   "invalid credentials",
+				Instance: "/projects/1",
+				Code:     "invalid_credentials",
+			},
+		},
+		{
+			name:           "Internal",
+			err:            errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedResponse: errResponse{
+				Type:      "/problems/internal-error",
+				Title:     "Internal Server Error",
+				Status:    http.StatusInternalServerError,
+				Detail:    "internal server error",
+				Instance:  "/projects/1",
+				Code:      "internal_error",
+				RequestId: "request",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(rec)
+			ctx.Request = httptest.NewRequest("GET", "/projects/1", nil)
+			ctx.Set("request_id", c.expectedResponse.RequestId)
+
+			newServiceErrResponse(ctx, c.err)
+
+			var got errResponse
+			err := json.Unmarshal(rec.Body.Bytes(), &got)
+
+			assert.NoError(t, err)
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+			assert.Equal(t, got, c.expectedResponse)
+		})
+	}
+}
+
+func TestNewValidationErrResponse(t *testing.T) {
+	type input struct {
+		Title string `json:"title" binding:"required"`
+		Limit int    `json:"limit" binding:"omitempty,gte=1"`
+	}
+
+	cases := []struct {
+		name           string
+		body           string
+		expectedCode   string
+		expectedErrors []apperr.FieldError
+	}{
+		{
+			name:         "Failed rules",
+			body:         `{"limit":0}`,
+			expectedCode: "validation_failed",
+			expectedErrors: []apperr.FieldError{
+				{Field: "title", Rule: "required", Detail: "is required"},
+			},
+		},
+		{
+			name:         "Failed several rules",
+			body:         `{"limit":-1}`,
+			expectedCode: "validation_failed",
+			expectedErrors: []apperr.FieldError{
+				{Field: "title", Rule: "required", Detail: "is required"},
+				{Field: "limit", Rule: "gte", Detail: "must be at least 1"},
+			},
+		},
+		{
+			name:         "Wrong type",
+			body:         `{"title":"title","limit":"ten"}`,
+			expectedCode: "validation_failed",
+			expectedErrors: []apperr.FieldError{
+				{Field: "limit", Rule: "type", Detail: "must be of type int"},
+			},
+		},
+		{
+			name:         "Malformed body",
+			body:         `{"title":`,
+			expectedCode: "malformed_body",
+		},
+		{
+			name:         "Empty body",
+			body:         ``,
+			expectedCode: "malformed_body",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(rec)
+			ctx.Request = httptest.NewRequest("POST", "/", bytes.NewBufferString(c.body))
+
+			var in input
+			newValidationErrResponse(ctx, ctx.ShouldBindJSON(&in))
+
+			var got errResponse
+			err := json.Unmarshal(rec.Body.Bytes(), &got)
+
+			assert.NoError(t, err)
+			assert.Equal(t, rec.Code, http.StatusBadRequest)
+			assert.Equal(t, got.Code, c.expectedCode)
+			assert.Equal(t, got.Errors, c.expectedErrors)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/sirupsen/logrus"
+)
+
+// Access tokens are verified without the database, so revoking a session only
+// stops its refresh token. The cache keeps what was revoked for as long as the
+// affected access tokens could still be used.
+const (
+	revokedTokenKey        = "revoked_token:%s"
+	revokedSessionKey      = "revoked_session:%d"
+	tokensInvalidBeforeKey = "tokens_invalid_before:%d"
+)
+
+// revokeToken adds the access token to the denylist until it expires.
+func (h *Handler) revokeToken(claims dto.TokenClaimsDTO) {
+	ttl := time.Until(claims.ExpiresAt)
+	if claims.Id == "" || ttl <= 0 {
+		return
+	}
+
+	h.setRevoked(fmt.Sprintf(revokedTokenKey, claims.Id), true, ttl)
+}
+
+// revokeSession invalidates the access tokens issued to the session.
+func (h *Handler) revokeSession(sessionId int64) {
+	h.setRevoked(fmt.Sprintf(revokedSessionKey, sessionId), true, h.tokenTTL)
+}
+
+// revokeUserTokens invalidates every access token of the user issued until now.
+// Tokens carry their issue time in milliseconds, so only tokens issued within
+// a millisecond or so after are rejected as well.
+func (h *Handler) revokeUserTokens(userId int64) {
+	h.setRevoked(fmt.Sprintf(tokensInvalidBeforeKey, userId), time.Now().UnixMilli(), h.tokenTTL)
+}
+
+func (h *Handler) setRevoked(key string, value interface{}, ttl time.Duration) {
+	if err := h.cache.Set(key, value, ttl); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"key":   key,
+			"error": err,
+		}).Error("failed to revoke access tokens")
+	}
+}
+
+// tokenRevoked reports whether the access token was revoked by itself,
+// by its session or together with every token of its user.
+func (h *Handler) tokenRevoked(claims dto.TokenClaimsDTO) bool {
+	if claims.Id != "" {
+		if _, err := h.cache.Get(fmt.Sprintf(revokedTokenKey, claims.Id)); err == nil {
+			return true
+		}
+	}
+
+	if claims.SessionId != 0 {
+		if _, err := h.cache.Get(fmt.Sprintf(revokedSessionKey, claims.SessionId)); err == nil {
+			return true
+		}
+	}
+
+	before, err := h.cache.Get(fmt.Sprintf(tokensInvalidBeforeKey, claims.UserId))
+	if err != nil {
+		return false
+	}
+
+	invalidBefore, ok := before.(int64)
+	return ok && claims.IssuedAt.UnixMilli() <= invalidBefore
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/in_memory_cache/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+// userTokensRevoked reports whether every access token of the user was revoked.
+func userTokensRevoked(h *Handler, userId int64) bool {
+	_, err := h.cache.Get(fmt.Sprintf(tokensInvalidBeforeKey, userId))
+	return err == nil
+}
+
+func TestHandler_revokeToken(t *testing.T) {
+	cases := []struct {
+		name     string
+		claims   dto.TokenClaimsDTO
+		expected bool
+	}{
+		{
+			name:     "OK",
+			claims:   dto.TokenClaimsDTO{UserId: 1, Id: "jti", IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)},
+			expected: true,
+		},
+		{
+			name:   "Without jti",
+			claims: dto.TokenClaimsDTO{UserId: 1, IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)},
+		},
+		{
+			name:   "Expired",
+			claims: dto.TokenClaimsDTO{UserId: 1, Id: "jti", IssuedAt: time.Now(), ExpiresAt: time.Now().Add(-time.Minute)},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := Handler{cache: new(memory.Cache), tokenTTL: time.Hour}
+
+			h.revokeToken(c.claims)
+
+			assert.Equal(t, h.tokenRevoked(c.claims), c.expected)
+			// other tokens of the user stay valid
+			assert.False(t, h.tokenRevoked(dto.TokenClaimsDTO{UserId: 1, Id: "other", IssuedAt: time.Now()}))
+		})
+	}
+}
+
+func TestHandler_revokeUserTokens(t *testing.T) {
+	h := Handler{cache: new(memory.Cache), tokenTTL: time.Hour}
+
+	issued := time.Now().Add(-time.Millisecond)
+	h.revokeUserTokens(1)
+
+	assert.True(t, h.tokenRevoked(dto.TokenClaimsDTO{UserId: 1, IssuedAt: issued}))
+	// the issue time is truncated to milliseconds when the token is signed
+	assert.True(t, h.tokenRevoked(dto.TokenClaimsDTO{UserId: 1, IssuedAt: issued.Truncate(time.Millisecond)}))
+	// a sign in right after the revocation gets a valid token within the same second
+	assert.False(t, h.tokenRevoked(dto.TokenClaimsDTO{UserId: 1, IssuedAt: time.Now().Add(2 * time.Millisecond)}))
+	assert.False(t, h.tokenRevoked(dto.TokenClaimsDTO{UserId: 2, IssuedAt: issued}))
+}
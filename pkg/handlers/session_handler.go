@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getSessions godoc
+//
+//	@Summary		getSessions
+//	@Description	list active sessions of the user, the one of the refresh token is marked as current
+//	@Tags			sessions
+//	@Security		ApiKeyAuth
+//	@Produce		json
+//	@Success		200		{array}		dto.SessionDTO
+//	@Failure		401		{object}	errResponse
+//	@Failure		500		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/auth/sessions [get]
+func (h *Handler) getSessions(ctx *gin.Context) {
+	userId := ctx.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(ctx, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	// the refresh token is optional, without it no session is marked as current
+	rt, _ := h.refreshToken(ctx)
+
+	sessions, err := h.service.AuthService.GetSessions(userId, rt)
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+// deleteSession godoc
+//
+//	@Summary		deleteSession
+//	@Description	revoke a session of the user by id
+//	@Tags			sessions
+//	@Security		ApiKeyAuth
+//	@Produce		json
+//	@Param			id		path		integer	true	"session id"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		401		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/auth/sessions/{id} [delete]
+func (h *Handler) deleteSession(ctx *gin.Context) {
+	userId := ctx.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(ctx, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	sessionId, err := pathIdParam(ctx, "id")
+	if err != nil {
+		newErrResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.AuthService.DeleteSession(sessionId, userId); err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+	h.revokeSession(sessionId)
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	mock_services "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/mocks"
+	"github.com/DmytroBeliasnyk/in_memory_cache/memory"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_getSessions(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService, userId int64)
+
+	createdAt := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name             string
+		userId           int64
+		cookie           *http.Cookie
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:   "OK",
+			userId: 1,
+			cookie: &http.Cookie{Name: "refresh-token", Value: "token"},
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().GetSessions(userId, "token").Return([]dto.SessionDTO{{
+					Id:         2,
+					UserAgent:  "agent",
+					IP:         "127.0.0.1",
+					CreatedAt:  createdAt,
+					LastUsedAt: createdAt,
+					ExpiresAt:  createdAt,
+					Current:    true,
+				}}, nil)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `[{"id":2,"user_agent":"agent","ip":"127.0.0.1","created_at":"2025-01-01T00:00:00Z","last_used_at":"2025-01-01T00:00:00Z","expires_at":"2025-01-01T00:00:00Z","current":true}]`,
+		},
+		{
+			name:   "Without cookie",
+			userId: 1,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().GetSessions(userId, "").Return([]dto.SessionDTO{}, nil)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `[]`,
+		},
+		{
+			name:           "Unauthorized",
+			mockBehavior:   func(s *mock_services.MockAuthService, userId int64) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "Service failed",
+			userId: 1,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().GetSessions(userId, "").Return(nil, errors.New("some error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	cfg := &config.Config{
+		Cookie: config.Cookie{Name: "refresh-token"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s, c.userId)
+
+			serv := services.AbstractService{AuthService: s}
+			h := NewHandler(&serv, cfg, new(memory.Cache))
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			r.GET("/sessions", h.getSessions)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/sessions", nil)
+			if c.cookie != nil {
+				req.AddCookie(c.cookie)
+			}
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedResponse != "" {
+				assert.Equal(t, rec.Body.String(), c.expectedResponse)
+			} else {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, responseBody.Status, c.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_deleteSession(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService, sessionId, userId int64)
+
+	cases := []struct {
+		name           string
+		param          string
+		sessionId      int64
+		userId         int64
+		mockBehavior   mockBehavior
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:      "OK",
+			param:     "2",
+			sessionId: 2,
+			userId:    1,
+			mockBehavior: func(s *mock_services.MockAuthService, sessionId, userId int64) {
+				s.EXPECT().DeleteSession(sessionId, userId).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid id",
+			param:          "abc",
+			userId:         1,
+			mockBehavior:   func(s *mock_services.MockAuthService, sessionId, userId int64) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_request",
+		},
+		{
+			name:           "Unauthorized",
+			param:          "2",
+			mockBehavior:   func(s *mock_services.MockAuthService, sessionId, userId int64) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "unauthorized",
+		},
+		{
+			name:      "Not found",
+			param:     "3",
+			sessionId: 3,
+			userId:    1,
+			mockBehavior: func(s *mock_services.MockAuthService, sessionId, userId int64) {
+				s.EXPECT().DeleteSession(sessionId, userId).
+					Return(apperr.NotFound("session_not_found", "session not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "session_not_found",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s, c.sessionId, c.userId)
+
+			serv := services.AbstractService{AuthService: s}
+			h := Handler{service: &serv, cache: new(memory.Cache), tokenTTL: time.Hour}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			r.DELETE("/sessions/:id", h.deleteSession)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/sessions/"+c.param, nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			assert.Equal(t, h.tokenRevoked(dto.TokenClaimsDTO{UserId: 1, SessionId: 2, IssuedAt: time.Now()}),
+				c.expectedCode == "")
+			if c.expectedCode == "" {
+				assert.Equal(t, rec.Body.String(), `{"message":"ok"}`)
+			} else {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, responseBody.Code, c.expectedCode)
+			}
+		})
+	}
+}
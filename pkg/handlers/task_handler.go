@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// createTask godoc
+//
+//	@Summary		createTask
+//	@Description	add a task to a project, owners and editors may add tasks
+//	@Tags			tasks
+//	@Security		ApiKeyAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		integer		true	"project id"
+//	@Param			input	body		dto.TaskDTO	true	"task info"
+//	@Success		201		{integer}	integer		id
+//	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/v1/projects/{id}/tasks [post]
+func (h *Handler) createTask(c *gin.Context) {
+	userId := c.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(c, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	projectId, err := pathIdParam(c, "id")
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var input dto.TaskDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(c, err)
+		return
+	}
+
+	taskId, err := h.service.TaskService.Create(projectId, input, userId)
+	if err != nil {
+		newServiceErrResponse(c, err)
+		return
+	}
+
+	// done of the project may follow its tasks
+	h.invalidateProject(projectId, h.projectMemberIds(projectId, userId))
+
+	c.JSON(http.StatusCreated, map[string]interface{}{
+		"id": taskId,
+	})
+}
+
+// listTasks godoc
+//
+//	@Summary		listTasks
+//	@Description	get the tasks of a project in their order
+//	@Tags			tasks
+//	@Security		ApiKeyAuth
+//	@Produce		json
+//	@Param			id			path		integer	true	"project id"
+//	@Param			status		query		string	false	"filter by status"	Enums(todo, in_progress, done)
+//	@Param			assignee_id	query		integer	false	"filter by assignee"
+//	@Success		200			{array}		dto.TaskResponseDTO
+//	@Failure		400			{object}	errResponse
+//	@Failure		404			{object}	errResponse
+//	@Failure		default		{object}	errResponse
+//	@Router			/api/v1/projects/{id}/tasks [get]
+func (h *Handler) listTasks(c *gin.Context) {
+	userId := c.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(c, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	projectId, err := pathIdParam(c, "id")
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var query dto.TaskQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		newValidationErrResponse(c, err)
+		return
+	}
+
+	tasks, err := h.service.TaskService.List(projectId, userId, query)
+	if err != nil {
+		newServiceErrResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// getTask godoc
+//
+//	@Summary		getTask
+//	@Description	get a task of a project by id
+//	@Tags			tasks
+//	@Security		ApiKeyAuth
+//	@Produce		json
+//	@Param			id		path		integer	true	"project id"
+//	@Param			task_id	path		integer	true	"task id"
+//	@Success		200		{object}	dto.TaskResponseDTO
+//	@Failure		400		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/v1/projects/{id}/tasks/{task_id} [get]
+func (h *Handler) getTask(c *gin.Context) {
+	userId := c.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(c, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	projectId, taskId, err := taskParams(c)
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	task, err := h.service.TaskService.GetById(projectId, taskId, userId)
+	if err != nil {
+		newServiceErrResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// updateTask godoc
+//
+//	@Summary		updateTask
+//	@Description	update provided fields of a task, owners and editors may change tasks
+//	@Tags			tasks
+//	@Security		ApiKeyAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		integer				true	"project id"
+//	@Param			task_id	path		integer				true	"task id"
+//	@Param			input	body		dto.UpdateTaskDTO	true	"task info"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/v1/projects/{id}/tasks/{task_id} [patch]
+func (h *Handler) updateTask(c *gin.Context) {
+	userId := c.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(c, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	projectId, taskId, err := taskParams(c)
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var input dto.UpdateTaskDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(c, err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		newValidationErrResponse(c, err)
+		return
+	}
+
+	if err := h.service.TaskService.UpdateById(projectId, taskId, input, userId); err != nil {
+		newServiceErrResponse(c, err)
+		return
+	}
+
+	h.invalidateProject(projectId, h.projectMemberIds(projectId, userId))
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// deleteTask godoc
+//
+//	@Summary		deleteTask
+//	@Description	delete a task of a project, owners and editors may delete tasks
+//	@Tags			tasks
+//	@Security		ApiKeyAuth
+//	@Produce		json
+//	@Param			id		path		integer	true	"project id"
+//	@Param			task_id	path		integer	true	"task id"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		403		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/v1/projects/{id}/tasks/{task_id} [delete]
+func (h *Handler) deleteTask(c *gin.Context) {
+	userId := c.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(c, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	projectId, taskId, err := taskParams(c)
+	if err != nil {
+		newErrResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.TaskService.DeleteById(projectId, taskId, userId); err != nil {
+		newServiceErrResponse(c, err)
+		return
+	}
+
+	h.invalidateProject(projectId, h.projectMemberIds(projectId, userId))
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// taskParams reads the project id and the task id from the path.
+func taskParams(c *gin.Context) (int64, int64, error) {
+	projectId, err := pathIdParam(c, "id")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	taskId, err := pathIdParam(c, "task_id")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return projectId, taskId, nil
+}
@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	mock_handlers "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/handlers/mocks"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	mock_services "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var errTaskNotFound = apperr.NotFound("task_not_found", "task not found")
+
+func TestHandler_createTask(t *testing.T) {
+	type mockTasks func(s *mock_services.MockTaskService)
+	type mockProjects func(s *mock_services.MockProjectService)
+	type mockCache func(s *mock_handlers.MockCache)
+
+	position := 0
+
+	cases := []struct {
+		name                 string
+		body                 string
+		tasksBehavior        mockTasks
+		projectsBehavior     mockProjects
+		cacheBehavior        mockCache
+		expectedStatus       int
+		expectedResponseBody string
+		expectedCode         string
+	}{
+		{
+			name: "OK",
+			body: `{"title":"title","due_date":"2024-03-01","position":0}`,
+			tasksBehavior: func(s *mock_services.MockTaskService) {
+				s.EXPECT().Create(int64(1), dto.TaskDTO{Title: "title", DueDate: "2024-03-01", Position: &position}, int64(2)).
+					Return(int64(4), nil)
+			},
+			projectsBehavior: func(s *mock_services.MockProjectService) {
+				s.EXPECT().MemberIds(int64(1)).Return([]int64{2, 3}, nil)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache) {
+				s.EXPECT().Delete("project:1:2")
+				s.EXPECT().Delete("all2")
+				s.EXPECT().Delete("project:1:3")
+				s.EXPECT().Delete("all3")
+			},
+			expectedStatus:       http.StatusCreated,
+			expectedResponseBody: `{"id":4}`,
+		},
+		{
+			name:             "Invalid status",
+			body:             `{"title":"title","status":"blocked"}`,
+			tasksBehavior:    func(s *mock_services.MockTaskService) {},
+			projectsBehavior: func(s *mock_services.MockProjectService) {},
+			cacheBehavior:    func(s *mock_handlers.MockCache) {},
+			expectedStatus:   http.StatusBadRequest,
+			expectedCode:     "validation_failed",
+		},
+		{
+			name:             "Invalid due date",
+			body:             `{"title":"title","due_date":"01.03.2024"}`,
+			tasksBehavior:    func(s *mock_services.MockTaskService) {},
+			projectsBehavior: func(s *mock_services.MockProjectService) {},
+			cacheBehavior:    func(s *mock_handlers.MockCache) {},
+			expectedStatus:   http.StatusBadRequest,
+			expectedCode:     "validation_failed",
+		},
+		{
+			name: "Viewer",
+			body: `{"title":"title"}`,
+			tasksBehavior: func(s *mock_services.MockTaskService) {
+				s.EXPECT().Create(int64(1), dto.TaskDTO{Title: "title"}, int64(2)).Return(int64(0), errProjectForbidden)
+			},
+			projectsBehavior: func(s *mock_services.MockProjectService) {},
+			cacheBehavior:    func(s *mock_handlers.MockCache) {},
+			expectedStatus:   http.StatusForbidden,
+			expectedCode:     "project_forbidden",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tasksMock := mock_services.NewMockTaskService(ctrl)
+			c.tasksBehavior(tasksMock)
+
+			projectsMock := mock_services.NewMockProjectService(ctrl)
+			c.projectsBehavior(projectsMock)
+
+			cacheMock := mock_handlers.NewMockCache(ctrl)
+			c.cacheBehavior(cacheMock)
+
+			serv := services.AbstractService{ProjectService: projectsMock, TaskService: tasksMock}
+			h := Handler{service: &serv, cache: cacheMock}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", int64(2))
+			})
+			r.POST("/projects/:id/tasks", h.createTask)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/projects/1/tasks", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedResponseBody != "" {
+				assert.Equal(t, rec.Body.String(), c.expectedResponseBody)
+			}
+
+			if c.expectedCode != "" {
+				var responseBody errResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+				assert.Equal(t, responseBody.Code, c.expectedCode)
+			}
+		})
+	}
+}
+
+func TestHandler_listTasks(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockTaskService)
+
+	dueDate := "2024-03-01"
+
+	cases := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name:  "OK",
+			query: "?status=todo&assignee_id=3",
+			mockBehavior: func(s *mock_services.MockTaskService) {
+				s.EXPECT().List(int64(1), int64(2), dto.TaskQueryDTO{Status: dto.TaskStatusTodo, AssigneeId: 3}).
+					Return([]dto.TaskResponseDTO{{Id: 4, ProjectId: 1, Title: "title", Status: "todo", DueDate: &dueDate}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponseBody: `[{"id":4,"project_id":1,"title":"title","notes":"","status":"todo",` +
+				`"due_date":"2024-03-01","position":0,"assignee_id":null,"created_at":"0001-01-01T00:00:00Z",` +
+				`"updated_at":"0001-01-01T00:00:00Z","completed_at":null}]`,
+		},
+		{
+			name:           "Invalid status",
+			query:          "?status=blocked",
+			mockBehavior:   func(s *mock_services.MockTaskService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Not found",
+			query: "",
+			mockBehavior: func(s *mock_services.MockTaskService) {
+				s.EXPECT().List(int64(1), int64(2), dto.TaskQueryDTO{}).Return(nil, errNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			serviceMock := mock_services.NewMockTaskService(ctrl)
+			c.mockBehavior(serviceMock)
+
+			serv := services.AbstractService{TaskService: serviceMock}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", int64(2))
+			})
+			r.GET("/projects/:id/tasks", h.listTasks)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/projects/1/tasks"+c.query, nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedResponseBody != "" {
+				assert.Equal(t, rec.Body.String(), c.expectedResponseBody)
+			}
+		})
+	}
+}
+
+func TestHandler_getTask(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockTaskService)
+
+	cases := []struct {
+		name           string
+		path           string
+		mockBehavior   mockBehavior
+		expectedStatus int
+	}{
+		{
+			name: "OK",
+			path: "1/tasks/4",
+			mockBehavior: func(s *mock_services.MockTaskService) {
+				s.EXPECT().GetById(int64(1), int64(4), int64(2)).Return(dto.TaskResponseDTO{Id: 4}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid task id",
+			path:           "1/tasks/invalid",
+			mockBehavior:   func(s *mock_services.MockTaskService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Not found",
+			path: "1/tasks/4",
+			mockBehavior: func(s *mock_services.MockTaskService) {
+				s.EXPECT().GetById(int64(1), int64(4), int64(2)).Return(dto.TaskResponseDTO{}, errTaskNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			serviceMock := mock_services.NewMockTaskService(ctrl)
+			c.mockBehavior(serviceMock)
+
+			serv := services.AbstractService{TaskService: serviceMock}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", int64(2))
+			})
+			r.GET("/projects/:id/tasks/:task_id", h.getTask)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/projects/"+c.path, nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+		})
+	}
+}
+
+func TestHandler_updateTask(t *testing.T) {
+	type mockTasks func(s *mock_services.MockTaskService)
+	type mockProjects func(s *mock_services.MockProjectService)
+	type mockCache func(s *mock_handlers.MockCache)
+
+	status, dueDate := dto.TaskStatusDone, ""
+	unassigned := int64(0)
+
+	cases := []struct {
+		name             string
+		body             string
+		tasksBehavior    mockTasks
+		projectsBehavior mockProjects
+		cacheBehavior    mockCache
+		expectedStatus   int
+	}{
+		{
+			name: "OK",
+			body: `{"status":"done"}`,
+			tasksBehavior: func(s *mock_services.MockTaskService) {
+				s.EXPECT().UpdateById(int64(1), int64(4), dto.UpdateTaskDTO{Status: &status}, int64(2)).Return(nil)
+			},
+			projectsBehavior: func(s *mock_services.MockProjectService) {
+				s.EXPECT().MemberIds(int64(1)).Return([]int64{2}, nil)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache) {
+				s.EXPECT().Delete("project:1:2")
+				s.EXPECT().Delete("all2")
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Clear due date and unassign",
+			body: `{"due_date":"","assignee_id":0}`,
+			tasksBehavior: func(s *mock_services.MockTaskService) {
+				s.EXPECT().UpdateById(int64(1), int64(4), dto.UpdateTaskDTO{DueDate: &dueDate, AssigneeId: &unassigned},
+					int64(2)).Return(nil)
+			},
+			projectsBehavior: func(s *mock_services.MockProjectService) {
+				s.EXPECT().MemberIds(int64(1)).Return([]int64{2}, nil)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache) {
+				s.EXPECT().Delete("project:1:2")
+				s.EXPECT().Delete("all2")
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:             "Empty update",
+			body:             `{}`,
+			tasksBehavior:    func(s *mock_services.MockTaskService) {},
+			projectsBehavior: func(s *mock_services.MockProjectService) {},
+			cacheBehavior:    func(s *mock_handlers.MockCache) {},
+			expectedStatus:   http.StatusBadRequest,
+		},
+		{
+			name: "Not found",
+			body: `{"status":"done"}`,
+			tasksBehavior: func(s *mock_services.MockTaskService) {
+				s.EXPECT().UpdateById(int64(1), int64(4), dto.UpdateTaskDTO{Status: &status}, int64(2)).
+					Return(errTaskNotFound)
+			},
+			projectsBehavior: func(s *mock_services.MockProjectService) {},
+			cacheBehavior:    func(s *mock_handlers.MockCache) {},
+			expectedStatus:   http.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tasksMock := mock_services.NewMockTaskService(ctrl)
+			c.tasksBehavior(tasksMock)
+
+			projectsMock := mock_services.NewMockProjectService(ctrl)
+			c.projectsBehavior(projectsMock)
+
+			cacheMock := mock_handlers.NewMockCache(ctrl)
+			c.cacheBehavior(cacheMock)
+
+			serv := services.AbstractService{ProjectService: projectsMock, TaskService: tasksMock}
+			h := Handler{service: &serv, cache: cacheMock}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", int64(2))
+			})
+			r.PATCH("/projects/:id/tasks/:task_id", h.updateTask)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/projects/1/tasks/4", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+		})
+	}
+}
+
+func TestHandler_deleteTask(t *testing.T) {
+	type mockTasks func(s *mock_services.MockTaskService)
+	type mockProjects func(s *mock_services.MockProjectService)
+	type mockCache func(s *mock_handlers.MockCache)
+
+	cases := []struct {
+		name             string
+		tasksBehavior    mockTasks
+		projectsBehavior mockProjects
+		cacheBehavior    mockCache
+		expectedStatus   int
+	}{
+		{
+			name: "OK",
+			tasksBehavior: func(s *mock_services.MockTaskService) {
+				s.EXPECT().DeleteById(int64(1), int64(4), int64(2)).Return(nil)
+			},
+			projectsBehavior: func(s *mock_services.MockProjectService) {
+				s.EXPECT().MemberIds(int64(1)).Return([]int64{2}, nil)
+			},
+			cacheBehavior: func(s *mock_handlers.MockCache) {
+				s.EXPECT().Delete("project:1:2")
+				s.EXPECT().Delete("all2")
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Viewer",
+			tasksBehavior: func(s *mock_services.MockTaskService) {
+				s.EXPECT().DeleteById(int64(1), int64(4), int64(2)).Return(errProjectForbidden)
+			},
+			projectsBehavior: func(s *mock_services.MockProjectService) {},
+			cacheBehavior:    func(s *mock_handlers.MockCache) {},
+			expectedStatus:   http.StatusForbidden,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tasksMock := mock_services.NewMockTaskService(ctrl)
+			c.tasksBehavior(tasksMock)
+
+			projectsMock := mock_services.NewMockProjectService(ctrl)
+			c.projectsBehavior(projectsMock)
+
+			cacheMock := mock_handlers.NewMockCache(ctrl)
+			c.cacheBehavior(cacheMock)
+
+			serv := services.AbstractService{ProjectService: projectsMock, TaskService: tasksMock}
+			h := Handler{service: &serv, cache: cacheMock}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", int64(2))
+			})
+			r.DELETE("/projects/:id/tasks/:task_id", h.deleteTask)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/projects/1/tasks/4", nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+		})
+	}
+}
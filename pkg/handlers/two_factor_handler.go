@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// signInTwoFactor godoc
+//
+//	@Summary		signInTwoFactor
+//	@Description	exchange the challenge token of signIn and a TOTP or recovery code for tokens,
+//	@Description	the challenge can be used once, after a wrong code the user has to sign in again
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		dto.SignInTwoFactorDTO	true	"challenge token and code"
+//	@Header			200		{string}	Set-Cookie				"set new refresh token"
+//	@Success		200		{string}	string					jwt
+//	@Failure		400		{object}	errResponse
+//	@Failure		401		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/auth/sign-in/2fa [post]
+func (h *Handler) signInTwoFactor(ctx *gin.Context) {
+	var input dto.SignInTwoFactorDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	userId, err := h.service.AuthService.SignInTwoFactor(input, clientInfo(ctx))
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	h.issueTokens(ctx, userId)
+}
+
+// enrollTwoFactor godoc
+//
+//	@Summary		enrollTwoFactor
+//	@Security		ApiKeyAuth
+//	@Description	generate a TOTP secret, two-factor authentication is enabled once a first code is confirmed
+//	@Tags			me
+//	@Produce		json
+//	@Success		200		{object}	dto.TOTPEnrollmentDTO
+//	@Failure		401		{object}	errResponse
+//	@Failure		409		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/me/2fa [post]
+func (h *Handler) enrollTwoFactor(ctx *gin.Context) {
+	userId := ctx.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(ctx, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	enrollment, err := h.service.AuthService.EnrollTOTP(userId)
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollment)
+}
+
+// confirmTwoFactor godoc
+//
+//	@Summary		confirmTwoFactor
+//	@Security		ApiKeyAuth
+//	@Description	enable two-factor authentication with a first code, the recovery codes are only shown once
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		dto.ConfirmTOTPDTO	true	"TOTP code"
+//	@Success		200		{object}	dto.RecoveryCodesDTO
+//	@Failure		400		{object}	errResponse
+//	@Failure		401		{object}	errResponse
+//	@Failure		409		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/me/2fa/confirm [post]
+func (h *Handler) confirmTwoFactor(ctx *gin.Context) {
+	userId := ctx.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(ctx, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	var input dto.ConfirmTOTPDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	codes, err := h.service.AuthService.ConfirmTOTP(userId, input.Code)
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, codes)
+}
+
+// disableTwoFactor godoc
+//
+//	@Summary		disableTwoFactor
+//	@Security		ApiKeyAuth
+//	@Description	disable two-factor authentication and delete the recovery codes
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		dto.DisableTOTPDTO	true	"password confirmation"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		401		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/me/2fa [delete]
+func (h *Handler) disableTwoFactor(ctx *gin.Context) {
+	userId := ctx.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(ctx, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	var input dto.DisableTOTPDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	if err := h.service.AuthService.DisableTOTP(userId, input.Password); err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	mock_services "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/mocks"
+	"github.com/DmytroBeliasnyk/in_memory_cache/memory"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_signInTwoFactor(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService, input dto.SignInTwoFactorDTO)
+
+	input := dto.SignInTwoFactorDTO{ChallengeToken: "challenge", Code: "123456"}
+
+	cases := []struct {
+		name           string
+		body           string
+		mockBehavior   mockBehavior
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "OK",
+			body: `{"challenge_token":"challenge","code":"123456"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, input dto.SignInTwoFactorDTO) {
+				s.EXPECT().SignInTwoFactor(input, testClient).Return(int64(1), nil)
+				s.EXPECT().GenerateTokens(int64(1), testClient).Return("access", "refresh", nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Without code",
+			body:           `{"challenge_token":"challenge"}`,
+			mockBehavior:   func(s *mock_services.MockAuthService, input dto.SignInTwoFactorDTO) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "validation_failed",
+		},
+		{
+			name: "Wrong code",
+			body: `{"challenge_token":"challenge","code":"123456"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, input dto.SignInTwoFactorDTO) {
+				s.EXPECT().SignInTwoFactor(input, testClient).
+					Return(int64(0), apperr.Unauthorized("invalid_two_factor_code", "two-factor code is invalid"))
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "invalid_two_factor_code",
+		},
+	}
+
+	cfg := &config.Config{
+		Cookie: config.Cookie{Name: "refresh-token", Age: 1000, Path: "/", HttpOnly: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s, input)
+
+			serv := services.AbstractService{AuthService: s}
+			h := NewHandler(&serv, cfg, new(memory.Cache))
+
+			r := gin.New()
+			r.POST("/sign-in/2fa", h.signInTwoFactor)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/sign-in/2fa", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedCode != "" {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, responseBody.Code, c.expectedCode)
+			} else {
+				cookies := rec.Result().Cookies()
+				assert.Len(t, cookies, 2)
+				assert.Equal(t, cookies[0].Value, "refresh")
+				assert.Equal(t, rec.Body.String(), `{"Bearer":"access"}`)
+			}
+		})
+	}
+}
+
+func TestHandler_enrollTwoFactor(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService, userId int64)
+
+	cases := []struct {
+		name                 string
+		userId               int64
+		mockBehavior         mockBehavior
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name:   "OK",
+			userId: 1,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().EnrollTOTP(userId).Return(dto.TOTPEnrollmentDTO{Secret: "SECRET", URI: "otpauth://totp"}, nil)
+			},
+			expectedStatus:       http.StatusOK,
+			expectedResponseBody: `{"secret":"SECRET","otpauth_uri":"otpauth://totp"}`,
+		},
+		{
+			name:           "Unauthorized",
+			mockBehavior:   func(s *mock_services.MockAuthService, userId int64) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "Already enabled",
+			userId: 1,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().EnrollTOTP(userId).Return(dto.TOTPEnrollmentDTO{},
+					apperr.Conflict("two_factor_enabled", "two-factor authentication is already enabled"))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s, c.userId)
+
+			serv := services.AbstractService{AuthService: s}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			r.POST("/me/2fa", h.enrollTwoFactor)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/me/2fa", nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedResponseBody != "" {
+				assert.Equal(t, rec.Body.String(), c.expectedResponseBody)
+			}
+		})
+	}
+}
+
+func TestHandler_confirmTwoFactor(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService, userId int64)
+
+	cases := []struct {
+		name                 string
+		userId               int64
+		body                 string
+		mockBehavior         mockBehavior
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name:   "OK",
+			userId: 1,
+			body:   `{"code":"123456"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().ConfirmTOTP(userId, "123456").
+					Return(dto.RecoveryCodesDTO{RecoveryCodes: []string{"aaaaa-bbbbb"}}, nil)
+			},
+			expectedStatus:       http.StatusOK,
+			expectedResponseBody: `{"recovery_codes":["aaaaa-bbbbb"]}`,
+		},
+		{
+			name:           "Without code",
+			userId:         1,
+			body:           `{}`,
+			mockBehavior:   func(s *mock_services.MockAuthService, userId int64) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Wrong code",
+			userId: 1,
+			body:   `{"code":"000000"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().ConfirmTOTP(userId, "000000").Return(dto.RecoveryCodesDTO{},
+					apperr.Validation("invalid_totp_code", "code is invalid"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s, c.userId)
+
+			serv := services.AbstractService{AuthService: s}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			r.POST("/me/2fa/confirm", h.confirmTwoFactor)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/me/2fa/confirm", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedResponseBody != "" {
+				assert.Equal(t, rec.Body.String(), c.expectedResponseBody)
+			}
+		})
+	}
+}
+
+func TestHandler_disableTwoFactor(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService, userId int64)
+
+	cases := []struct {
+		name           string
+		userId         int64
+		body           string
+		mockBehavior   mockBehavior
+		expectedStatus int
+	}{
+		{
+			name:   "OK",
+			userId: 1,
+			body:   `{"password":"password"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().DisableTOTP(userId, "password").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unauthorized",
+			body:           `{"password":"password"}`,
+			mockBehavior:   func(s *mock_services.MockAuthService, userId int64) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Without password",
+			userId:         1,
+			body:           `{}`,
+			mockBehavior:   func(s *mock_services.MockAuthService, userId int64) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Service failed",
+			userId: 1,
+			body:   `{"password":"password"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().DisableTOTP(userId, "password").Return(errors.New("some error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s, c.userId)
+
+			serv := services.AbstractService{AuthService: s}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			r.DELETE("/me/2fa", h.disableTwoFactor)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/me/2fa", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+		})
+	}
+}
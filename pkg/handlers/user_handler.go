@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// getMe godoc
+//
+//	@Summary		getMe
+//	@Security		ApiKeyAuth
+//	@Description	get the profile of the user
+//	@Tags			me
+//	@Produce		json
+//	@Success		200		{object}	dto.UserResponseDTO
+//	@Failure		401		{object}	errResponse
+//	@Failure		404		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/me [get]
+func (h *Handler) getMe(ctx *gin.Context) {
+	userId := ctx.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(ctx, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	user, err := h.service.AuthService.GetUser(userId)
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// updateMe godoc
+//
+//	@Summary		updateMe
+//	@Security		ApiKeyAuth
+//	@Description	update provided fields of the profile, a new email has to be verified again
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		dto.UpdateUserDTO	true	"profile fields"
+//	@Success		200		{object}	dto.UserResponseDTO
+//	@Failure		400		{object}	errResponse
+//	@Failure		401		{object}	errResponse
+//	@Failure		409		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/me [patch]
+func (h *Handler) updateMe(ctx *gin.Context) {
+	userId := ctx.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(ctx, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	var input dto.UpdateUserDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	user, err := h.service.AuthService.UpdateUser(userId, input)
+	if err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// deleteMe godoc
+//
+//	@Summary		deleteMe
+//	@Security		ApiKeyAuth
+//	@Description	delete the account of the user with all of its projects and sessions.
+//	@Description	Accounts without a password, e.g. signed up with OpenID Connect, send no password.
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		dto.DeleteUserDTO	true	"password confirmation"
+//	@Header			200		{string}	Set-Cookie			"expired refresh token"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		401		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/api/me [delete]
+func (h *Handler) deleteMe(ctx *gin.Context) {
+	userId := ctx.GetInt64("user_id")
+	if userId == 0 {
+		newErrResponse(ctx, http.StatusUnauthorized, "user unauthorized")
+		return
+	}
+
+	var input dto.DeleteUserDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	if err := h.service.AuthService.DeleteUser(userId, input.Password); err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+	h.revokeUserTokens(userId)
+
+	h.cache.Delete(fmt.Sprintf("all%d", userId))
+
+	h.clearRefreshCookie(ctx)
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	mock_services "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/mocks"
+	"github.com/DmytroBeliasnyk/in_memory_cache/memory"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_getMe(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService, userId int64)
+
+	cases := []struct {
+		name                 string
+		userId               int64
+		mockBehavior         mockBehavior
+		expectedStatus       int
+		expectedResponseBody string
+	}{
+		{
+			name:   "OK",
+			userId: 1,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().GetUser(userId).Return(dto.UserResponseDTO{
+					Id: 1, Name: "name", Email: "aaa@bbb.ccc", Username: "username", Role: "user",
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponseBody: `{"id":1,"name":"name","email":"aaa@bbb.ccc",` +
+				`"email_verified_at":null,"username":"username","two_factor":false,"role":"user"}`,
+		},
+		{
+			name:           "Unauthorized",
+			mockBehavior:   func(s *mock_services.MockAuthService, userId int64) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "Not found",
+			userId: 1,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().GetUser(userId).Return(dto.UserResponseDTO{},
+					apperr.NotFound("user_not_found", "user not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s, c.userId)
+
+			serv := services.AbstractService{AuthService: s}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			r.GET("/me", h.getMe)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/me", nil)
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedResponseBody != "" {
+				assert.Equal(t, rec.Body.String(), c.expectedResponseBody)
+			}
+		})
+	}
+}
+
+func TestHandler_updateMe(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService, userId int64)
+
+	name := "new name"
+
+	cases := []struct {
+		name           string
+		userId         int64
+		body           string
+		mockBehavior   mockBehavior
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:   "OK",
+			userId: 1,
+			body:   `{"name":"new name"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().UpdateUser(userId, dto.UpdateUserDTO{Name: &name}).
+					Return(dto.UserResponseDTO{Id: 1, Name: name}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Empty body",
+			userId:         1,
+			body:           `{}`,
+			mockBehavior:   func(s *mock_services.MockAuthService, userId int64) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_request",
+		},
+		{
+			name:           "Invalid email",
+			userId:         1,
+			body:           `{"email":"invalid_email"}`,
+			mockBehavior:   func(s *mock_services.MockAuthService, userId int64) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "validation_failed",
+		},
+		{
+			name:   "Username taken",
+			userId: 1,
+			body:   `{"name":"new name"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().UpdateUser(userId, dto.UpdateUserDTO{Name: &name}).
+					Return(dto.UserResponseDTO{}, apperr.Conflict("username_taken", "username is already taken"))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "username_taken",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s, c.userId)
+
+			serv := services.AbstractService{AuthService: s}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			r.PATCH("/me", h.updateMe)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/me", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedCode != "" {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, responseBody.Code, c.expectedCode)
+			}
+		})
+	}
+}
+
+func TestHandler_deleteMe(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService, userId int64)
+
+	cases := []struct {
+		name           string
+		userId         int64
+		body           string
+		mockBehavior   mockBehavior
+		expectedStatus int
+	}{
+		{
+			name:   "OK",
+			userId: 1,
+			body:   `{"password":"password"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().DeleteUser(userId, "password").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Without password",
+			userId: 1,
+			body:   `{}`,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().DeleteUser(userId, "").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Invalid password",
+			userId: 1,
+			body:   `{"password":"invalid"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().DeleteUser(userId, "invalid").
+					Return(apperr.Validation("invalid_password", "password is invalid"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Service failed",
+			userId: 1,
+			body:   `{"password":"password"}`,
+			mockBehavior: func(s *mock_services.MockAuthService, userId int64) {
+				s.EXPECT().DeleteUser(userId, "password").Return(errors.New("some error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	cfg := &config.Config{
+		Auth:   config.Auth{JWT: time.Hour},
+		Cookie: config.Cookie{Name: "refresh-token", Path: "/"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s, c.userId)
+
+			serv := services.AbstractService{AuthService: s}
+			h := NewHandler(&serv, cfg, new(memory.Cache))
+
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				ctx.Set("user_id", c.userId)
+			})
+			r.DELETE("/me", h.deleteMe)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/me", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			assert.Equal(t, userTokensRevoked(h, 1), c.expectedStatus == http.StatusOK)
+			if c.expectedStatus == http.StatusOK {
+				cookies := rec.Result().Cookies()
+				assert.Len(t, cookies, 2)
+				assert.Equal(t, cookies[0].MaxAge, -1)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// verifyEmail godoc
+//
+//	@Summary		verifyEmail
+//	@Description	verify the email of the user with the token sent to it
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		dto.VerifyEmailDTO	true	"verification token"
+//	@Success		200		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/auth/verify-email [post]
+func (h *Handler) verifyEmail(ctx *gin.Context) {
+	var input dto.VerifyEmailDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	if err := h.service.AuthService.VerifyEmail(input.Token); err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// resendVerification godoc
+//
+//	@Summary		resendVerification
+//	@Description	send a new verification token, the response is the same whether the email is registered or not
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		dto.ResendVerificationDTO	true	"email"
+//	@Success		202		{object}	statusResponse
+//	@Failure		400		{object}	errResponse
+//	@Failure		default	{object}	errResponse
+//	@Router			/auth/resend-verification [post]
+func (h *Handler) resendVerification(ctx *gin.Context) {
+	var input dto.ResendVerificationDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		newValidationErrResponse(ctx, err)
+		return
+	}
+
+	if err := h.service.AuthService.ResendVerification(input.Email); err != nil {
+		newServiceErrResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, statusResponse{"ok"})
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	mock_services "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_verifyEmail(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService)
+
+	cases := []struct {
+		name           string
+		body           string
+		mockBehavior   mockBehavior
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "OK",
+			body: `{"token":"token"}`,
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().VerifyEmail("token").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Without token",
+			body:           `{}`,
+			mockBehavior:   func(s *mock_services.MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "validation_failed",
+		},
+		{
+			name: "Invalid token",
+			body: `{"token":"token"}`,
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().VerifyEmail("token").
+					Return(apperr.Validation("invalid_verification_token", "invalid verification token"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_verification_token",
+		},
+		{
+			name: "Service failed",
+			body: `{"token":"token"}`,
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().VerifyEmail("token").Return(errors.New("some error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "internal_error",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s)
+
+			serv := services.AbstractService{AuthService: s}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.POST("/verify-email", h.verifyEmail)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/verify-email", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedCode != "" {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+				assert.Equal(t, responseBody.Code, c.expectedCode)
+			} else {
+				assert.Equal(t, rec.Body.String(), `{"message":"ok"}`)
+			}
+		})
+	}
+}
+
+func TestHandler_resendVerification(t *testing.T) {
+	type mockBehavior func(s *mock_services.MockAuthService)
+
+	cases := []struct {
+		name           string
+		body           string
+		mockBehavior   mockBehavior
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "OK",
+			body: `{"email":"aaa@bbb.ccc"}`,
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().ResendVerification("aaa@bbb.ccc").Return(nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "Invalid email",
+			body:           `{"email":"invalid_email"}`,
+			mockBehavior:   func(s *mock_services.MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "validation_failed",
+		},
+		{
+			name: "Service failed",
+			body: `{"email":"aaa@bbb.ccc"}`,
+			mockBehavior: func(s *mock_services.MockAuthService) {
+				s.EXPECT().ResendVerification("aaa@bbb.ccc").Return(errors.New("some error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "internal_error",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := mock_services.NewMockAuthService(ctrl)
+			c.mockBehavior(s)
+
+			serv := services.AbstractService{AuthService: s}
+			h := Handler{service: &serv}
+
+			r := gin.New()
+			r.POST("/resend-verification", h.resendVerification)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/resend-verification", bytes.NewBufferString(c.body))
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, rec.Code, c.expectedStatus)
+			if c.expectedCode != "" {
+				var responseBody errResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+				assert.NoError(t, err)
+
+				assert.Equal(t, rec.Header().Get("Content-Type"), problemContentType)
+				assert.Equal(t, responseBody.Code, c.expectedCode)
+			} else {
+				assert.Equal(t, rec.Body.String(), `{"message":"ok"}`)
+			}
+		})
+	}
+}
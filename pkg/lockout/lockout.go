@@ -0,0 +1,57 @@
+package lockout
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/jmoiron/sqlx"
+)
+
+//go:generate mockgen -source=lockout.go -destination=mocks/mock.go
+
+// Store tracks failed sign in attempts by key, e.g. a username or a client IP.
+// Its operations are atomic, so instances sharing a store see the same counts.
+type Store interface {
+	// Get returns the attempt of the key or a zero attempt if nothing is tracked.
+	Get(key string) (Attempt, error)
+	// Fail counts a failure of the key at now. Failures are forgotten once
+	// the last one and the lock are older than the window, so the count starts over.
+	Fail(key string, now time.Time, window time.Duration) (Attempt, error)
+	// Lock blocks the key until the time.
+	Lock(key string, until time.Time) error
+	// Reset forgets the key and returns the attempt it had.
+	Reset(key string) (Attempt, error)
+	// Purge deletes the attempts whose last failure and lock ended before since
+	// and returns how many were deleted.
+	Purge(since time.Time) (int64, error)
+}
+
+type Attempt struct {
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  time.Time
+}
+
+// Locked reports whether the attempt is locked at now.
+func (a Attempt) Locked(now time.Time) bool {
+	return a.LockedUntil.After(now)
+}
+
+// stale reports whether the last failure and the lock ended before since.
+func (a Attempt) stale(since time.Time) bool {
+	return a.LastFailedAt.Before(since) && !a.LockedUntil.After(since)
+}
+
+// New returns the store selected by the driver of the config: postgres
+// shares the counts between instances, memory keeps them in the process.
+func New(cfg config.Lockout, db *sqlx.DB) (Store, error) {
+	switch cfg.Driver {
+	case "postgres":
+		return NewPostgresStore(db), nil
+	case "memory", "":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown lockout driver: %q", cfg.Driver)
+	}
+}
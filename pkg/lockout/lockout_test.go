@@ -0,0 +1,49 @@
+package lockout
+
+import (
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name        string
+		driver      string
+		expected    Store
+		expectedErr bool
+	}{
+		{
+			name:     "Postgres",
+			driver:   "postgres",
+			expected: &PostgresStore{},
+		},
+		{
+			name:     "Memory",
+			driver:   "memory",
+			expected: &MemoryStore{},
+		},
+		{
+			name:     "Memory by default",
+			expected: &MemoryStore{},
+		},
+		{
+			name:        "Unknown driver",
+			driver:      "unknown",
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := New(config.Lockout{Driver: c.driver}, nil)
+			if c.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.IsType(t, got, c.expected)
+			}
+		})
+	}
+}
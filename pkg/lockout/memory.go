@@ -0,0 +1,88 @@
+package lockout
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps attempts in the process, so every instance counts on its own.
+type MemoryStore struct {
+	mu       sync.Mutex
+	attempts map[string]Attempt
+	// swept is when stale attempts were removed last
+	swept time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{attempts: make(map[string]Attempt)}
+}
+
+func (s *MemoryStore) Get(key string) (Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.attempts[key], nil
+}
+
+func (s *MemoryStore) Fail(key string, now time.Time, window time.Duration) (Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	since := now.Add(-window)
+	if s.swept.Before(since) {
+		s.sweep(since, now)
+	}
+
+	a := s.attempts[key]
+	if a.stale(since) {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastFailedAt = now
+
+	s.attempts[key] = a
+	return a, nil
+}
+
+func (s *MemoryStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.attempts[key]
+	a.LockedUntil = until
+
+	s.attempts[key] = a
+	return nil
+}
+
+func (s *MemoryStore) Reset(key string) (Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.attempts[key]
+	delete(s.attempts, key)
+
+	return a, nil
+}
+
+func (s *MemoryStore) Purge(since time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.attempts)
+	s.sweep(since, since)
+
+	return int64(n - len(s.attempts)), nil
+}
+
+// sweep removes the attempts that neither count nor lock anymore,
+// so keys of one-off clients do not pile up.
+func (s *MemoryStore) sweep(since, now time.Time) {
+	for key, a := range s.attempts {
+		if a.stale(since) && !a.Locked(now) {
+			delete(s.attempts, key)
+		}
+	}
+
+	s.swept = now
+}
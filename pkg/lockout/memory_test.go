@@ -0,0 +1,79 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Now()
+
+	a, err := s.Fail("key", now, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, a.Failures, 1)
+
+	a, err = s.Fail("key", now.Add(30*time.Second), time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, a.Failures, 2)
+
+	assert.NoError(t, s.Lock("key", now.Add(10*time.Minute)))
+
+	a, err = s.Get("key")
+	assert.NoError(t, err)
+	assert.True(t, a.Locked(now))
+	assert.False(t, a.Locked(now.Add(10*time.Minute)))
+
+	// the lock keeps the failures beyond the window
+	a, err = s.Fail("key", now.Add(10*time.Minute), time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, a.Failures, 3)
+
+	a, err = s.Reset("key")
+	assert.NoError(t, err)
+	assert.Equal(t, a.Failures, 3)
+
+	a, err = s.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, a, Attempt{})
+}
+
+func TestMemoryStore_window(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Now()
+
+	_, err := s.Fail("key", now, time.Minute)
+	assert.NoError(t, err)
+	_, err = s.Fail("stale", now, time.Minute)
+	assert.NoError(t, err)
+
+	a, err := s.Fail("key", now.Add(2*time.Minute), time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, a.Failures, 1)
+
+	// stale keys are swept instead of piling up
+	assert.NotContains(t, s.attempts, "stale")
+}
+
+func TestMemoryStore_Purge(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Now()
+
+	_, err := s.Fail("recent", now, time.Minute)
+	assert.NoError(t, err)
+	_, err = s.Fail("stale", now.Add(-time.Hour), time.Minute)
+	assert.NoError(t, err)
+	_, err = s.Fail("locked", now.Add(-time.Hour), time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Lock("locked", now.Add(time.Hour)))
+
+	got, err := s.Purge(now.Add(-time.Minute))
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, int64(1))
+	assert.NotContains(t, s.attempts, "stale")
+	assert.Contains(t, s.attempts, "locked")
+	assert.Contains(t, s.attempts, "recent")
+}
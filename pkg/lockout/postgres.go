@@ -0,0 +1,81 @@
+package lockout
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresStore keeps attempts in the sign_in_attempts table shared by every instance.
+type PostgresStore struct {
+	db *sqlx.DB
+}
+
+func NewPostgresStore(db *sqlx.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+const attemptColumns = "failures, last_failed_at, locked_until"
+
+// attemptRow maps a row of sign_in_attempts, locked_until is NULL until the key is locked.
+type attemptRow struct {
+	Failures     int          `db:"failures"`
+	LastFailedAt time.Time    `db:"last_failed_at"`
+	LockedUntil  sql.NullTime `db:"locked_until"`
+}
+
+func (r attemptRow) toAttempt() Attempt {
+	return Attempt{
+		Failures:     r.Failures,
+		LastFailedAt: r.LastFailedAt,
+		LockedUntil:  r.LockedUntil.Time,
+	}
+}
+
+func (s *PostgresStore) Get(key string) (Attempt, error) {
+	var row attemptRow
+	err := s.db.Get(&row, "SELECT "+attemptColumns+" FROM sign_in_attempts WHERE key=$1", key)
+	if err == sql.ErrNoRows {
+		return Attempt{}, nil
+	}
+
+	return row.toAttempt(), err
+}
+
+func (s *PostgresStore) Fail(key string, now time.Time, window time.Duration) (Attempt, error) {
+	var row attemptRow
+	err := s.db.Get(&row, "INSERT INTO sign_in_attempts (key, failures, last_failed_at) VALUES ($1, 1, $2) "+
+		"ON CONFLICT (key) DO UPDATE SET failures = CASE "+
+		"WHEN GREATEST(sign_in_attempts.last_failed_at, sign_in_attempts.locked_until) < $3 THEN 1 "+
+		"ELSE sign_in_attempts.failures + 1 END, last_failed_at=$2 RETURNING "+attemptColumns,
+		key, now, now.Add(-window))
+
+	return row.toAttempt(), err
+}
+
+func (s *PostgresStore) Lock(key string, until time.Time) error {
+	_, err := s.db.Exec("UPDATE sign_in_attempts SET locked_until=$1 WHERE key=$2", until, key)
+
+	return err
+}
+
+func (s *PostgresStore) Reset(key string) (Attempt, error) {
+	var row attemptRow
+	err := s.db.Get(&row, "DELETE FROM sign_in_attempts WHERE key=$1 RETURNING "+attemptColumns, key)
+	if err == sql.ErrNoRows {
+		return Attempt{}, nil
+	}
+
+	return row.toAttempt(), err
+}
+
+func (s *PostgresStore) Purge(since time.Time) (int64, error) {
+	res, err := s.db.Exec("DELETE FROM sign_in_attempts WHERE last_failed_at<$1 "+
+		"AND (locked_until IS NULL OR locked_until<=$1)", since)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,173 @@
+package lockout
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestPostgresStore_Get(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	s := NewPostgresStore(db)
+
+	failedAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	lockedUntil := failedAt.Add(time.Minute)
+
+	cases := []struct {
+		name        string
+		mock        func()
+		expected    Attempt
+		expectedErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"failures", "last_failed_at", "locked_until"}).
+					AddRow(5, failedAt, lockedUntil)
+				mock.ExpectQuery(`SELECT failures, last_failed_at, locked_until FROM sign_in_attempts WHERE key=\$1`).
+					WithArgs("ip:192.0.2.1").
+					WillReturnRows(rows)
+			},
+			expected: Attempt{Failures: 5, LastFailedAt: failedAt, LockedUntil: lockedUntil},
+		},
+		{
+			name: "Not locked",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"failures", "last_failed_at", "locked_until"}).
+					AddRow(1, failedAt, nil)
+				mock.ExpectQuery("SELECT (.+) FROM sign_in_attempts").
+					WithArgs("ip:192.0.2.1").
+					WillReturnRows(rows)
+			},
+			expected: Attempt{Failures: 1, LastFailedAt: failedAt},
+		},
+		{
+			name: "Not tracked",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM sign_in_attempts").
+					WithArgs("ip:192.0.2.1").
+					WillReturnRows(sqlmock.NewRows([]string{"failures", "last_failed_at", "locked_until"}))
+			},
+		},
+		{
+			name: "Failed",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM sign_in_attempts").
+					WithArgs("ip:192.0.2.1").
+					WillReturnError(errors.New("some error"))
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			got, err := s.Get("ip:192.0.2.1")
+			if c.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, got, c.expected)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPostgresStore_Fail(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	s := NewPostgresStore(db)
+	now := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+
+	rows := sqlmock.NewRows([]string{"failures", "last_failed_at", "locked_until"}).AddRow(2, now, nil)
+	mock.ExpectQuery(`INSERT INTO sign_in_attempts \(key, failures, last_failed_at\) VALUES \(\$1, 1, \$2\) `+
+		`ON CONFLICT \(key\) DO UPDATE SET failures = CASE `+
+		`WHEN GREATEST\(sign_in_attempts.last_failed_at, sign_in_attempts.locked_until\) < \$3 THEN 1 `+
+		`ELSE sign_in_attempts.failures \+ 1 END, last_failed_at=\$2 RETURNING failures, last_failed_at, locked_until`).
+		WithArgs("username:username", now, now.Add(-time.Minute)).
+		WillReturnRows(rows)
+
+	got, err := s.Fail("username:username", now, time.Minute)
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, Attempt{Failures: 2, LastFailedAt: now})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStore_Lock(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	s := NewPostgresStore(db)
+	until := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+
+	mock.ExpectExec(`UPDATE sign_in_attempts SET locked_until=\$1 WHERE key=\$2`).
+		WithArgs(until, "username:username").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, s.Lock("username:username", until))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStore_Reset(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	s := NewPostgresStore(db)
+	failedAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+
+	rows := sqlmock.NewRows([]string{"failures", "last_failed_at", "locked_until"}).AddRow(5, failedAt, failedAt)
+	mock.ExpectQuery(`DELETE FROM sign_in_attempts WHERE key=\$1 RETURNING failures, last_failed_at, locked_until`).
+		WithArgs("username:username").
+		WillReturnRows(rows)
+	mock.ExpectQuery("DELETE FROM sign_in_attempts").
+		WithArgs("username:username").
+		WillReturnRows(sqlmock.NewRows([]string{"failures", "last_failed_at", "locked_until"}))
+
+	got, err := s.Reset("username:username")
+	assert.NoError(t, err)
+	assert.Equal(t, got, Attempt{Failures: 5, LastFailedAt: failedAt, LockedUntil: failedAt})
+
+	got, err = s.Reset("username:username")
+	assert.NoError(t, err)
+	assert.Equal(t, got, Attempt{})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStore_Purge(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	s := NewPostgresStore(db)
+	since := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+
+	mock.ExpectExec(`DELETE FROM sign_in_attempts WHERE last_failed_at<\$1 AND \(locked_until IS NULL OR locked_until<=\$1\)`).
+		WithArgs(since).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	got, err := s.Purge(since)
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, int64(3))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
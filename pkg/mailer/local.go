@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FileMailer appends every message to a file instead of sending it,
+// so that links can be followed during local development.
+type FileMailer struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileMailer(path string) *FileMailer {
+	return &FileMailer{path: path}
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC1123Z), msg.To, msg.Subject, msg.Body)
+
+	return err
+}
+
+// LogMailer writes every message to the log instead of sending it. It has a logger
+// of its own, so messages are shown whatever the level of the application log is.
+type LogMailer struct {
+	log *logrus.Logger
+}
+
+func NewLogMailer() *LogMailer {
+	log := logrus.New()
+	log.SetOutput(os.Stdout)
+
+	return &LogMailer{log: log}
+}
+
+func (m *LogMailer) Send(msg Message) error {
+	m.log.WithFields(logrus.Fields{
+		"to":      msg.To,
+		"subject": msg.Subject,
+	}).Info(msg.Body)
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package mailer
+
+import (
+	"fmt"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+)
+
+//go:generate mockgen -source=mailer.go -destination=mocks/mock.go
+
+// Mailer delivers emails to users, e.g. verification and password reset links.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// New returns the mailer selected by the driver of the config:
+// smtp for production, file or log for local development.
+func New(cfg config.Mailer) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg), nil
+	case "file":
+		return NewFileMailer(cfg.File), nil
+	case "log", "":
+		return NewLogMailer(), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer driver: %q", cfg.Driver)
+	}
+}
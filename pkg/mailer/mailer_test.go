@@ -0,0 +1,92 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name        string
+		driver      string
+		expected    Mailer
+		expectedErr bool
+	}{
+		{
+			name:     "SMTP",
+			driver:   "smtp",
+			expected: &SMTPMailer{},
+		},
+		{
+			name:     "File",
+			driver:   "file",
+			expected: &FileMailer{},
+		},
+		{
+			name:     "Log",
+			driver:   "log",
+			expected: &LogMailer{},
+		},
+		{
+			name:     "Log by default",
+			expected: &LogMailer{},
+		},
+		{
+			name:        "Unknown driver",
+			driver:      "unknown",
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := New(config.Mailer{Driver: c.driver})
+			if c.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.IsType(t, got, c.expected)
+			}
+		})
+	}
+}
+
+func TestFileMailer_Send(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	m := NewFileMailer(path)
+
+	assert.NoError(t, m.Send(Message{To: "first@gmail.com", Subject: "First", Body: "first body"}))
+	assert.NoError(t, m.Send(Message{To: "second@gmail.com", Subject: "Second", Body: "second body"}))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	got := string(data)
+	assert.Contains(t, got, "To: first@gmail.com\nSubject: First\n\nfirst body\n")
+	assert.Contains(t, got, "To: second@gmail.com\nSubject: Second\n\nsecond body\n")
+	assert.Less(t, strings.Index(got, "first body"), strings.Index(got, "second body"))
+}
+
+func TestSMTPMailer_compose(t *testing.T) {
+	m := NewSMTPMailer(config.Mailer{
+		From: "no-reply@app.com",
+		SMTP: config.SMTP{Host: "smtp.app.com", Port: "587"},
+	})
+
+	got := string(m.compose(Message{To: "email@gmail.com", Subject: "Subject", Body: "line 1\nline 2"}))
+
+	assert.Equal(t, m.addr, "smtp.app.com:587")
+	assert.Nil(t, m.auth)
+	assert.Equal(t, got, "From: no-reply@app.com\r\n"+
+		"To: email@gmail.com\r\n"+
+		"Subject: Subject\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=UTF-8\r\n"+
+		"\r\n"+
+		"line 1\r\nline 2")
+}
@@ -0,0 +1,65 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJWK(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	cases := []struct {
+		name     string
+		jwk      dto.JWKDTO
+		expected interface{}
+		isErr    bool
+	}{
+		{
+			name:     "EC",
+			jwk:      dto.JWKDTO{Kty: "EC", Crv: "P-256", X: b64(ecKey.X.Bytes()), Y: b64(ecKey.Y.Bytes())},
+			expected: &ecKey.PublicKey,
+		},
+		{
+			name:     "Ed25519",
+			jwk:      dto.JWKDTO{Kty: "OKP", Crv: "Ed25519", X: b64(edKey)},
+			expected: edKey,
+		},
+		{
+			name:  "Point not on the curve",
+			jwk:   dto.JWKDTO{Kty: "EC", Crv: "P-256", X: b64(ecKey.X.Bytes()), Y: b64(ecKey.X.Bytes())},
+			isErr: true,
+		},
+		{
+			name:  "Unsupported type",
+			jwk:   dto.JWKDTO{Kty: "oct"},
+			isErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := parseJWK(c.jwk)
+
+			if c.isErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, got, c.expected)
+		})
+	}
+}
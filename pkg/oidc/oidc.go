@@ -0,0 +1,313 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keysRefreshInterval limits how often the keys are fetched again for an unknown kid.
+const keysRefreshInterval = time.Minute
+
+var (
+	// ErrInvalidGrant is returned if the provider rejects the authorization code.
+	ErrInvalidGrant = errors.New("oidc: authorization code rejected")
+	// ErrInvalidIDToken is returned if the ID token can not be verified.
+	ErrInvalidIDToken = errors.New("oidc: invalid ID token")
+)
+
+var defaultScopes = []string{"openid", "email", "profile"}
+
+// Claims are the claims of a verified ID token, the user is identified by the subject.
+type Claims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Nonce             string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// Provider is an OpenID Connect provider users sign in with the authorization
+// code flow and PKCE. Its endpoints and keys are fetched on first use.
+type Provider struct {
+	cfg    config.OIDCProvider
+	client *http.Client
+
+	mu        sync.Mutex
+	endpoints *endpoints
+	keys      map[string]crypto.PublicKey
+	keysAt    time.Time
+}
+
+type endpoints struct {
+	Issuer   string `json:"issuer"`
+	AuthURL  string `json:"authorization_endpoint"`
+	TokenURL string `json:"token_endpoint"`
+	JWKSURL  string `json:"jwks_uri"`
+}
+
+// New returns the providers of the config by name.
+func New(cfg config.OIDC, client *http.Client) (map[string]*Provider, error) {
+	providers := make(map[string]*Provider, len(cfg.Providers))
+	for name, p := range cfg.Providers {
+		if p.Issuer == "" || p.ClientId == "" || p.RedirectURL == "" {
+			return nil, fmt.Errorf("oidc provider %q needs an issuer, a client_id and a redirect_url", name)
+		}
+
+		providers[name] = NewProvider(p, client)
+	}
+
+	return providers, nil
+}
+
+func NewProvider(cfg config.OIDCProvider, client *http.Client) *Provider {
+	return &Provider{cfg: cfg, client: client}
+}
+
+// Challenge returns the S256 code challenge of the PKCE code verifier.
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns the address of the provider the user signs in at.
+// The provider redirects back to the redirect URL with a code and the state.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	e, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(e.AuthURL)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := p.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientId)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", Challenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// Exchange redeems the authorization code and returns the claims of the ID token,
+// which has to be signed by the provider for the client and carry the nonce.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (Claims, error) {
+	e, err := p.discover(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientId},
+		"code_verifier": {verifier},
+	}
+	if p.cfg.Secret.ClientSecret != "" {
+		form.Set("client_secret", p.cfg.Secret.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return Claims{}, err
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		IdToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&body); err != nil {
+		return Claims{}, fmt.Errorf("oidc: token endpoint responded %d: %w", res.StatusCode, err)
+	}
+
+	switch {
+	case res.StatusCode == http.StatusBadRequest || res.StatusCode == http.StatusUnauthorized:
+		return Claims{}, fmt.Errorf("%w: %s", ErrInvalidGrant, body.Error)
+	case res.StatusCode != http.StatusOK:
+		return Claims{}, fmt.Errorf("oidc: token endpoint responded %d", res.StatusCode)
+	case body.IdToken == "":
+		return Claims{}, fmt.Errorf("%w: missing in the token response", ErrInvalidIDToken)
+	}
+
+	return p.verify(ctx, e, body.IdToken, nonce)
+}
+
+func (p *Provider) verify(ctx context.Context, e endpoints, raw, nonce string) (Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, e, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(e.Issuer),
+		jwt.WithAudience(p.cfg.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute))
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Subject == "" {
+		return Claims{}, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return Claims{}, fmt.Errorf("%w: nonce does not match", ErrInvalidIDToken)
+	}
+
+	return claims, nil
+}
+
+// discover returns the endpoints of the config, the missing ones are read
+// from the discovery document of the issuer.
+func (p *Provider) discover(ctx context.Context) (endpoints, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.endpoints != nil {
+		return *p.endpoints, nil
+	}
+
+	e := endpoints{
+		Issuer:   p.cfg.Issuer,
+		AuthURL:  p.cfg.AuthURL,
+		TokenURL: p.cfg.TokenURL,
+		JWKSURL:  p.cfg.JWKSURL,
+	}
+
+	if e.AuthURL == "" || e.TokenURL == "" || e.JWKSURL == "" {
+		var doc endpoints
+		wellKnown := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+		if err := p.getJSON(ctx, wellKnown, &doc); err != nil {
+			return endpoints{}, err
+		}
+
+		if doc.Issuer != p.cfg.Issuer {
+			return endpoints{}, fmt.Errorf("oidc: discovery issuer %q does not match %q", doc.Issuer, p.cfg.Issuer)
+		}
+
+		if e.AuthURL == "" {
+			e.AuthURL = doc.AuthURL
+		}
+		if e.TokenURL == "" {
+			e.TokenURL = doc.TokenURL
+		}
+		if e.JWKSURL == "" {
+			e.JWKSURL = doc.JWKSURL
+		}
+	}
+
+	p.endpoints = &e
+	return e, nil
+}
+
+// key returns the verification key of the kid. An unknown kid fetches the keys
+// again, at most every keysRefreshInterval, to pick up a key rotation.
+func (p *Provider) key(ctx context.Context, e endpoints, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	if p.keys != nil && time.Since(p.keysAt) < keysRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key: %q", kid)
+	}
+
+	var set dto.JWKSetDTO
+	if err := p.getJSON(ctx, e.JWKSURL, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := parseJWK(k)
+		if err != nil {
+			// keys of unsupported types do not prevent using the others
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	p.keys, p.keysAt = keys, time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown signing key: %q", kid)
+}
+
+// lookupKey finds the key of the kid, a token without kid is accepted if there is a single key.
+func (p *Provider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: %s responded %d", url, res.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(v)
+}
@@ -0,0 +1,100 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRedirectURL = "http://localhost:8000/auth/oidc/stub/callback"
+
+func TestProvider_Exchange(t *testing.T) {
+	cases := []struct {
+		name          string
+		verifier      string
+		nonce         string
+		replay        bool
+		expectedErr   error
+		expectedEmail string
+	}{
+		{
+			name:          "OK",
+			expectedEmail: "email@gmail.com",
+		},
+		{
+			name:        "Wrong verifier",
+			verifier:    "other_verifier",
+			expectedErr: ErrInvalidGrant,
+		},
+		{
+			name:        "Wrong nonce",
+			nonce:       "other_nonce",
+			expectedErr: ErrInvalidIDToken,
+		},
+		{
+			name:        "Replayed code",
+			replay:      true,
+			expectedErr: ErrInvalidGrant,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := oidctest.NewServer(t)
+			server.SetUser(oidctest.User{Subject: "subject", Email: "email@gmail.com", EmailVerified: true})
+
+			p := NewProvider(server.Provider(testRedirectURL), http.DefaultClient)
+			ctx := context.Background()
+
+			authURL, err := p.AuthCodeURL(ctx, "state", "nonce", "verifier")
+			assert.NoError(t, err)
+
+			callback := server.Authorize(t, authURL)
+			assert.Equal(t, callback.Get("state"), "state")
+
+			verifier, nonce := "verifier", "nonce"
+			if c.verifier != "" {
+				verifier = c.verifier
+			}
+			if c.nonce != "" {
+				nonce = c.nonce
+			}
+
+			if c.replay {
+				_, err := p.Exchange(ctx, callback.Get("code"), verifier, nonce)
+				assert.NoError(t, err)
+			}
+
+			got, err := p.Exchange(ctx, callback.Get("code"), verifier, nonce)
+
+			assert.True(t, errors.Is(err, c.expectedErr), "error %v is not %v", err, c.expectedErr)
+			if c.expectedErr == nil {
+				assert.Equal(t, got.Subject, "subject")
+				assert.Equal(t, got.Email, c.expectedEmail)
+				assert.True(t, got.EmailVerified)
+			}
+		})
+	}
+}
+
+func TestProvider_discover(t *testing.T) {
+	server := oidctest.NewServer(t)
+
+	cfg := server.Provider(testRedirectURL)
+	cfg.Issuer = server.URL + "/"
+
+	_, err := NewProvider(cfg, http.DefaultClient).AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+
+	assert.ErrorContains(t, err, "does not match")
+}
+
+func TestChallenge(t *testing.T) {
+	// RFC 7636, appendix B
+	got := Challenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+
+	assert.Equal(t, got, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM")
+}
@@ -15,26 +15,82 @@ type ProjectRepository interface {
 	Create(p *entity.Project) (int64, error)
 	GetById(id int64, userId int64) (entity.Project, error)
 	GetAll(userId int64) ([]entity.Project, error)
+	List(userId int64, query dto.ProjectQueryDTO) ([]entity.Project, error)
 	UpdateById(id int64, input dto.UpdateProjectDTO, userId int64) error
 	DeleteById(id int64, userId int64) error
+	GetMemberRole(projectId int64, userId int64) (string, error)
+	GetMembers(projectId int64, userId int64) ([]entity.ProjectMember, error)
+	AddMember(projectId int64, userId int64, input dto.AddProjectMemberDTO) (entity.ProjectMember, error)
+	UpdateMemberRole(projectId int64, userId int64, memberId int64, role string) error
+	RemoveMember(projectId int64, userId int64, memberId int64) error
+	TransferOwnership(projectId int64, userId int64, newOwnerId int64) error
+	MemberIds(projectId int64) ([]int64, error)
+}
+
+type TaskRepository interface {
+	Create(t *entity.Task, userId int64) (int64, error)
+	GetById(projectId int64, id int64, userId int64) (entity.Task, error)
+	List(projectId int64, userId int64, query dto.TaskQueryDTO) ([]entity.Task, error)
+	UpdateById(projectId int64, id int64, input dto.UpdateTaskDTO, userId int64) error
+	DeleteById(projectId int64, id int64, userId int64) error
 }
 
 type AuthRepository interface {
 	SignUp(u *entity.User) (int64, error)
-	SignIn(username, passwordHash string) (int64, error)
-	CreateRefreshToken(userId int64, token string, expiresAt time.Time) error
-	FindRefreshToken(token string) (int64, time.Time, error)
-	DeleteRefreshToken(token string) error
+	GetById(userId int64) (entity.User, error)
+	GetByUsername(username string) (entity.User, error)
+	GetByEmail(email string) (entity.User, error)
+	MarkEmailVerified(userId int64, email string) error
+	UpdateUser(userId int64, input dto.UpdateUserDTO) error
+	DeleteUser(userId int64) error
+	UpdatePasswordHash(userId int64, passwordHash string) error
+	ChangePassword(userId int64, passwordHash string) error
+	CreateRefreshToken(s *entity.Session, tokenHash string) error
+	FindRefreshToken(tokenHash string) (entity.Session, error)
+	RotateRefreshToken(parentId int64, s *entity.Session, tokenHash string) error
+	DeleteRefreshToken(tokenHash string) error
+	DeleteTokenFamily(familyId int64) error
+	DeleteRefreshTokens(userId int64) error
+	GetSessions(userId int64) ([]entity.Session, error)
+	DeleteSession(id int64, userId int64) error
+	CreateUserToken(t *entity.UserToken, tokenHash string) error
+	ConsumeUserToken(tokenHash, purpose string) (entity.UserToken, error)
+	DeleteUserTokens(userId int64, purpose string) error
+	SetTOTPSecret(userId int64, secret string) error
+	EnableTOTP(userId int64, step int64, codeHashes []string) error
+	DisableTOTP(userId int64) error
+	UseTOTPStep(userId int64, step int64) error
+	ConsumeRecoveryCode(userId int64, codeHash string) error
+	CreateAccessToken(t *entity.AccessToken, tokenHash string) error
+	GetAccessTokens(userId int64) ([]entity.AccessToken, error)
+	DeleteAccessToken(id int64, userId int64) error
+	UseAccessToken(tokenHash string) (entity.AccessToken, error)
+	ListUsers(query dto.UserQueryDTO) ([]entity.User, error)
+	DisableUser(userId int64) error
+	EnableUser(userId int64) error
+	GetByIdentity(provider, subject string) (entity.User, error)
+	LinkIdentity(i *entity.UserIdentity) error
+	SignUpWithIdentity(u *entity.User, i *entity.UserIdentity) (int64, error)
+}
+
+type AuditRepository interface {
+	CreateEvent(e *entity.AuditEvent) error
+	ListEvents(query dto.AuditQueryDTO) ([]entity.AuditEvent, error)
+	DeleteEventsBefore(t time.Time) (int64, error)
 }
 
 type AbstractRepository struct {
 	ProjectRepository
+	TaskRepository
 	AuthRepository
+	AuditRepository
 }
 
 func NewRepository(db *sqlx.DB) *AbstractRepository {
 	return &AbstractRepository{
 		ProjectRepository: implrepo.NewProjectRepository(db),
+		TaskRepository:    implrepo.NewTaskRepository(db),
 		AuthRepository:    implrepo.NewUserRepository(db),
+		AuditRepository:   implrepo.NewAuditRepository(db),
 	}
 }
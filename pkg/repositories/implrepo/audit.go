@@ -0,0 +1,92 @@
+package implrepo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditRepositoryImpl struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) *AuditRepositoryImpl {
+	return &AuditRepositoryImpl{db}
+}
+
+func (repo *AuditRepositoryImpl) CreateEvent(e *entity.AuditEvent) error {
+	return repo.db.QueryRow(`INSERT INTO audit_events (event, user_id, ip, user_agent, request_id, detail)
+								VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+		e.Event, e.UserId, e.IP, e.UserAgent, e.RequestId, e.Detail).Scan(&e.Id, &e.CreatedAt)
+}
+
+// ListEvents returns events from the newest, starting before query.BeforeId if it is set.
+func (repo *AuditRepositoryImpl) ListEvents(query dto.AuditQueryDTO) ([]entity.AuditEvent, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	argId := 1
+
+	if query.BeforeId != 0 {
+		conditions = append(conditions, fmt.Sprintf("id<$%d", argId))
+		args = append(args, query.BeforeId)
+		argId++
+	}
+
+	if query.UserId != 0 {
+		conditions = append(conditions, fmt.Sprintf("user_id=$%d", argId))
+		args = append(args, query.UserId)
+		argId++
+	}
+
+	if query.Event != "" {
+		conditions = append(conditions, fmt.Sprintf("event=$%d", argId))
+		args = append(args, query.Event)
+		argId++
+	}
+
+	if query.IP != "" {
+		conditions = append(conditions, fmt.Sprintf("ip=$%d", argId))
+		args = append(args, query.IP)
+		argId++
+	}
+
+	if query.From != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at>=$%d", argId))
+		args = append(args, *query.From)
+		argId++
+	}
+
+	if query.To != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at<$%d", argId))
+		args = append(args, *query.To)
+		argId++
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, query.Limit)
+
+	var events []entity.AuditEvent
+	q := fmt.Sprintf("SELECT * FROM audit_events%s ORDER BY id DESC LIMIT $%d", where, argId)
+	if err := repo.db.Select(&events, q, args...); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// DeleteEventsBefore deletes the events created before the time and returns how many were deleted.
+func (repo *AuditRepositoryImpl) DeleteEventsBefore(t time.Time) (int64, error) {
+	res, err := repo.db.Exec("DELETE FROM audit_events WHERE created_at<$1", t)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
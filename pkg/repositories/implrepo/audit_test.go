@@ -0,0 +1,124 @@
+package implrepo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestAuditRepository_CreateEvent(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewAuditRepository(db)
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	userId := int64(1)
+
+	mock.ExpectQuery("INSERT INTO audit_events (.+) RETURNING id, created_at").
+		WithArgs(entity.AuditSignIn, 1, "192.0.2.1", "curl", "request", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, createdAt))
+
+	event := entity.AuditEvent{
+		Event:     entity.AuditSignIn,
+		UserId:    &userId,
+		IP:        "192.0.2.1",
+		UserAgent: "curl",
+		RequestId: "request",
+	}
+	err = repo.CreateEvent(&event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, event.Id, int64(3))
+	assert.Equal(t, event.CreatedAt, createdAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditRepository_ListEvents(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewAuditRepository(db)
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	from := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC)
+	userId := int64(1)
+
+	cases := []struct {
+		name     string
+		query    dto.AuditQueryDTO
+		mock     func()
+		expected []entity.AuditEvent
+	}{
+		{
+			name:  "OK",
+			query: dto.AuditQueryDTO{Limit: 51},
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "event", "user_id", "ip", "user_agent", "request_id", "detail", "created_at"}).
+					AddRow(2, "sign_in_failed", nil, "192.0.2.1", "curl", "request", "unknown user", createdAt).
+					AddRow(1, "sign_in", 1, "192.0.2.1", "curl", "", "", createdAt)
+				mock.ExpectQuery(`SELECT \* FROM audit_events ORDER BY id DESC LIMIT \$1`).
+					WithArgs(51).
+					WillReturnRows(rows)
+			},
+			expected: []entity.AuditEvent{
+				{Id: 2, Event: "sign_in_failed", IP: "192.0.2.1", UserAgent: "curl", RequestId: "request",
+					Detail: "unknown user", CreatedAt: createdAt},
+				{Id: 1, Event: "sign_in", UserId: &userId, IP: "192.0.2.1", UserAgent: "curl", CreatedAt: createdAt},
+			},
+		},
+		{
+			name: "Filters and cursor",
+			query: dto.AuditQueryDTO{Limit: 6, UserId: 1, Event: "sign_in", IP: "192.0.2.1",
+				From: &from, To: &to, BeforeId: 10},
+			mock: func() {
+				mock.ExpectQuery(`SELECT \* FROM audit_events WHERE id<\$1 AND user_id=\$2 AND event=\$3 `+
+					`AND ip=\$4 AND created_at>=\$5 AND created_at<\$6 ORDER BY id DESC LIMIT \$7`).
+					WithArgs(10, 1, "sign_in", "192.0.2.1", from, to, 6).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			got, err := repo.ListEvents(c.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, got, c.expected)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuditRepository_DeleteEventsBefore(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewAuditRepository(db)
+
+	before := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+
+	mock.ExpectExec("DELETE FROM audit_events WHERE created_at<(.+)").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	got, err := repo.DeleteEventsBefore(before)
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, int64(4))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,8 +1,11 @@
 package implrepo
 
 import (
-	"time"
+	"database/sql"
+	"fmt"
+	"strings"
 
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
 	"github.com/jmoiron/sqlx"
 )
@@ -26,42 +29,502 @@ func (repo *UserRepositoryImpl) SignUp(u *entity.User) (int64, error) {
 	return id, nil
 }
 
-func (repo *UserRepositoryImpl) SignIn(username, passwordHash string) (int64, error) {
-	var id int64
-	if err := repo.db.QueryRow("SELECT id FROM users WHERE username=$1 AND password_hash=$2",
-		username, passwordHash).Scan(&id); err != nil {
-		return 0, err
+func (repo *UserRepositoryImpl) GetById(userId int64) (entity.User, error) {
+	var user entity.User
+	if err := repo.db.Get(&user, "SELECT * FROM users WHERE id=$1", userId); err != nil {
+		return entity.User{}, err
 	}
 
-	return id, nil
+	return user, nil
+}
+
+// GetByUsername matches case-insensitively, like the unique index of the column.
+func (repo *UserRepositoryImpl) GetByUsername(username string) (entity.User, error) {
+	var user entity.User
+	if err := repo.db.Get(&user, "SELECT * FROM users WHERE lower(username)=lower($1)", username); err != nil {
+		return entity.User{}, err
+	}
+
+	return user, nil
+}
+
+// GetByEmail matches case-insensitively, like the unique index of the column.
+func (repo *UserRepositoryImpl) GetByEmail(email string) (entity.User, error) {
+	var user entity.User
+	if err := repo.db.Get(&user, "SELECT * FROM users WHERE lower(email)=lower($1)", email); err != nil {
+		return entity.User{}, err
+	}
+
+	return user, nil
+}
+
+// MarkEmailVerified verifies the email of the user unless it was changed
+// after the token was issued, in which case sql.ErrNoRows is returned.
+func (repo *UserRepositoryImpl) MarkEmailVerified(userId int64, email string) error {
+	res, err := repo.db.Exec(`UPDATE users SET email_verified_at=COALESCE(email_verified_at, now())
+								WHERE id=$1 AND email=$2`, userId, email)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+// UpdateUser updates the provided fields of the user. A new email
+// has to be verified again.
+func (repo *UserRepositoryImpl) UpdateUser(userId int64, input dto.UpdateUserDTO) error {
+	setValues := make([]string, 0)
+	args := make([]interface{}, 0)
+	argId := 1
+
+	if input.Name != nil {
+		setValues = append(setValues, fmt.Sprintf("name=$%d", argId))
+		args = append(args, *input.Name)
+		argId++
+	}
+
+	if input.Email != nil {
+		setValues = append(setValues, fmt.Sprintf("email=$%d", argId),
+			fmt.Sprintf("email_verified_at=CASE WHEN email=$%d THEN email_verified_at END", argId))
+		args = append(args, *input.Email)
+		argId++
+	}
+
+	if input.Username != nil {
+		setValues = append(setValues, fmt.Sprintf("username=$%d", argId))
+		args = append(args, *input.Username)
+		argId++
+	}
+
+	values := strings.Join(setValues, ", ")
+	args = append(args, userId)
+
+	query := fmt.Sprintf("UPDATE users SET %s WHERE id=$%d", values, argId)
+	res, err := repo.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+// DeleteUser deletes the user, the projects and tokens of the user
+// are deleted by the cascading foreign keys.
+func (repo *UserRepositoryImpl) DeleteUser(userId int64) error {
+	res, err := repo.db.Exec("DELETE FROM users WHERE id=$1", userId)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+func (repo *UserRepositoryImpl) UpdatePasswordHash(userId int64, passwordHash string) error {
+	if _, err := repo.db.Exec("UPDATE users SET password_hash=$1 WHERE id=$2", passwordHash, userId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ChangePassword replaces the password hash of the user and, in the same transaction,
+// revokes every refresh token and pending password reset token of the user.
+func (repo *UserRepositoryImpl) ChangePassword(userId int64, passwordHash string) error {
+	tx, err := repo.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("UPDATE users SET password_hash=$1 WHERE id=$2", passwordHash, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM tokens WHERE user_id=$1", userId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM user_tokens WHERE user_id=$1 AND purpose=$2",
+		userId, entity.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateRefreshToken stores the first token of a session and sets its id and family id.
+func (repo *UserRepositoryImpl) CreateRefreshToken(s *entity.Session, tokenHash string) error {
+	return repo.db.QueryRowx(`INSERT INTO tokens (user_id, token_hash, user_agent, ip, expires_at)
+								VALUES ($1, $2, $3, $4, $5) RETURNING id, family_id`,
+		s.UserId, tokenHash, s.UserAgent, s.IP, s.ExpiresAt).Scan(&s.Id, &s.FamilyId)
+}
+
+const sessionColumns = "id, user_id, family_id, parent_id, rotated_at, user_agent, ip, created_at, last_used_at, expires_at"
+
+func (repo *UserRepositoryImpl) FindRefreshToken(tokenHash string) (entity.Session, error) {
+	var session entity.Session
+	if err := repo.db.Get(&session, "SELECT "+sessionColumns+" FROM tokens WHERE token_hash=$1", tokenHash); err != nil {
+		return entity.Session{}, err
+	}
+
+	return session, nil
+}
+
+// RotateRefreshToken marks the token parentId as rotated and stores its successor s.
+// The parent is locked for the duration of the transaction, so of two concurrent
+// rotations of the same token only the first succeeds and the second one gets
+// sql.ErrNoRows, as it does when the parent was already rotated or deleted.
+func (repo *UserRepositoryImpl) RotateRefreshToken(parentId int64, s *entity.Session, tokenHash string) error {
+	tx, err := repo.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var rotatedAt sql.NullTime
+	if err := tx.QueryRow("SELECT rotated_at FROM tokens WHERE id=$1 FOR UPDATE", parentId).
+		Scan(&rotatedAt); err != nil {
+		return err
+	}
+
+	if rotatedAt.Valid {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec("UPDATE tokens SET rotated_at=now() WHERE id=$1", parentId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO tokens (user_id, token_hash, family_id, parent_id, user_agent, ip, created_at, expires_at)
+							VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		s.UserId, tokenHash, s.FamilyId, parentId, s.UserAgent, s.IP, s.CreatedAt, s.ExpiresAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (repo *UserRepositoryImpl) CreateRefreshToken(userId int64, token string, expiresAt time.Time) error {
-	if _, err := repo.db.Exec("INSERT INTO tokens (user_id, token, expires_at) VALUES ($1, $2, $3)",
-		userId, token, expiresAt); err != nil {
+// DeleteRefreshToken deletes the token together with the rest of its family,
+// so that none of its rotated ancestors can be presented again.
+func (repo *UserRepositoryImpl) DeleteRefreshToken(tokenHash string) error {
+	if _, err := repo.db.Exec(`DELETE FROM tokens
+								WHERE family_id=(SELECT family_id FROM tokens WHERE token_hash=$1)`, tokenHash); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (repo *UserRepositoryImpl) FindRefreshToken(token string) (int64, time.Time, error) {
-	var res struct {
-		Id        int64     `db:"user_id"`
-		ExpiresAt time.Time `db:"expires_at"`
+func (repo *UserRepositoryImpl) DeleteTokenFamily(familyId int64) error {
+	if _, err := repo.db.Exec("DELETE FROM tokens WHERE family_id=$1", familyId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *UserRepositoryImpl) DeleteRefreshTokens(userId int64) error {
+	if _, err := repo.db.Exec("DELETE FROM tokens WHERE user_id=$1", userId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *UserRepositoryImpl) GetSessions(userId int64) ([]entity.Session, error) {
+	var sessions []entity.Session
+	if err := repo.db.Select(&sessions, "SELECT "+sessionColumns+` FROM tokens
+										WHERE user_id=$1 AND rotated_at IS NULL AND expires_at > now()
+										ORDER BY last_used_at DESC, id DESC`, userId); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// DeleteSession deletes every token of the family id of the user.
+func (repo *UserRepositoryImpl) DeleteSession(id int64, userId int64) error {
+	res, err := repo.db.Exec("DELETE FROM tokens WHERE family_id=$1 AND user_id=$2", id, userId)
+	if err != nil {
+		return err
 	}
 
-	if err := repo.db.Get(&res, "SELECT user_id, expires_at FROM tokens WHERE token=$1", token); err != nil {
-		return 0, time.Time{}, err
+	return requireAffected(res)
+}
+
+func (repo *UserRepositoryImpl) CreateUserToken(t *entity.UserToken, tokenHash string) error {
+	if _, err := repo.db.Exec(`INSERT INTO user_tokens (user_id, purpose, token_hash, email, expires_at)
+								VALUES ($1, $2, $3, $4, $5)`,
+		t.UserId, t.Purpose, tokenHash, t.Email, t.ExpiresAt); err != nil {
+		return err
 	}
 
-	return res.Id, res.ExpiresAt, nil
+	return nil
+}
+
+// ConsumeUserToken deletes the token and returns it, so that each token can be used once
+// even by concurrent requests.
+func (repo *UserRepositoryImpl) ConsumeUserToken(tokenHash, purpose string) (entity.UserToken, error) {
+	var token entity.UserToken
+	if err := repo.db.Get(&token, `DELETE FROM user_tokens WHERE token_hash=$1 AND purpose=$2
+									RETURNING id, user_id, purpose, email, created_at, expires_at`,
+		tokenHash, purpose); err != nil {
+		return entity.UserToken{}, err
+	}
+
+	return token, nil
 }
 
-func (repo *UserRepositoryImpl) DeleteRefreshToken(token string) error {
-	if _, err := repo.db.Exec("DELETE FROM tokens WHERE token=$1", token); err != nil {
+func (repo *UserRepositoryImpl) DeleteUserTokens(userId int64, purpose string) error {
+	if _, err := repo.db.Exec("DELETE FROM user_tokens WHERE user_id=$1 AND purpose=$2", userId, purpose); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// SetTOTPSecret starts a TOTP enrollment, sql.ErrNoRows is returned
+// if TOTP is already enabled.
+func (repo *UserRepositoryImpl) SetTOTPSecret(userId int64, secret string) error {
+	res, err := repo.db.Exec("UPDATE users SET totp_secret=$1 WHERE id=$2 AND totp_enabled_at IS NULL",
+		secret, userId)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+// EnableTOTP confirms the enrollment and replaces the recovery codes of the user.
+func (repo *UserRepositoryImpl) EnableTOTP(userId int64, step int64, codeHashes []string) error {
+	tx, err := repo.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE users SET totp_enabled_at=now(), totp_last_step=$1
+							WHERE id=$2 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL`, step, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id=$1", userId); err != nil {
+		return err
+	}
+
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec("INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)",
+			userId, hash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (repo *UserRepositoryImpl) DisableTOTP(userId int64) error {
+	tx, err := repo.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE users SET totp_secret=NULL, totp_enabled_at=NULL, totp_last_step=NULL
+							WHERE id=$1`, userId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id=$1", userId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep records the time step of an accepted code. sql.ErrNoRows is returned
+// if a code of the same or a later step was already accepted, e.g. by a concurrent request.
+func (repo *UserRepositoryImpl) UseTOTPStep(userId int64, step int64) error {
+	res, err := repo.db.Exec(`UPDATE users SET totp_last_step=$1
+								WHERE id=$2 AND (totp_last_step IS NULL OR totp_last_step < $1)`, step, userId)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+// ConsumeRecoveryCode deletes the code, sql.ErrNoRows is returned if the user has no such code.
+func (repo *UserRepositoryImpl) ConsumeRecoveryCode(userId int64, codeHash string) error {
+	res, err := repo.db.Exec("DELETE FROM recovery_codes WHERE user_id=$1 AND code_hash=$2", userId, codeHash)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+const accessTokenColumns = "id, user_id, name, scopes, created_at, expires_at, last_used_at"
+
+// CreateAccessToken stores the token and sets its id and creation time.
+func (repo *UserRepositoryImpl) CreateAccessToken(t *entity.AccessToken, tokenHash string) error {
+	return repo.db.QueryRowx(`INSERT INTO access_tokens (user_id, name, token_hash, scopes, expires_at)
+								VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		t.UserId, t.Name, tokenHash, t.Scopes, t.ExpiresAt).Scan(&t.Id, &t.CreatedAt)
+}
+
+func (repo *UserRepositoryImpl) GetAccessTokens(userId int64) ([]entity.AccessToken, error) {
+	var tokens []entity.AccessToken
+	if err := repo.db.Select(&tokens, "SELECT "+accessTokenColumns+` FROM access_tokens
+										WHERE user_id=$1 ORDER BY id DESC`, userId); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (repo *UserRepositoryImpl) DeleteAccessToken(id int64, userId int64) error {
+	res, err := repo.db.Exec("DELETE FROM access_tokens WHERE id=$1 AND user_id=$2", id, userId)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+// UseAccessToken records the use of the token and returns it. sql.ErrNoRows
+// is returned if there is no such token, it is expired or the user is disabled.
+func (repo *UserRepositoryImpl) UseAccessToken(tokenHash string) (entity.AccessToken, error) {
+	var token entity.AccessToken
+	if err := repo.db.Get(&token, `UPDATE access_tokens t SET last_used_at=now() FROM users u
+									WHERE t.token_hash=$1 AND t.expires_at > now()
+									AND u.id=t.user_id AND u.disabled_at IS NULL
+									RETURNING t.id, t.user_id, t.name, t.scopes, t.created_at, t.expires_at, t.last_used_at`,
+		tokenHash); err != nil {
+		return entity.AccessToken{}, err
+	}
+
+	return token, nil
+}
+
+// ListUsers returns users ordered by id, starting after query.AfterId.
+func (repo *UserRepositoryImpl) ListUsers(query dto.UserQueryDTO) ([]entity.User, error) {
+	conditions := []string{"id>$1"}
+	args := []interface{}{query.AfterId}
+	argId := 2
+
+	if query.Search != "" {
+		conditions = append(conditions,
+			fmt.Sprintf("(name ILIKE $%d OR email ILIKE $%d OR username ILIKE $%d)", argId, argId, argId))
+		args = append(args, "%"+escapeLike(query.Search)+"%")
+		argId++
+	}
+
+	if query.Role != "" {
+		conditions = append(conditions, fmt.Sprintf("role=$%d", argId))
+		args = append(args, query.Role)
+		argId++
+	}
+
+	if query.Disabled != nil {
+		if *query.Disabled {
+			conditions = append(conditions, "disabled_at IS NOT NULL")
+		} else {
+			conditions = append(conditions, "disabled_at IS NULL")
+		}
+	}
+
+	args = append(args, query.Limit)
+
+	var users []entity.User
+	q := fmt.Sprintf("SELECT * FROM users WHERE %s ORDER BY id LIMIT $%d", strings.Join(conditions, " AND "), argId)
+	if err := repo.db.Select(&users, q, args...); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// DisableUser marks the user as disabled and revokes the refresh tokens of the user.
+func (repo *UserRepositoryImpl) DisableUser(userId int64) error {
+	tx, err := repo.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("UPDATE users SET disabled_at=COALESCE(disabled_at, now()) WHERE id=$1", userId)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM tokens WHERE user_id=$1", userId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repo *UserRepositoryImpl) EnableUser(userId int64) error {
+	res, err := repo.db.Exec("UPDATE users SET disabled_at=NULL WHERE id=$1", userId)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+// GetByIdentity returns the user the subject of the provider is linked to.
+func (repo *UserRepositoryImpl) GetByIdentity(provider, subject string) (entity.User, error) {
+	var user entity.User
+	if err := repo.db.Get(&user, `SELECT u.* FROM users u JOIN user_identities i ON i.user_id=u.id
+									WHERE i.provider=$1 AND i.subject=$2`, provider, subject); err != nil {
+		return entity.User{}, err
+	}
+
+	return user, nil
+}
+
+func (repo *UserRepositoryImpl) LinkIdentity(i *entity.UserIdentity) error {
+	return repo.db.QueryRow(`INSERT INTO user_identities (user_id, provider, subject, email)
+								VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+		i.UserId, i.Provider, i.Subject, i.Email).Scan(&i.Id, &i.CreatedAt)
+}
+
+// SignUpWithIdentity creates the user together with the identity it signed up with.
+func (repo *UserRepositoryImpl) SignUpWithIdentity(u *entity.User, i *entity.UserIdentity) (int64, error) {
+	tx, err := repo.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	if err := tx.QueryRow(`INSERT INTO users (name, email, username, password_hash, email_verified_at)
+							VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		u.Name, u.Email, u.Username, u.PasswordHash, u.EmailVerifiedAt).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	i.UserId = id
+	if err := tx.QueryRow(`INSERT INTO user_identities (user_id, provider, subject, email)
+							VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+		i.UserId, i.Provider, i.Subject, i.Email).Scan(&i.Id, &i.CreatedAt); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
@@ -1,10 +1,13 @@
 package implrepo
 
 import (
+	"database/sql"
 	"database/sql/driver"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
 	"github.com/stretchr/testify/assert"
 	sqlmock "github.com/zhashkevych/go-sqlxmock"
@@ -71,7 +74,7 @@ func TestUserRepository_SignUp(t *testing.T) {
 	}
 }
 
-func TestUserRepository_SignIn(t *testing.T) {
+func TestUserRepository_GetByUsername(t *testing.T) {
 	db, mock, err := sqlmock.Newx()
 
 	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
@@ -79,38 +82,38 @@ func TestUserRepository_SignIn(t *testing.T) {
 
 	repo := NewUserRepository(db)
 
-	type args struct {
-		username, passwordHash string
-	}
-
 	cases := []struct {
 		name        string
-		input       args
+		input       string
 		mock        func()
-		expected    int64
+		expected    entity.User
 		expectedErr bool
 	}{
 		{
-			name: "OK",
-			input: args{
-				username:     "username",
-				passwordHash: "password",
-			},
+			name:  "OK",
+			input: "UserName",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
-				mock.ExpectQuery("SELECT id FROM users").
-					WithArgs("username", "password").
+				rows := sqlmock.NewRows([]string{"id", "name", "email", "username", "password_hash"}).
+					AddRow(1, "name", "aaa@bbb.ccc", "username", "hash")
+				mock.ExpectQuery(`SELECT \* FROM users WHERE lower\(username\)=lower\(\$1\)`).
+					WithArgs("UserName").
 					WillReturnRows(rows)
 			},
-			expected: 1,
+			expected: entity.User{
+				Id:           1,
+				Name:         "name",
+				Email:        "aaa@bbb.ccc",
+				Username:     "username",
+				PasswordHash: "hash",
+			},
 		},
 		{
-			name:  "emty fields",
-			input: args{},
+			name:  "Not found",
+			input: "not found",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id"})
-				mock.ExpectQuery("SELECT id FROM users").
-					WithArgs("", "").
+				rows := sqlmock.NewRows([]string{"id", "name", "email", "username", "password_hash"})
+				mock.ExpectQuery("SELECT (.+) FROM users").
+					WithArgs("not found").
 					WillReturnRows(rows)
 			},
 			expectedErr: true,
@@ -121,7 +124,7 @@ func TestUserRepository_SignIn(t *testing.T) {
 		t.Run(c.name, func(t *testing.T) {
 			c.mock()
 
-			got, err := repo.SignIn(c.input.username, c.input.passwordHash)
+			got, err := repo.GetByUsername(c.input)
 			if c.expectedErr {
 				assert.Error(t, err)
 			} else {
@@ -134,6 +137,165 @@ func TestUserRepository_SignIn(t *testing.T) {
 	}
 }
 
+func TestUserRepository_GetById(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "email", "username", "password_hash"}).
+		AddRow(1, "name", "aaa@bbb.ccc", "username", "hash")
+
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE id=(.+)").
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	got, err := repo.GetById(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, entity.User{
+		Id:           1,
+		Name:         "name",
+		Email:        "aaa@bbb.ccc",
+		Username:     "username",
+		PasswordHash: "hash",
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_UpdateUser(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	name, email := "new name", "new@bbb.ccc"
+	input := dto.UpdateUserDTO{
+		Name:  &name,
+		Email: &email,
+	}
+	mock.ExpectExec(`UPDATE users SET name=\$1, email=\$2, email_verified_at=CASE WHEN email=\$2 THEN email_verified_at END WHERE id=\$3`).
+		WithArgs(name, email, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.UpdateUser(1, input)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectExec("UPDATE users SET").
+		WithArgs(name, email, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.UpdateUser(2, input)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_DeleteUser(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	mock.ExpectExec("DELETE FROM users WHERE id=(.+)").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.DeleteUser(1)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectExec("DELETE FROM users WHERE id=(.+)").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.DeleteUser(2)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_UpdatePasswordHash(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	mock.ExpectExec("UPDATE users SET password_hash").
+		WithArgs("hash", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.UpdatePasswordHash(1, "hash")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_ChangePassword(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	cases := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE users SET password_hash=(.+) WHERE id=(.+)").
+					WithArgs("hash", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM tokens WHERE user_id=(.+)").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM user_tokens WHERE user_id=(.+) AND purpose=(.+)").
+					WithArgs(1, entity.TokenPurposePasswordReset).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE users SET password_hash=(.+) WHERE id=(.+)").
+					WithArgs("hash", 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			err := repo.ChangePassword(1, "hash")
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestUserRepository_CreateRefreshToken(t *testing.T) {
 	db, mock, err := sqlmock.Newx()
 
@@ -142,16 +304,27 @@ func TestUserRepository_CreateRefreshToken(t *testing.T) {
 
 	repo := NewUserRepository(db)
 
-	mock.ExpectExec("INSERT INTO tokens").
-		WithArgs(1, "token", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)).
-		WillReturnResult(driver.ResultNoRows)
+	expiresAt := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)
+	mock.ExpectQuery("INSERT INTO tokens (.+) RETURNING id, family_id").
+		WithArgs(1, "token_hash", "agent", "127.0.0.1", expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "family_id"}).AddRow(3, 2))
 
-	got := repo.CreateRefreshToken(1, "token", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local))
+	session := entity.Session{
+		UserId:    1,
+		UserAgent: "agent",
+		IP:        "127.0.0.1",
+		ExpiresAt: expiresAt,
+	}
+	got := repo.CreateRefreshToken(&session, "token_hash")
 
 	assert.NoError(t, got)
+	assert.Equal(t, session.Id, int64(3))
+	assert.Equal(t, session.FamilyId, int64(2))
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+var sessionRows = strings.Split(sessionColumns, ", ")
+
 func TestUserRepository_FindRefreshToken(t *testing.T) {
 	db, mock, err := sqlmock.Newx()
 
@@ -160,38 +333,43 @@ func TestUserRepository_FindRefreshToken(t *testing.T) {
 
 	repo := NewUserRepository(db)
 
-	type result struct {
-		userId    int64
-		expiresAt time.Time
-	}
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	expiresAt := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)
+
 	cases := []struct {
 		name        string
 		arg         string
 		mock        func()
-		expected    result
+		expected    entity.Session
 		expectedErr bool
 	}{
 		{
 			name: "OK",
-			arg:  "token",
+			arg:  "token_hash",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"user_id", "expires_at"}).
-					AddRow(1, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local))
+				rows := sqlmock.NewRows(sessionRows).
+					AddRow(2, 1, 1, nil, nil, "agent", "127.0.0.1", createdAt, createdAt, expiresAt)
 
-				mock.ExpectQuery("SELECT (.+) FROM tokens").
-					WithArgs("token").
+				mock.ExpectQuery("SELECT (.+) FROM tokens WHERE token_hash=(.+)").
+					WithArgs("token_hash").
 					WillReturnRows(rows)
 			},
-			expected: result{
-				userId:    1,
-				expiresAt: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local),
+			expected: entity.Session{
+				Id:         2,
+				UserId:     1,
+				FamilyId:   1,
+				UserAgent:  "agent",
+				IP:         "127.0.0.1",
+				CreatedAt:  createdAt,
+				LastUsedAt: createdAt,
+				ExpiresAt:  expiresAt,
 			},
 		},
 		{
 			name: "Not found",
 			arg:  "not found",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"user_id", "expires_at"})
+				rows := sqlmock.NewRows(sessionRows)
 
 				mock.ExpectQuery("SELECT (.+) FROM tokens").
 					WithArgs("not found").
@@ -205,19 +383,111 @@ func TestUserRepository_FindRefreshToken(t *testing.T) {
 		t.Run(c.name, func(t *testing.T) {
 			c.mock()
 
-			userId, expiresAt, err := repo.FindRefreshToken(c.arg)
+			got, err := repo.FindRefreshToken(c.arg)
 			if c.expectedErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, userId, c.expected.userId)
-				assert.Equal(t, expiresAt, c.expected.expiresAt)
+				assert.Equal(t, got, c.expected)
 			}
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
 
+func TestUserRepository_RotateRefreshToken(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	expiresAt := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)
+	session := &entity.Session{
+		UserId:    1,
+		FamilyId:  3,
+		UserAgent: "agent",
+		IP:        "127.0.0.1",
+		CreatedAt: createdAt,
+		ExpiresAt: expiresAt,
+	}
+
+	cases := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT rotated_at FROM tokens WHERE id=(.+) FOR UPDATE").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"rotated_at"}).AddRow(nil))
+				mock.ExpectExec("UPDATE tokens SET rotated_at=now()").
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO tokens").
+					WithArgs(1, "new_token_hash", 3, 2, "agent", "127.0.0.1", createdAt, expiresAt).
+					WillReturnResult(sqlmock.NewResult(4, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Already rotated",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT rotated_at FROM tokens WHERE id=(.+) FOR UPDATE").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"rotated_at"}).AddRow(createdAt))
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrNoRows,
+		},
+		{
+			name: "Deleted",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT rotated_at FROM tokens WHERE id=(.+) FOR UPDATE").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"rotated_at"}))
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrNoRows,
+		},
+		{
+			name: "Failed to insert",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT rotated_at FROM tokens WHERE id=(.+) FOR UPDATE").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"rotated_at"}).AddRow(nil))
+				mock.ExpectExec("UPDATE tokens SET rotated_at=now()").
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO tokens").
+					WithArgs(1, "new_token_hash", 3, 2, "agent", "127.0.0.1", createdAt, expiresAt).
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			err := repo.RotateRefreshToken(2, session, "new_token_hash")
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestUserRepository_DeleteRefreshToken(t *testing.T) {
 	db, mock, err := sqlmock.Newx()
 
@@ -226,12 +496,848 @@ func TestUserRepository_DeleteRefreshToken(t *testing.T) {
 
 	repo := NewUserRepository(db)
 
-	mock.ExpectExec("DELETE FROM tokens").
-		WithArgs("token").
+	mock.ExpectExec("DELETE FROM tokens WHERE family_id=\\(SELECT family_id FROM tokens WHERE token_hash=(.+)\\)").
+		WithArgs("token_hash").
 		WillReturnResult(driver.ResultNoRows)
 
-	err = repo.DeleteRefreshToken("token")
+	err = repo.DeleteRefreshToken("token_hash")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_DeleteTokenFamily(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	mock.ExpectExec("DELETE FROM tokens WHERE family_id=(.+)").
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err = repo.DeleteTokenFamily(3)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_DeleteRefreshTokens(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	mock.ExpectExec("DELETE FROM tokens WHERE user_id=(.+)").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err = repo.DeleteRefreshTokens(1)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_GetSessions(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	expiresAt := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)
+
+	parentId := int64(2)
+	rows := sqlmock.NewRows(sessionRows).
+		AddRow(4, 1, 3, 2, nil, "agent", "127.0.0.1", createdAt, createdAt, expiresAt).
+		AddRow(1, 1, 1, nil, nil, "other agent", "10.0.0.1", createdAt, createdAt, expiresAt)
+
+	mock.ExpectQuery("SELECT (.+) FROM tokens WHERE user_id=(.+) AND rotated_at IS NULL AND expires_at > now()").
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	got, err := repo.GetSessions(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, []entity.Session{
+		{Id: 4, UserId: 1, FamilyId: 3, ParentId: &parentId, UserAgent: "agent", IP: "127.0.0.1",
+			CreatedAt: createdAt, LastUsedAt: createdAt, ExpiresAt: expiresAt},
+		{Id: 1, UserId: 1, FamilyId: 1, UserAgent: "other agent", IP: "10.0.0.1",
+			CreatedAt: createdAt, LastUsedAt: createdAt, ExpiresAt: expiresAt},
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_DeleteSession(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	cases := []struct {
+		name        string
+		affected    int64
+		expectedErr error
+	}{
+		{
+			name:     "OK",
+			affected: 1,
+		},
+		{
+			name:        "Not found",
+			affected:    0,
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mock.ExpectExec("DELETE FROM tokens WHERE family_id=(.+) AND user_id=(.+)").
+				WithArgs(2, 1).
+				WillReturnResult(sqlmock.NewResult(0, c.affected))
+
+			err := repo.DeleteSession(2, 1)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_GetByEmail(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	verifiedAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	rows := sqlmock.NewRows([]string{"id", "name", "email", "username", "password_hash", "email_verified_at"}).
+		AddRow(1, "name", "aaa@bbb.ccc", "username", "hash", verifiedAt)
+
+	mock.ExpectQuery(`SELECT \* FROM users WHERE lower\(email\)=lower\(\$1\)`).
+		WithArgs("AAA@bbb.ccc").
+		WillReturnRows(rows)
+
+	got, err := repo.GetByEmail("AAA@bbb.ccc")
 
 	assert.NoError(t, err)
+	assert.Equal(t, got, entity.User{
+		Id:              1,
+		Name:            "name",
+		Email:           "aaa@bbb.ccc",
+		Username:        "username",
+		PasswordHash:    "hash",
+		EmailVerifiedAt: &verifiedAt,
+	})
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUserRepository_MarkEmailVerified(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	cases := []struct {
+		name        string
+		affected    int64
+		expectedErr error
+	}{
+		{
+			name:     "OK",
+			affected: 1,
+		},
+		{
+			name:        "Email changed",
+			affected:    0,
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mock.ExpectExec("UPDATE users SET email_verified_at=(.+) WHERE id=(.+) AND email=(.+)").
+				WithArgs(1, "aaa@bbb.ccc").
+				WillReturnResult(sqlmock.NewResult(0, c.affected))
+
+			err := repo.MarkEmailVerified(1, "aaa@bbb.ccc")
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_CreateUserToken(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	expiresAt := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)
+
+	mock.ExpectExec("INSERT INTO user_tokens").
+		WithArgs(1, entity.TokenPurposeEmailVerification, "hash", "aaa@bbb.ccc", expiresAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.CreateUserToken(&entity.UserToken{
+		UserId:    1,
+		Purpose:   entity.TokenPurposeEmailVerification,
+		Email:     "aaa@bbb.ccc",
+		ExpiresAt: expiresAt,
+	}, "hash")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_ConsumeUserToken(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	expiresAt := time.Date(2024, time.December, 2, 0, 0, 0, 0, time.Local)
+	columns := []string{"id", "user_id", "purpose", "email", "created_at", "expires_at"}
+
+	cases := []struct {
+		name        string
+		mock        func()
+		expected    entity.UserToken
+		expectedErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(3, 1, entity.TokenPurposeEmailVerification, "aaa@bbb.ccc", createdAt, expiresAt)
+				mock.ExpectQuery("DELETE FROM user_tokens WHERE token_hash=(.+) AND purpose=(.+) RETURNING").
+					WithArgs("hash", entity.TokenPurposeEmailVerification).
+					WillReturnRows(rows)
+			},
+			expected: entity.UserToken{
+				Id:        3,
+				UserId:    1,
+				Purpose:   entity.TokenPurposeEmailVerification,
+				Email:     "aaa@bbb.ccc",
+				CreatedAt: createdAt,
+				ExpiresAt: expiresAt,
+			},
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectQuery("DELETE FROM user_tokens WHERE token_hash=(.+) AND purpose=(.+) RETURNING").
+					WithArgs("hash", entity.TokenPurposeEmailVerification).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			got, err := repo.ConsumeUserToken("hash", entity.TokenPurposeEmailVerification)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_DeleteUserTokens(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	mock.ExpectExec("DELETE FROM user_tokens WHERE user_id=(.+) AND purpose=(.+)").
+		WithArgs(1, entity.TokenPurposeEmailVerification).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err = repo.DeleteUserTokens(1, entity.TokenPurposeEmailVerification)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_SetTOTPSecret(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	cases := []struct {
+		name        string
+		affected    int64
+		expectedErr error
+	}{
+		{
+			name:     "OK",
+			affected: 1,
+		},
+		{
+			name:        "Already enabled",
+			affected:    0,
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mock.ExpectExec("UPDATE users SET totp_secret=(.+) WHERE id=(.+) AND totp_enabled_at IS NULL").
+				WithArgs("secret", 1).
+				WillReturnResult(sqlmock.NewResult(0, c.affected))
+
+			err := repo.SetTOTPSecret(1, "secret")
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_EnableTOTP(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	cases := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE users SET totp_enabled_at=now\\(\\), totp_last_step=(.+) WHERE id=(.+)").
+					WithArgs(42, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM recovery_codes WHERE user_id=(.+)").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO recovery_codes").
+					WithArgs(1, "hash1").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO recovery_codes").
+					WithArgs(1, "hash2").
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Not enrolled",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE users SET totp_enabled_at=now\\(\\), totp_last_step=(.+) WHERE id=(.+)").
+					WithArgs(42, 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			err := repo.EnableTOTP(1, 42, []string{"hash1", "hash2"})
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_DisableTOTP(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET totp_secret=NULL, totp_enabled_at=NULL, totp_last_step=NULL WHERE id=(.+)").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM recovery_codes WHERE user_id=(.+)").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectCommit()
+
+	err = repo.DisableTOTP(1)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_UseTOTPStep(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	cases := []struct {
+		name        string
+		affected    int64
+		expectedErr error
+	}{
+		{
+			name:     "OK",
+			affected: 1,
+		},
+		{
+			name:        "Step already used",
+			affected:    0,
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mock.ExpectExec("UPDATE users SET totp_last_step=(.+) WHERE id=(.+) AND").
+				WithArgs(42, 1).
+				WillReturnResult(sqlmock.NewResult(0, c.affected))
+
+			err := repo.UseTOTPStep(1, 42)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_ConsumeRecoveryCode(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	mock.ExpectExec("DELETE FROM recovery_codes WHERE user_id=(.+) AND code_hash=(.+)").
+		WithArgs(1, "hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.ConsumeRecoveryCode(1, "hash")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectExec("DELETE FROM recovery_codes WHERE user_id=(.+) AND code_hash=(.+)").
+		WithArgs(1, "used").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.ConsumeRecoveryCode(1, "used")
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+var accessTokenRows = []string{"id", "user_id", "name", "scopes", "created_at", "expires_at", "last_used_at"}
+
+func TestUserRepository_CreateAccessToken(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	expiresAt := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)
+
+	mock.ExpectQuery("INSERT INTO access_tokens (.+) RETURNING id, created_at").
+		WithArgs(1, "ci", "hash", "{\"projects:read\"}", expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, createdAt))
+
+	token := entity.AccessToken{UserId: 1, Name: "ci", Scopes: []string{"projects:read"}, ExpiresAt: expiresAt}
+	err = repo.CreateAccessToken(&token, "hash")
+
+	assert.NoError(t, err)
+	assert.Equal(t, token.Id, int64(3))
+	assert.Equal(t, token.CreatedAt, createdAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_GetAccessTokens(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	expiresAt := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)
+
+	rows := sqlmock.NewRows(accessTokenRows).
+		AddRow(2, 1, "deploy", "{projects:read,projects:write}", createdAt, expiresAt, createdAt).
+		AddRow(1, 1, "ci", "{projects:read}", createdAt, expiresAt, nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM access_tokens WHERE user_id=(.+)").
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	got, err := repo.GetAccessTokens(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, []entity.AccessToken{
+		{Id: 2, UserId: 1, Name: "deploy", Scopes: []string{"projects:read", "projects:write"},
+			CreatedAt: createdAt, ExpiresAt: expiresAt, LastUsedAt: &createdAt},
+		{Id: 1, UserId: 1, Name: "ci", Scopes: []string{"projects:read"},
+			CreatedAt: createdAt, ExpiresAt: expiresAt},
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_DeleteAccessToken(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	mock.ExpectExec("DELETE FROM access_tokens WHERE id=(.+) AND user_id=(.+)").
+		WithArgs(3, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.DeleteAccessToken(3, 1)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectExec("DELETE FROM access_tokens WHERE id=(.+) AND user_id=(.+)").
+		WithArgs(3, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.DeleteAccessToken(3, 2)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_UseAccessToken(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	expiresAt := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)
+
+	cases := []struct {
+		name        string
+		mock        func()
+		expected    entity.AccessToken
+		expectedErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows(accessTokenRows).
+					AddRow(3, 1, "ci", "{projects:read}", createdAt, expiresAt, createdAt)
+				mock.ExpectQuery("UPDATE access_tokens t SET last_used_at=now\\(\\) FROM users u WHERE t.token_hash=(.+) AND u.disabled_at IS NULL RETURNING").
+					WithArgs("hash").
+					WillReturnRows(rows)
+			},
+			expected: entity.AccessToken{Id: 3, UserId: 1, Name: "ci", Scopes: []string{"projects:read"},
+				CreatedAt: createdAt, ExpiresAt: expiresAt, LastUsedAt: &createdAt},
+		},
+		{
+			name: "Unknown or expired",
+			mock: func() {
+				mock.ExpectQuery("UPDATE access_tokens t SET last_used_at=now\\(\\) FROM users u WHERE t.token_hash=(.+) AND u.disabled_at IS NULL RETURNING").
+					WithArgs("hash").
+					WillReturnRows(sqlmock.NewRows(accessTokenRows))
+			},
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			got, err := repo.UseAccessToken("hash")
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_ListUsers(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	disabled := true
+	cases := []struct {
+		name     string
+		query    dto.UserQueryDTO
+		mock     func()
+		expected []entity.User
+	}{
+		{
+			name:  "OK",
+			query: dto.UserQueryDTO{Limit: 21},
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "name", "email", "username", "password_hash", "role"}).
+					AddRow(1, "name", "aaa@bbb.ccc", "username", "hash", "admin")
+				mock.ExpectQuery(`SELECT \* FROM users WHERE id>\$1 ORDER BY id LIMIT \$2`).
+					WithArgs(0, 21).
+					WillReturnRows(rows)
+			},
+			expected: []entity.User{
+				{Id: 1, Name: "name", Email: "aaa@bbb.ccc", Username: "username", PasswordHash: "hash", Role: "admin"},
+			},
+		},
+		{
+			name:  "Filters and cursor",
+			query: dto.UserQueryDTO{Limit: 6, Search: "50%_off", Role: "user", Disabled: &disabled, AfterId: 3},
+			mock: func() {
+				mock.ExpectQuery(`SELECT \* FROM users WHERE id>\$1 `+
+					`AND \(name ILIKE \$2 OR email ILIKE \$2 OR username ILIKE \$2\) AND role=\$3 `+
+					`AND disabled_at IS NOT NULL ORDER BY id LIMIT \$4`).
+					WithArgs(3, `%50\%\_off%`, "user", 6).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			got, err := repo.ListUsers(c.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, got, c.expected)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_DisableUser(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	cases := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE users SET disabled_at=COALESCE\\(disabled_at, now\\(\\)\\) WHERE id=(.+)").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM tokens WHERE user_id=(.+)").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE users SET disabled_at=COALESCE\\(disabled_at, now\\(\\)\\) WHERE id=(.+)").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			err := repo.DisableUser(1)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserRepository_EnableUser(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	mock.ExpectExec("UPDATE users SET disabled_at=NULL WHERE id=(.+)").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.EnableUser(1)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectExec("UPDATE users SET disabled_at=NULL WHERE id=(.+)").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.EnableUser(2)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_GetByIdentity(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "email", "username", "password_hash"}).
+		AddRow(1, "name", "email@gmail.com", "username", "")
+	mock.ExpectQuery(`SELECT u.\* FROM users u JOIN user_identities i ON i.user_id=u.id WHERE i.provider=(.+) AND i.subject=(.+)`).
+		WithArgs("corp", "subject").
+		WillReturnRows(rows)
+
+	got, err := repo.GetByIdentity("corp", "subject")
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, entity.User{Id: 1, Name: "name", Email: "email@gmail.com", Username: "username"})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_LinkIdentity(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+
+	mock.ExpectQuery("INSERT INTO user_identities (.+) RETURNING id, created_at").
+		WithArgs(1, "corp", "subject", "email@gmail.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, createdAt))
+
+	identity := entity.UserIdentity{UserId: 1, Provider: "corp", Subject: "subject", Email: "email@gmail.com"}
+	err = repo.LinkIdentity(&identity)
+
+	assert.NoError(t, err)
+	assert.Equal(t, identity.Id, int64(3))
+	assert.Equal(t, identity.CreatedAt, createdAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_SignUpWithIdentity(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	verifiedAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+
+	cases := []struct {
+		name        string
+		mock        func()
+		expected    int64
+		expectedErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO users (.+) RETURNING id").
+					WithArgs("name", "email@gmail.com", "username", "", verifiedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectQuery("INSERT INTO user_identities (.+) RETURNING id, created_at").
+					WithArgs(2, "corp", "subject", "email@gmail.com").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, createdAt))
+				mock.ExpectCommit()
+			},
+			expected: 2,
+		},
+		{
+			name: "Failed identity",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO users (.+) RETURNING id").
+					WithArgs("name", "email@gmail.com", "username", "", verifiedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectQuery("INSERT INTO user_identities (.+) RETURNING id, created_at").
+					WithArgs(2, "corp", "subject", "email@gmail.com").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			user := entity.User{Name: "name", Email: "email@gmail.com", Username: "username", EmailVerifiedAt: &verifiedAt}
+			identity := entity.UserIdentity{Provider: "corp", Subject: "subject", Email: "email@gmail.com"}
+			got, err := repo.SignUpWithIdentity(&user, &identity)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
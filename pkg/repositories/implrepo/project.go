@@ -1,8 +1,11 @@
 package implrepo
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
@@ -17,20 +20,36 @@ func NewProjectRepository(db *sqlx.DB) *ProjectRepositoryImpl {
 	return &ProjectRepositoryImpl{db}
 }
 
+// Create stores the project together with its owner p.UserId as the first member.
 func (repo *ProjectRepositoryImpl) Create(p *entity.Project) (int64, error) {
+	tx, err := repo.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
 	var id int64
-	if err := repo.db.QueryRow(`INSERT INTO projects (title, description, done, user_id)
-								 VALUES ($1, $2, $3, $4) RETURNING id`,
-		p.Title, p.Description, p.Done, p.UserId).Scan(&id); err != nil {
+	if err := tx.QueryRow(`INSERT INTO projects (title, description, done, done_from_tasks, user_id, completed_at)
+							VALUES ($1, $2, $3, $4, $5, CASE WHEN $3 THEN now() END) RETURNING id`,
+		p.Title, p.Description, p.Done, p.DoneFromTasks, p.UserId).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec("INSERT INTO project_members (project_id, user_id, role) VALUES ($1, $2, $3)",
+		id, p.UserId, dto.ProjectRoleOwner); err != nil {
 		return 0, err
 	}
 
-	return id, nil
+	return id, tx.Commit()
 }
 
+// selectProjects selects the projects together with the role of the member m,
+// the conditions have to restrict m to a single user.
+const selectProjects = "SELECT p.*, m.role FROM projects p JOIN project_members m ON m.project_id=p.id"
+
 func (repo *ProjectRepositoryImpl) GetById(id int64, userId int64) (entity.Project, error) {
 	var project entity.Project
-	if err := repo.db.Get(&project, "SELECT * FROM projects WHERE id=$1 AND user_id=$2", id, userId); err != nil {
+	if err := repo.db.Get(&project, selectProjects+" WHERE p.id=$1 AND m.user_id=$2", id, userId); err != nil {
 		return entity.Project{}, err
 	}
 
@@ -38,13 +57,84 @@ func (repo *ProjectRepositoryImpl) GetById(id int64, userId int64) (entity.Proje
 }
 
 func (repo *ProjectRepositoryImpl) GetAll(userId int64) (projects []entity.Project, err error) {
-	if err = repo.db.Select(&projects, "SELECT * FROM projects WHERE user_id=$1", userId); err != nil {
+	if err = repo.db.Select(&projects, selectProjects+" WHERE m.user_id=$1", userId); err != nil {
 		return nil, err
 	}
 
 	return projects, nil
 }
 
+var projectSortColumns = map[string]string{
+	"title":      "p.title",
+	"created_at": "p.created_at",
+	"updated_at": "p.updated_at",
+}
+
+func (repo *ProjectRepositoryImpl) List(userId int64, query dto.ProjectQueryDTO) (projects []entity.Project, err error) {
+	column, ok := projectSortColumns[query.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort: %q", query.Sort)
+	}
+
+	conditions := []string{"m.user_id=$1"}
+	args := []interface{}{userId}
+	argId := 2
+
+	if query.Done != nil {
+		conditions = append(conditions, fmt.Sprintf("p.done=$%d", argId))
+		args = append(args, *query.Done)
+		argId++
+	}
+
+	if query.Search != "" {
+		conditions = append(conditions, fmt.Sprintf("(p.title ILIKE $%d OR p.description ILIKE $%d)", argId, argId))
+		args = append(args, "%"+escapeLike(query.Search)+"%")
+		argId++
+	}
+
+	if query.CreatedFrom != nil {
+		conditions = append(conditions, fmt.Sprintf("p.created_at>=$%d", argId))
+		args = append(args, *query.CreatedFrom)
+		argId++
+	}
+
+	if query.CreatedTo != nil {
+		conditions = append(conditions, fmt.Sprintf("p.created_at<=$%d", argId))
+		args = append(args, *query.CreatedTo)
+		argId++
+	}
+
+	order, cmp := "ASC", ">"
+	if query.Order == "desc" {
+		order, cmp = "DESC", "<"
+	}
+
+	if query.After != nil {
+		var value interface{} = query.After.Value
+		if query.Sort != "title" {
+			if value, err = time.Parse(time.RFC3339Nano, query.After.Value); err != nil {
+				return nil, errors.New("invalid cursor")
+			}
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(%s, p.id)%s($%d, $%d)", column, cmp, argId, argId+1))
+		args = append(args, value, query.After.Id)
+		argId += 2
+	}
+
+	args = append(args, query.Limit)
+
+	q := fmt.Sprintf("%s WHERE %s ORDER BY %s %s, p.id %s LIMIT $%d",
+		selectProjects, strings.Join(conditions, " AND "), column, order, order, argId)
+	if err = repo.db.Select(&projects, q, args...); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// UpdateById changes the project if the user is its owner or an editor. Done of a
+// project that derives it from its tasks is set back to the value of the tasks.
 func (repo *ProjectRepositoryImpl) UpdateById(id int64, input dto.UpdateProjectDTO, userId int64) error {
 	setValues := make([]string, 0)
 	args := make([]interface{}, 0)
@@ -63,26 +153,83 @@ func (repo *ProjectRepositoryImpl) UpdateById(id int64, input dto.UpdateProjectD
 	}
 
 	if input.Done != nil {
-		setValues = append(setValues, fmt.Sprintf("done=$%d", argId))
+		setValues = append(setValues, fmt.Sprintf("done=$%d", argId),
+			fmt.Sprintf("completed_at=CASE WHEN $%d THEN COALESCE(completed_at, now()) END", argId))
 		args = append(args, *input.Done)
 		argId++
 	}
 
+	if input.DoneFromTasks != nil {
+		setValues = append(setValues, fmt.Sprintf("done_from_tasks=$%d", argId))
+		args = append(args, *input.DoneFromTasks)
+		argId++
+	}
+
+	setValues = append(setValues, "updated_at=now()")
+
 	values := strings.Join(setValues, ", ")
 	args = append(args, id, userId)
 
-	query := fmt.Sprintf("UPDATE projects SET %s WHERE id=$%d AND user_id=$%d", values, argId, argId+1)
-	if _, err := repo.db.Exec(query, args...); err != nil {
+	tx, err := repo.db.Beginx()
+	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	return nil
+	query := fmt.Sprintf("UPDATE projects SET %s WHERE id=$%d AND %s", values, argId,
+		memberCondition("projects.id", argId+1, dto.ProjectRoleOwner, dto.ProjectRoleEditor))
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+
+	if err := syncProjectDone(tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
+// DeleteById deletes the project if the user is its owner.
 func (repo *ProjectRepositoryImpl) DeleteById(id int64, userId int64) error {
-	if _, err := repo.db.Exec("DELETE FROM projects WHERE id=$1 AND user_id=$2", id, userId); err != nil {
+	res, err := repo.db.Exec("DELETE FROM projects WHERE id=$1 AND "+
+		memberCondition("projects.id", 2, dto.ProjectRoleOwner), id, userId)
+	if err != nil {
 		return err
 	}
 
+	return requireAffected(res)
+}
+
+// memberRoles are all roles of project members.
+var memberRoles = []string{dto.ProjectRoleOwner, dto.ProjectRoleEditor, dto.ProjectRoleViewer}
+
+// memberCondition returns a condition that the user of the parameter $userArg is
+// a member of the project with one of the roles. The roles are constants,
+// so they are safe to be written into the query.
+func memberCondition(projectId string, userArg int, roles ...string) string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM project_members WHERE project_id=%s AND user_id=$%d AND role IN ('%s'))",
+		projectId, userArg, strings.Join(roles, "', '"))
+}
+
+// requireAffected returns sql.ErrNoRows if the statement did not change any row.
+func requireAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
 	return nil
 }
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,146 @@
+package implrepo
+
+import (
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+)
+
+// GetMemberRole returns the role of the user in the project. Statements that
+// require a role return sql.ErrNoRows for users without it just like for a
+// missing project or member, the role tells the causes apart.
+func (repo *ProjectRepositoryImpl) GetMemberRole(projectId int64, userId int64) (string, error) {
+	var role string
+	if err := repo.db.Get(&role, "SELECT role FROM project_members WHERE project_id=$1 AND user_id=$2",
+		projectId, userId); err != nil {
+		return "", err
+	}
+
+	return role, nil
+}
+
+// GetMembers returns the members of the project if the user is one of them
+// and no members otherwise, as every project has at least its owner.
+func (repo *ProjectRepositoryImpl) GetMembers(projectId int64, userId int64) (members []entity.ProjectMember, err error) {
+	if err = repo.db.Select(&members, `SELECT m.project_id, m.user_id, u.username, u.name, m.role, m.created_at
+										FROM project_members m JOIN users u ON u.id=m.user_id
+										WHERE m.project_id=$1 AND `+memberCondition("m.project_id", 2, memberRoles...)+`
+										ORDER BY m.created_at, m.user_id`, projectId, userId); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// AddMember adds the user with the username or email of the input to the project
+// if the user userId is its owner. Both are matched case-insensitively.
+func (repo *ProjectRepositoryImpl) AddMember(projectId int64, userId int64,
+	input dto.AddProjectMemberDTO) (entity.ProjectMember, error) {
+	condition, identifier := "lower(u.username)=lower($3)", input.Username
+	if input.Email != "" {
+		condition, identifier = "lower(u.email)=lower($3)", input.Email
+	}
+
+	var member entity.ProjectMember
+	if err := repo.db.Get(&member, `WITH added AS (
+										INSERT INTO project_members (project_id, user_id, role)
+										SELECT $1::int, u.id, $4 FROM users u
+										WHERE `+condition+` AND `+memberCondition("$1", 2, dto.ProjectRoleOwner)+`
+										RETURNING project_id, user_id, role, created_at)
+									SELECT a.project_id, a.user_id, u.username, u.name, a.role, a.created_at
+									FROM added a JOIN users u ON u.id=a.user_id`,
+		projectId, userId, identifier, input.Role); err != nil {
+		return entity.ProjectMember{}, err
+	}
+
+	return member, nil
+}
+
+// UpdateMemberRole changes the role of the member if the user userId is the owner
+// of the project. The role of the owner itself is left unchanged.
+func (repo *ProjectRepositoryImpl) UpdateMemberRole(projectId int64, userId int64, memberId int64, role string) error {
+	res, err := repo.db.Exec(`UPDATE project_members SET role=$1
+								WHERE project_id=$2 AND user_id=$3 AND role<>$4 AND `+
+		memberCondition("$2", 5, dto.ProjectRoleOwner),
+		role, projectId, memberId, dto.ProjectRoleOwner, userId)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+// RemoveMember removes the member from the project if the user userId is the owner
+// of the project or the member itself. The owner is never removed. Tasks of the
+// project assigned to the member are unassigned.
+func (repo *ProjectRepositoryImpl) RemoveMember(projectId int64, userId int64, memberId int64) error {
+	tx, err := repo.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM project_members
+							WHERE project_id=$1 AND user_id=$2 AND role<>$3 AND ($2=$4 OR `+
+		memberCondition("$1", 4, dto.ProjectRoleOwner)+`)`,
+		projectId, memberId, dto.ProjectRoleOwner, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE tasks SET assignee_id=NULL, updated_at=now() WHERE project_id=$1 AND assignee_id=$2",
+		projectId, memberId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// TransferOwnership makes the member newOwnerId the owner of the project if the
+// user userId is its owner, the previous owner stays a member as an editor.
+func (repo *ProjectRepositoryImpl) TransferOwnership(projectId int64, userId int64, newOwnerId int64) error {
+	tx, err := repo.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// the previous owner is demoted first, a project can not have two owners
+	res, err := tx.Exec("UPDATE project_members SET role=$1 WHERE project_id=$2 AND user_id=$3 AND role=$4",
+		dto.ProjectRoleEditor, projectId, userId, dto.ProjectRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+
+	res, err = tx.Exec("UPDATE project_members SET role=$1 WHERE project_id=$2 AND user_id=$3",
+		dto.ProjectRoleOwner, projectId, newOwnerId)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE projects SET user_id=$1, updated_at=now() WHERE id=$2", newOwnerId, projectId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MemberIds returns the ids of all members of the project.
+func (repo *ProjectRepositoryImpl) MemberIds(projectId int64) (ids []int64, err error) {
+	if err = repo.db.Select(&ids, "SELECT user_id FROM project_members WHERE project_id=$1", projectId); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,212 @@
+package implrepo
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/stretchr/testify/assert"
+	sqlxmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+var memberColumns = []string{"project_id", "user_id", "username", "name", "role", "created_at"}
+
+func TestProjectRepository_GetMembers(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewProjectRepository(db)
+
+	rows := sqlxmock.NewRows(memberColumns).
+		AddRow(1, 2, "owner", "Owner", "owner", createdAt).
+		AddRow(1, 3, "viewer", "Viewer", "viewer", createdAt)
+	mock.ExpectQuery(`FROM project_members m JOIN users u ON u\.id=m\.user_id\s+WHERE m\.project_id=\$1 AND EXISTS \(SELECT 1 `+
+		`FROM project_members WHERE project_id=m\.project_id AND user_id=\$2 AND role IN \('owner', 'editor', 'viewer'\)\)`).
+		WithArgs(1, 3).
+		WillReturnRows(rows)
+
+	got, err := repo.GetMembers(1, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, []entity.ProjectMember{
+		{ProjectId: 1, UserId: 2, Username: "owner", Name: "Owner", Role: "owner", CreatedAt: createdAt},
+		{ProjectId: 1, UserId: 3, Username: "viewer", Name: "Viewer", Role: "viewer", CreatedAt: createdAt},
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProjectRepository_AddMember(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewProjectRepository(db)
+
+	cases := []struct {
+		name        string
+		input       dto.AddProjectMemberDTO
+		mock        func()
+		expected    entity.ProjectMember
+		expectedErr error
+	}{
+		{
+			name:  "By username",
+			input: dto.AddProjectMemberDTO{Username: "Editor", Role: "editor"},
+			mock: func() {
+				rows := sqlxmock.NewRows(memberColumns).AddRow(1, 3, "editor", "Editor", "editor", createdAt)
+				mock.ExpectQuery(`WHERE lower\(u\.username\)=lower\(\$3\) AND EXISTS \(SELECT 1 FROM project_members `+
+					`WHERE project_id=\$1 AND user_id=\$2 AND role IN \('owner'\)\)`).
+					WithArgs(1, 2, "Editor", "editor").
+					WillReturnRows(rows)
+			},
+			expected: entity.ProjectMember{
+				ProjectId: 1, UserId: 3, Username: "editor", Name: "Editor", Role: "editor", CreatedAt: createdAt,
+			},
+		},
+		{
+			name:  "By email",
+			input: dto.AddProjectMemberDTO{Email: "viewer@mail.com", Role: "viewer"},
+			mock: func() {
+				mock.ExpectQuery(`WHERE lower\(u\.email\)=lower\(\$3\)`).
+					WithArgs(1, 2, "viewer@mail.com", "viewer").
+					WillReturnRows(sqlxmock.NewRows(memberColumns))
+			},
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			got, err := repo.AddMember(1, 2, c.input)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestProjectRepository_UpdateMemberRole(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewProjectRepository(db)
+
+	mock.ExpectExec(`UPDATE project_members SET role=\$1\s+WHERE project_id=\$2 AND user_id=\$3 AND role<>\$4 AND EXISTS`).
+		WithArgs("viewer", 1, 3, "owner", 2).
+		WillReturnResult(sqlxmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.UpdateMemberRole(1, 2, 3, "viewer"))
+
+	mock.ExpectExec("UPDATE project_members SET role").
+		WithArgs("viewer", 1, 3, "owner", 4).
+		WillReturnResult(sqlxmock.NewResult(0, 0))
+
+	assert.ErrorIs(t, repo.UpdateMemberRole(1, 4, 3, "viewer"), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProjectRepository_RemoveMember(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewProjectRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM project_members\s+WHERE project_id=\$1 AND user_id=\$2 AND role<>\$3 AND \(\$2=\$4 OR EXISTS`).
+		WithArgs(1, 3, "owner", 3).
+		WillReturnResult(sqlxmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE tasks SET assignee_id=NULL, updated_at=now\(\) WHERE project_id=\$1 AND assignee_id=\$2`).
+		WithArgs(1, 3).
+		WillReturnResult(sqlxmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.RemoveMember(1, 3, 3))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM project_members").
+		WithArgs(1, 2, "owner", 2).
+		WillReturnResult(sqlxmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.RemoveMember(1, 2, 2), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProjectRepository_TransferOwnership(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewProjectRepository(db)
+
+	cases := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE project_members SET role").
+					WithArgs("editor", 1, 2, "owner").
+					WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE project_members SET role").
+					WithArgs("owner", 1, 3).
+					WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE projects SET user_id").
+					WithArgs(3, 1).
+					WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Not the owner",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE project_members SET role").
+					WithArgs("editor", 1, 2, "owner").
+					WillReturnResult(sqlxmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrNoRows,
+		},
+		{
+			name: "Not a member",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE project_members SET role").
+					WithArgs("editor", 1, 2, "owner").
+					WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE project_members SET role").
+					WithArgs("owner", 1, 3).
+					WillReturnResult(sqlxmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			err := repo.TransferOwnership(1, 2, 3)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package implrepo
 
 import (
+	"database/sql"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
@@ -9,6 +12,12 @@ import (
 	sqlxmock "github.com/zhashkevych/go-sqlxmock"
 )
 
+var (
+	projectColumns = []string{"id", "title", "description", "done", "user_id",
+		"created_at", "updated_at", "completed_at", "role"}
+	createdAt = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
 func TestProjectRepository_Create(t *testing.T) {
 	db, mock, err := sqlxmock.Newx()
 
@@ -32,9 +41,14 @@ func TestProjectRepository_Create(t *testing.T) {
 			},
 			mock: func() {
 				rows := sqlxmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectBegin()
 				mock.ExpectQuery("INSERT INTO projects").
-					WithArgs("title", "", false, 1).
+					WithArgs("title", "", false, false, 1).
 					WillReturnRows(rows)
+				mock.ExpectExec("INSERT INTO project_members").
+					WithArgs(1, 1, "owner").
+					WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectCommit()
 			},
 			expected: 1,
 		},
@@ -42,8 +56,11 @@ func TestProjectRepository_Create(t *testing.T) {
 			name:    "emty field",
 			project: entity.Project{},
 			mock: func() {
+				mock.ExpectBegin()
 				mock.ExpectQuery("INSERT INTO projects").
-					WithArgs("", "", false, 0)
+					WithArgs("", "", false, false, 0).
+					WillReturnError(errors.New("violates not-null constraint"))
+				mock.ExpectRollback()
 			},
 			expected:    1,
 			expectedErr: true,
@@ -91,8 +108,8 @@ func TestProjectRepository_GetById(t *testing.T) {
 			name: "OK",
 			args: args{1, 2},
 			mock: func() {
-				rows := sqlxmock.NewRows([]string{"id", "title", "description", "done", "user_id"}).
-					AddRow(1, "title", "description", false, 2)
+				rows := sqlxmock.NewRows(projectColumns).
+					AddRow(1, "title", "description", true, 2, createdAt, createdAt, createdAt, "owner")
 				mock.ExpectQuery("SELECT (.+) FROM projects").
 					WithArgs(1, 2).
 					WillReturnRows(rows)
@@ -101,15 +118,19 @@ func TestProjectRepository_GetById(t *testing.T) {
 				Id:          1,
 				Title:       "title",
 				Description: "description",
-				Done:        false,
+				Done:        true,
 				UserId:      2,
+				CreatedAt:   createdAt,
+				UpdatedAt:   createdAt,
+				CompletedAt: &createdAt,
+				Role:        "owner",
 			},
 		},
 		{
 			name: "Not found",
 			args: args{1, 2},
 			mock: func() {
-				rows := sqlxmock.NewRows([]string{"id", "title", "description", "done", "user_id"})
+				rows := sqlxmock.NewRows(projectColumns)
 				mock.ExpectQuery("SELECT (.+) FROM projects").
 					WithArgs(1, 2).
 					WillReturnRows(rows)
@@ -150,10 +171,13 @@ func TestProjectRepository_GetAll(t *testing.T) {
 			Title:       "title",
 			Description: "description",
 			UserId:      arg,
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+			Role:        "editor",
 		},
 	}
-	rows := sqlxmock.NewRows([]string{"id", "title", "description", "done", "user_id"}).
-		AddRow(1, "title", "description", false, arg)
+	rows := sqlxmock.NewRows(projectColumns).
+		AddRow(1, "title", "description", false, arg, createdAt, createdAt, nil, "editor")
 
 	mock.ExpectQuery("SELECT (.+) FROM projects").
 		WithArgs(arg).
@@ -166,6 +190,105 @@ func TestProjectRepository_GetAll(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestProjectRepository_List(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewProjectRepository(db)
+
+	done := true
+	cases := []struct {
+		name        string
+		query       dto.ProjectQueryDTO
+		mock        func()
+		expected    []entity.Project
+		expectedErr bool
+	}{
+		{
+			name:  "OK",
+			query: dto.ProjectQueryDTO{Limit: 21, Sort: "created_at", Order: "asc"},
+			mock: func() {
+				rows := sqlxmock.NewRows(projectColumns).
+					AddRow(1, "title", "description", false, 1, createdAt, createdAt, nil, "owner")
+				mock.ExpectQuery(`SELECT p\.\*, m\.role FROM projects p JOIN project_members m ON m\.project_id=p\.id `+
+					`WHERE m\.user_id=\$1 ORDER BY p\.created_at ASC, p\.id ASC LIMIT \$2`).
+					WithArgs(1, 21).
+					WillReturnRows(rows)
+			},
+			expected: []entity.Project{
+				{
+					Id:          1,
+					Title:       "title",
+					Description: "description",
+					UserId:      1,
+					CreatedAt:   createdAt,
+					UpdatedAt:   createdAt,
+					Role:        "owner",
+				},
+			},
+		},
+		{
+			name: "Filters and cursor",
+			query: dto.ProjectQueryDTO{
+				Limit:       6,
+				Done:        &done,
+				Search:      "50%_off",
+				CreatedFrom: &createdAt,
+				CreatedTo:   &createdAt,
+				Sort:        "updated_at",
+				Order:       "desc",
+				After: &dto.ProjectCursor{
+					Sort:  "updated_at",
+					Value: createdAt.Format(time.RFC3339Nano),
+					Id:    3,
+				},
+			},
+			mock: func() {
+				mock.ExpectQuery(`WHERE m\.user_id=\$1 AND p\.done=\$2 `+
+					`AND \(p\.title ILIKE \$3 OR p\.description ILIKE \$3\) AND p\.created_at>=\$4 AND p\.created_at<=\$5 `+
+					`AND \(p\.updated_at, p\.id\)<\(\$6, \$7\) ORDER BY p\.updated_at DESC, p\.id DESC LIMIT \$8`).
+					WithArgs(1, true, `%50\%\_off%`, createdAt, createdAt, createdAt, 3, 6).
+					WillReturnRows(sqlxmock.NewRows(projectColumns))
+			},
+		},
+		{
+			name:        "Unsupported sort",
+			query:       dto.ProjectQueryDTO{Limit: 21, Sort: "user_id", Order: "asc"},
+			mock:        func() {},
+			expectedErr: true,
+		},
+		{
+			name: "Invalid cursor value",
+			query: dto.ProjectQueryDTO{
+				Limit: 21,
+				Sort:  "created_at",
+				Order: "asc",
+				After: &dto.ProjectCursor{Sort: "created_at", Value: "title", Id: 3},
+			},
+			mock:        func() {},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			got, err := repo.List(1, c.query)
+			if c.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, got, c.expected)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestProjectRepository_UpdateById(t *testing.T) {
 	db, mock, err := sqlxmock.Newx()
 
@@ -178,14 +301,30 @@ func TestProjectRepository_UpdateById(t *testing.T) {
 	input := dto.UpdateProjectDTO{
 		Done: &updateDone,
 	}
-	mock.ExpectExec("UPDATE projects SET").
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE projects SET done=\$1, completed_at=CASE WHEN \$1 THEN COALESCE\(completed_at, now\(\)\) END, updated_at=now\(\)`).
 		WithArgs(updateDone, 1, 2).
 		WillReturnResult(sqlxmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE projects\s+SET done=t\.done`).
+		WithArgs(1).
+		WillReturnResult(sqlxmock.NewResult(0, 0))
+	mock.ExpectCommit()
 
 	err = repo.UpdateById(1, input, 2)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE projects SET").
+		WithArgs(updateDone, 1, 3).
+		WillReturnResult(sqlxmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.UpdateById(1, input, 3)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestProjectRepository_DeleteById(t *testing.T) {
@@ -205,4 +344,13 @@ func TestProjectRepository_DeleteById(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 
+	mock.ExpectExec("DELETE FROM projects").
+		WithArgs(1, 3).
+		WillReturnResult(sqlxmock.NewResult(0, 0))
+
+	err = repo.DeleteById(1, 3)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
 }
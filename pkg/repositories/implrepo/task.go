@@ -0,0 +1,199 @@
+package implrepo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/jmoiron/sqlx"
+)
+
+// TaskRepositoryImpl authorizes like ProjectRepositoryImpl: every member of the
+// project can read its tasks, owners and editors can change them.
+type TaskRepositoryImpl struct {
+	db *sqlx.DB
+}
+
+func NewTaskRepository(db *sqlx.DB) *TaskRepositoryImpl {
+	return &TaskRepositoryImpl{db}
+}
+
+const taskColumns = "t.id, t.project_id, t.title, t.notes, t.status, t.due_date, t.position, t.assignee_id, " +
+	"t.created_at, t.updated_at, t.completed_at"
+
+// Create stores the task if the user is allowed to change the project and the
+// assignee is a member of it. A task without a position is appended.
+func (repo *TaskRepositoryImpl) Create(t *entity.Task, userId int64) (int64, error) {
+	tx, err := repo.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	if err := tx.QueryRow(`INSERT INTO tasks (project_id, title, notes, status, due_date, position, assignee_id, completed_at)
+							SELECT $1::int, $2, $3, $4, $5::date,
+								COALESCE($6::int, (SELECT COALESCE(MAX(position) + 1, 0) FROM tasks WHERE project_id=$1)),
+								$7::int, CASE WHEN $4='done' THEN now() END
+							WHERE `+memberCondition("$1", 8, dto.ProjectRoleOwner, dto.ProjectRoleEditor)+`
+							AND ($7 IS NULL OR `+memberCondition("$1", 7, memberRoles...)+`)
+							RETURNING id`,
+		t.ProjectId, t.Title, t.Notes, t.Status, t.DueDate, t.Position, t.AssigneeId, userId).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	if err := syncProjectDone(tx, t.ProjectId); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (repo *TaskRepositoryImpl) GetById(projectId int64, id int64, userId int64) (entity.Task, error) {
+	var task entity.Task
+	if err := repo.db.Get(&task, `SELECT `+taskColumns+` FROM tasks t
+									JOIN project_members m ON m.project_id=t.project_id
+									WHERE t.project_id=$1 AND t.id=$2 AND m.user_id=$3`, projectId, id, userId); err != nil {
+		return entity.Task{}, err
+	}
+
+	return task, nil
+}
+
+// List returns the tasks of the project in their order, users who are not
+// members of the project get no tasks.
+func (repo *TaskRepositoryImpl) List(projectId int64, userId int64, query dto.TaskQueryDTO) (tasks []entity.Task, err error) {
+	conditions := []string{"t.project_id=$1", "m.user_id=$2"}
+	args := []interface{}{projectId, userId}
+	argId := 3
+
+	if query.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("t.status=$%d", argId))
+		args = append(args, query.Status)
+		argId++
+	}
+
+	if query.AssigneeId != 0 {
+		conditions = append(conditions, fmt.Sprintf("t.assignee_id=$%d", argId))
+		args = append(args, query.AssigneeId)
+	}
+
+	q := fmt.Sprintf(`SELECT %s FROM tasks t JOIN project_members m ON m.project_id=t.project_id
+						WHERE %s ORDER BY t.position, t.id`, taskColumns, strings.Join(conditions, " AND "))
+	if err = repo.db.Select(&tasks, q, args...); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// UpdateById changes the task if the user is allowed to change the project
+// and a new assignee is a member of it.
+func (repo *TaskRepositoryImpl) UpdateById(projectId int64, id int64, input dto.UpdateTaskDTO, userId int64) error {
+	setValues := make([]string, 0)
+	conditions := []string{"project_id=$1", "id=$2",
+		memberCondition("tasks.project_id", 3, dto.ProjectRoleOwner, dto.ProjectRoleEditor)}
+	args := []interface{}{projectId, id, userId}
+	argId := 4
+
+	if input.Title != nil {
+		setValues = append(setValues, fmt.Sprintf("title=$%d", argId))
+		args = append(args, *input.Title)
+		argId++
+	}
+
+	if input.Notes != nil {
+		setValues = append(setValues, fmt.Sprintf("notes=$%d", argId))
+		args = append(args, *input.Notes)
+		argId++
+	}
+
+	if input.Status != nil {
+		setValues = append(setValues, fmt.Sprintf("status=$%d", argId),
+			fmt.Sprintf("completed_at=CASE WHEN $%d='done' THEN COALESCE(completed_at, now()) END", argId))
+		args = append(args, *input.Status)
+		argId++
+	}
+
+	if input.DueDate != nil {
+		setValues = append(setValues, fmt.Sprintf("due_date=NULLIF($%d, '')::date", argId))
+		args = append(args, *input.DueDate)
+		argId++
+	}
+
+	if input.Position != nil {
+		setValues = append(setValues, fmt.Sprintf("position=$%d", argId))
+		args = append(args, *input.Position)
+		argId++
+	}
+
+	if input.AssigneeId != nil {
+		setValues = append(setValues, fmt.Sprintf("assignee_id=NULLIF($%d, 0)", argId))
+		if *input.AssigneeId != 0 {
+			conditions = append(conditions, memberCondition("tasks.project_id", argId, memberRoles...))
+		}
+		args = append(args, *input.AssigneeId)
+	}
+
+	setValues = append(setValues, "updated_at=now()")
+
+	tx, err := repo.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("UPDATE tasks SET %s WHERE %s", strings.Join(setValues, ", "), strings.Join(conditions, " AND "))
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+
+	if err := syncProjectDone(tx, projectId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteById deletes the task if the user is allowed to change the project.
+func (repo *TaskRepositoryImpl) DeleteById(projectId int64, id int64, userId int64) error {
+	tx, err := repo.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("DELETE FROM tasks WHERE project_id=$1 AND id=$2 AND "+
+		memberCondition("tasks.project_id", 3, dto.ProjectRoleOwner, dto.ProjectRoleEditor), projectId, id, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+
+	if err := syncProjectDone(tx, projectId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// syncProjectDone derives done of the project from its tasks if the project asks
+// for it: the project is done when it has tasks and all of them are done.
+func syncProjectDone(tx *sqlx.Tx, projectId int64) error {
+	_, err := tx.Exec(`UPDATE projects
+						SET done=t.done, completed_at=CASE WHEN t.done THEN COALESCE(completed_at, now()) END,
+							updated_at=now()
+						FROM (SELECT COALESCE(bool_and(status='done'), false) AS done FROM tasks WHERE project_id=$1) t
+						WHERE id=$1 AND done_from_tasks AND projects.done<>t.done`, projectId)
+
+	return err
+}
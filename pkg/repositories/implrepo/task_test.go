@@ -0,0 +1,287 @@
+package implrepo
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/stretchr/testify/assert"
+	sqlxmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+var taskColumnNames = []string{"id", "project_id", "title", "notes", "status", "due_date", "position", "assignee_id",
+	"created_at", "updated_at", "completed_at"}
+
+func TestTaskRepository_Create(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewTaskRepository(db)
+
+	assigneeId := int64(3)
+
+	cases := []struct {
+		name        string
+		task        entity.Task
+		mock        func()
+		expected    int64
+		expectedErr error
+	}{
+		{
+			name: "OK",
+			task: entity.Task{ProjectId: 1, Title: "title", Status: dto.TaskStatusTodo, AssigneeId: &assigneeId},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO tasks .+ WHERE EXISTS \(SELECT 1 FROM project_members `+
+					`WHERE project_id=\$1 AND user_id=\$8 AND role IN \('owner', 'editor'\)\)\s+`+
+					`AND \(\$7 IS NULL OR EXISTS \(SELECT 1 FROM project_members WHERE project_id=\$1 AND user_id=\$7`).
+					WithArgs(1, "title", "", "todo", nil, nil, 3, 2).
+					WillReturnRows(sqlxmock.NewRows([]string{"id"}).AddRow(4))
+				mock.ExpectExec(`UPDATE projects\s+SET done=t\.done`).
+					WithArgs(1).
+					WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expected: 4,
+		},
+		{
+			name: "Not allowed",
+			task: entity.Task{ProjectId: 1, Title: "title", Status: dto.TaskStatusTodo},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO tasks").
+					WithArgs(1, "title", "", "todo", nil, nil, nil, 2).
+					WillReturnRows(sqlxmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			got, err := repo.Create(&c.task, 2)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTaskRepository_GetById(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewTaskRepository(db)
+
+	position := 0
+	rows := sqlxmock.NewRows(taskColumnNames).
+		AddRow(4, 1, "title", "", "todo", nil, 0, nil, createdAt, createdAt, nil)
+	mock.ExpectQuery(`FROM tasks t\s+JOIN project_members m ON m\.project_id=t\.project_id\s+`+
+		`WHERE t\.project_id=\$1 AND t\.id=\$2 AND m\.user_id=\$3`).
+		WithArgs(1, 4, 2).
+		WillReturnRows(rows)
+
+	got, err := repo.GetById(1, 4, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, entity.Task{
+		Id: 4, ProjectId: 1, Title: "title", Status: "todo", Position: &position,
+		CreatedAt: createdAt, UpdatedAt: createdAt,
+	})
+
+	mock.ExpectQuery("FROM tasks t").
+		WithArgs(1, 5, 2).
+		WillReturnRows(sqlxmock.NewRows(taskColumnNames))
+
+	_, err = repo.GetById(1, 5, 2)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTaskRepository_List(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewTaskRepository(db)
+
+	first, second := 0, 1
+
+	cases := []struct {
+		name        string
+		query       dto.TaskQueryDTO
+		mock        func()
+		expected    []entity.Task
+		expectedErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlxmock.NewRows(taskColumnNames).
+					AddRow(4, 1, "first", "", "todo", nil, 0, nil, createdAt, createdAt, nil).
+					AddRow(5, 1, "second", "", "done", nil, 1, nil, createdAt, createdAt, createdAt)
+				mock.ExpectQuery(`WHERE t\.project_id=\$1 AND m\.user_id=\$2 ORDER BY t\.position, t\.id`).
+					WithArgs(1, 2).
+					WillReturnRows(rows)
+			},
+			expected: []entity.Task{
+				{Id: 4, ProjectId: 1, Title: "first", Status: "todo", Position: &first,
+					CreatedAt: createdAt, UpdatedAt: createdAt},
+				{Id: 5, ProjectId: 1, Title: "second", Status: "done", Position: &second,
+					CreatedAt: createdAt, UpdatedAt: createdAt, CompletedAt: &createdAt},
+			},
+		},
+		{
+			name:  "Filters",
+			query: dto.TaskQueryDTO{Status: dto.TaskStatusDone, AssigneeId: 3},
+			mock: func() {
+				mock.ExpectQuery(`WHERE t\.project_id=\$1 AND m\.user_id=\$2 AND t\.status=\$3 AND t\.assignee_id=\$4 ORDER BY`).
+					WithArgs(1, 2, "done", 3).
+					WillReturnRows(sqlxmock.NewRows(taskColumnNames))
+			},
+		},
+		{
+			name: "Failed",
+			mock: func() {
+				mock.ExpectQuery("FROM tasks t").
+					WithArgs(1, 2).
+					WillReturnError(errors.New("some error"))
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			got, err := repo.List(1, 2, c.query)
+			if c.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, got, c.expected)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTaskRepository_UpdateById(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewTaskRepository(db)
+
+	status, dueDate := dto.TaskStatusDone, ""
+	assigneeId, unassigned := int64(3), int64(0)
+
+	cases := []struct {
+		name        string
+		input       dto.UpdateTaskDTO
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name:  "Status and due date",
+			input: dto.UpdateTaskDTO{Status: &status, DueDate: &dueDate},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE tasks SET status=\$4, completed_at=CASE WHEN \$4='done' THEN COALESCE\(completed_at, now\(\)\) END, `+
+					`due_date=NULLIF\(\$5, ''\)::date, updated_at=now\(\) WHERE project_id=\$1 AND id=\$2 AND EXISTS`).
+					WithArgs(1, 4, 2, "done", "").
+					WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE projects\s+SET done=t\.done`).
+					WithArgs(1).
+					WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:  "Assignee",
+			input: dto.UpdateTaskDTO{AssigneeId: &assigneeId},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE tasks SET assignee_id=NULLIF\(\$4, 0\), updated_at=now\(\) WHERE .+ `+
+					`AND EXISTS \(SELECT 1 FROM project_members WHERE project_id=tasks\.project_id AND user_id=\$4 `+
+					`AND role IN \('owner', 'editor', 'viewer'\)\)`).
+					WithArgs(1, 4, 2, 3).
+					WillReturnResult(sqlxmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrNoRows,
+		},
+		{
+			name:  "Unassign",
+			input: dto.UpdateTaskDTO{AssigneeId: &unassigned},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE tasks SET assignee_id=NULLIF\(\$4, 0\), updated_at=now\(\) WHERE project_id=\$1 AND id=\$2 `+
+					`AND EXISTS \(SELECT 1 FROM project_members WHERE project_id=tasks\.project_id AND user_id=\$3 `+
+					`AND role IN \('owner', 'editor'\)\)$`).
+					WithArgs(1, 4, 2, 0).
+					WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE projects").
+					WithArgs(1).
+					WillReturnResult(sqlxmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.mock()
+
+			err := repo.UpdateById(1, 4, c.input, 2)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTaskRepository_DeleteById(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+
+	assert.NoError(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer db.Close()
+
+	repo := NewTaskRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM tasks WHERE project_id=\$1 AND id=\$2 AND EXISTS \(SELECT 1 FROM project_members `+
+		`WHERE project_id=tasks\.project_id AND user_id=\$3 AND role IN \('owner', 'editor'\)\)`).
+		WithArgs(1, 4, 2).
+		WillReturnResult(sqlxmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE projects\s+SET done=t\.done`).
+		WithArgs(1).
+		WillReturnResult(sqlxmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.DeleteById(1, 4, 2))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM tasks").
+		WithArgs(1, 4, 3).
+		WillReturnResult(sqlxmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.DeleteById(1, 4, 3), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -36,6 +36,21 @@ func (m *MockProjectRepository) EXPECT() *MockProjectRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddMember mocks base method.
+func (m *MockProjectRepository) AddMember(projectId, userId int64, input dto.AddProjectMemberDTO) (entity.ProjectMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", projectId, userId, input)
+	ret0, _ := ret[0].(entity.ProjectMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockProjectRepositoryMockRecorder) AddMember(projectId, userId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockProjectRepository)(nil).AddMember), projectId, userId, input)
+}
+
 // Create mocks base method.
 func (m *MockProjectRepository) Create(p *entity.Project) (int64, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +110,94 @@ func (mr *MockProjectRepositoryMockRecorder) GetById(id, userId interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockProjectRepository)(nil).GetById), id, userId)
 }
 
+// GetMemberRole mocks base method.
+func (m *MockProjectRepository) GetMemberRole(projectId, userId int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberRole", projectId, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberRole indicates an expected call of GetMemberRole.
+func (mr *MockProjectRepositoryMockRecorder) GetMemberRole(projectId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberRole", reflect.TypeOf((*MockProjectRepository)(nil).GetMemberRole), projectId, userId)
+}
+
+// GetMembers mocks base method.
+func (m *MockProjectRepository) GetMembers(projectId, userId int64) ([]entity.ProjectMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", projectId, userId)
+	ret0, _ := ret[0].([]entity.ProjectMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockProjectRepositoryMockRecorder) GetMembers(projectId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockProjectRepository)(nil).GetMembers), projectId, userId)
+}
+
+// List mocks base method.
+func (m *MockProjectRepository) List(userId int64, query dto.ProjectQueryDTO) ([]entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", userId, query)
+	ret0, _ := ret[0].([]entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockProjectRepositoryMockRecorder) List(userId, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockProjectRepository)(nil).List), userId, query)
+}
+
+// MemberIds mocks base method.
+func (m *MockProjectRepository) MemberIds(projectId int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MemberIds", projectId)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MemberIds indicates an expected call of MemberIds.
+func (mr *MockProjectRepositoryMockRecorder) MemberIds(projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MemberIds", reflect.TypeOf((*MockProjectRepository)(nil).MemberIds), projectId)
+}
+
+// RemoveMember mocks base method.
+func (m *MockProjectRepository) RemoveMember(projectId, userId, memberId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", projectId, userId, memberId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockProjectRepositoryMockRecorder) RemoveMember(projectId, userId, memberId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockProjectRepository)(nil).RemoveMember), projectId, userId, memberId)
+}
+
+// TransferOwnership mocks base method.
+func (m *MockProjectRepository) TransferOwnership(projectId, userId, newOwnerId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferOwnership", projectId, userId, newOwnerId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferOwnership indicates an expected call of TransferOwnership.
+func (mr *MockProjectRepositoryMockRecorder) TransferOwnership(projectId, userId, newOwnerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferOwnership", reflect.TypeOf((*MockProjectRepository)(nil).TransferOwnership), projectId, userId, newOwnerId)
+}
+
 // UpdateById mocks base method.
 func (m *MockProjectRepository) UpdateById(id int64, input dto.UpdateProjectDTO, userId int64) error {
 	m.ctrl.T.Helper()
@@ -109,6 +212,116 @@ func (mr *MockProjectRepositoryMockRecorder) UpdateById(id, input, userId interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateById", reflect.TypeOf((*MockProjectRepository)(nil).UpdateById), id, input, userId)
 }
 
+// UpdateMemberRole mocks base method.
+func (m *MockProjectRepository) UpdateMemberRole(projectId, userId, memberId int64, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMemberRole", projectId, userId, memberId, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMemberRole indicates an expected call of UpdateMemberRole.
+func (mr *MockProjectRepositoryMockRecorder) UpdateMemberRole(projectId, userId, memberId, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRole", reflect.TypeOf((*MockProjectRepository)(nil).UpdateMemberRole), projectId, userId, memberId, role)
+}
+
+// MockTaskRepository is a mock of TaskRepository interface.
+type MockTaskRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskRepositoryMockRecorder
+}
+
+// MockTaskRepositoryMockRecorder is the mock recorder for MockTaskRepository.
+type MockTaskRepositoryMockRecorder struct {
+	mock *MockTaskRepository
+}
+
+// NewMockTaskRepository creates a new mock instance.
+func NewMockTaskRepository(ctrl *gomock.Controller) *MockTaskRepository {
+	mock := &MockTaskRepository{ctrl: ctrl}
+	mock.recorder = &MockTaskRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskRepository) EXPECT() *MockTaskRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockTaskRepository) Create(t *entity.Task, userId int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", t, userId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTaskRepositoryMockRecorder) Create(t, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTaskRepository)(nil).Create), t, userId)
+}
+
+// DeleteById mocks base method.
+func (m *MockTaskRepository) DeleteById(projectId, id, userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteById", projectId, id, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteById indicates an expected call of DeleteById.
+func (mr *MockTaskRepositoryMockRecorder) DeleteById(projectId, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteById", reflect.TypeOf((*MockTaskRepository)(nil).DeleteById), projectId, id, userId)
+}
+
+// GetById mocks base method.
+func (m *MockTaskRepository) GetById(projectId, id, userId int64) (entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", projectId, id, userId)
+	ret0, _ := ret[0].(entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockTaskRepositoryMockRecorder) GetById(projectId, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockTaskRepository)(nil).GetById), projectId, id, userId)
+}
+
+// List mocks base method.
+func (m *MockTaskRepository) List(projectId, userId int64, query dto.TaskQueryDTO) ([]entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", projectId, userId, query)
+	ret0, _ := ret[0].([]entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTaskRepositoryMockRecorder) List(projectId, userId, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTaskRepository)(nil).List), projectId, userId, query)
+}
+
+// UpdateById mocks base method.
+func (m *MockTaskRepository) UpdateById(projectId, id int64, input dto.UpdateTaskDTO, userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateById", projectId, id, input, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateById indicates an expected call of UpdateById.
+func (mr *MockTaskRepositoryMockRecorder) UpdateById(projectId, id, input, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateById", reflect.TypeOf((*MockTaskRepository)(nil).UpdateById), projectId, id, input, userId)
+}
+
 // MockAuthRepository is a mock of AuthRepository interface.
 type MockAuthRepository struct {
 	ctrl     *gomock.Controller
@@ -132,63 +345,419 @@ func (m *MockAuthRepository) EXPECT() *MockAuthRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockAuthRepository) ChangePassword(userId int64, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", userId, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockAuthRepositoryMockRecorder) ChangePassword(userId, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAuthRepository)(nil).ChangePassword), userId, passwordHash)
+}
+
+// ConsumeRecoveryCode mocks base method.
+func (m *MockAuthRepository) ConsumeRecoveryCode(userId int64, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeRecoveryCode", userId, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeRecoveryCode indicates an expected call of ConsumeRecoveryCode.
+func (mr *MockAuthRepositoryMockRecorder) ConsumeRecoveryCode(userId, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeRecoveryCode", reflect.TypeOf((*MockAuthRepository)(nil).ConsumeRecoveryCode), userId, codeHash)
+}
+
+// ConsumeUserToken mocks base method.
+func (m *MockAuthRepository) ConsumeUserToken(tokenHash, purpose string) (entity.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeUserToken", tokenHash, purpose)
+	ret0, _ := ret[0].(entity.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeUserToken indicates an expected call of ConsumeUserToken.
+func (mr *MockAuthRepositoryMockRecorder) ConsumeUserToken(tokenHash, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeUserToken", reflect.TypeOf((*MockAuthRepository)(nil).ConsumeUserToken), tokenHash, purpose)
+}
+
+// CreateAccessToken mocks base method.
+func (m *MockAuthRepository) CreateAccessToken(t *entity.AccessToken, tokenHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccessToken", t, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccessToken indicates an expected call of CreateAccessToken.
+func (mr *MockAuthRepositoryMockRecorder) CreateAccessToken(t, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccessToken", reflect.TypeOf((*MockAuthRepository)(nil).CreateAccessToken), t, tokenHash)
+}
+
 // CreateRefreshToken mocks base method.
-func (m *MockAuthRepository) CreateRefreshToken(userId int64, token string, expiresAt time.Time) error {
+func (m *MockAuthRepository) CreateRefreshToken(s *entity.Session, tokenHash string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRefreshToken", userId, token, expiresAt)
+	ret := m.ctrl.Call(m, "CreateRefreshToken", s, tokenHash)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateRefreshToken indicates an expected call of CreateRefreshToken.
-func (mr *MockAuthRepositoryMockRecorder) CreateRefreshToken(userId, token, expiresAt interface{}) *gomock.Call {
+func (mr *MockAuthRepositoryMockRecorder) CreateRefreshToken(s, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockAuthRepository)(nil).CreateRefreshToken), s, tokenHash)
+}
+
+// CreateUserToken mocks base method.
+func (m *MockAuthRepository) CreateUserToken(t *entity.UserToken, tokenHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserToken", t, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUserToken indicates an expected call of CreateUserToken.
+func (mr *MockAuthRepositoryMockRecorder) CreateUserToken(t, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserToken", reflect.TypeOf((*MockAuthRepository)(nil).CreateUserToken), t, tokenHash)
+}
+
+// DeleteAccessToken mocks base method.
+func (m *MockAuthRepository) DeleteAccessToken(id, userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccessToken", id, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccessToken indicates an expected call of DeleteAccessToken.
+func (mr *MockAuthRepositoryMockRecorder) DeleteAccessToken(id, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockAuthRepository)(nil).CreateRefreshToken), userId, token, expiresAt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccessToken", reflect.TypeOf((*MockAuthRepository)(nil).DeleteAccessToken), id, userId)
 }
 
 // DeleteRefreshToken mocks base method.
-func (m *MockAuthRepository) DeleteRefreshToken(token string) error {
+func (m *MockAuthRepository) DeleteRefreshToken(tokenHash string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteRefreshToken", token)
+	ret := m.ctrl.Call(m, "DeleteRefreshToken", tokenHash)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteRefreshToken indicates an expected call of DeleteRefreshToken.
-func (mr *MockAuthRepositoryMockRecorder) DeleteRefreshToken(token interface{}) *gomock.Call {
+func (mr *MockAuthRepositoryMockRecorder) DeleteRefreshToken(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshToken", reflect.TypeOf((*MockAuthRepository)(nil).DeleteRefreshToken), tokenHash)
+}
+
+// DeleteRefreshTokens mocks base method.
+func (m *MockAuthRepository) DeleteRefreshTokens(userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRefreshTokens", userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRefreshTokens indicates an expected call of DeleteRefreshTokens.
+func (mr *MockAuthRepositoryMockRecorder) DeleteRefreshTokens(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshTokens", reflect.TypeOf((*MockAuthRepository)(nil).DeleteRefreshTokens), userId)
+}
+
+// DeleteSession mocks base method.
+func (m *MockAuthRepository) DeleteSession(id, userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", id, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockAuthRepositoryMockRecorder) DeleteSession(id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockAuthRepository)(nil).DeleteSession), id, userId)
+}
+
+// DeleteTokenFamily mocks base method.
+func (m *MockAuthRepository) DeleteTokenFamily(familyId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTokenFamily", familyId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTokenFamily indicates an expected call of DeleteTokenFamily.
+func (mr *MockAuthRepositoryMockRecorder) DeleteTokenFamily(familyId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTokenFamily", reflect.TypeOf((*MockAuthRepository)(nil).DeleteTokenFamily), familyId)
+}
+
+// DeleteUser mocks base method.
+func (m *MockAuthRepository) DeleteUser(userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockAuthRepositoryMockRecorder) DeleteUser(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAuthRepository)(nil).DeleteUser), userId)
+}
+
+// DeleteUserTokens mocks base method.
+func (m *MockAuthRepository) DeleteUserTokens(userId int64, purpose string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTokens", userId, purpose)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTokens indicates an expected call of DeleteUserTokens.
+func (mr *MockAuthRepositoryMockRecorder) DeleteUserTokens(userId, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTokens", reflect.TypeOf((*MockAuthRepository)(nil).DeleteUserTokens), userId, purpose)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockAuthRepository) DisableTOTP(userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockAuthRepositoryMockRecorder) DisableTOTP(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockAuthRepository)(nil).DisableTOTP), userId)
+}
+
+// DisableUser mocks base method.
+func (m *MockAuthRepository) DisableUser(userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableUser", userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableUser indicates an expected call of DisableUser.
+func (mr *MockAuthRepositoryMockRecorder) DisableUser(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUser", reflect.TypeOf((*MockAuthRepository)(nil).DisableUser), userId)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockAuthRepository) EnableTOTP(userId, step int64, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", userId, step, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockAuthRepositoryMockRecorder) EnableTOTP(userId, step, codeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockAuthRepository)(nil).EnableTOTP), userId, step, codeHashes)
+}
+
+// EnableUser mocks base method.
+func (m *MockAuthRepository) EnableUser(userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUser", userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableUser indicates an expected call of EnableUser.
+func (mr *MockAuthRepositoryMockRecorder) EnableUser(userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshToken", reflect.TypeOf((*MockAuthRepository)(nil).DeleteRefreshToken), token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUser", reflect.TypeOf((*MockAuthRepository)(nil).EnableUser), userId)
 }
 
 // FindRefreshToken mocks base method.
-func (m *MockAuthRepository) FindRefreshToken(token string) (int64, time.Time, error) {
+func (m *MockAuthRepository) FindRefreshToken(tokenHash string) (entity.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindRefreshToken", token)
-	ret0, _ := ret[0].(int64)
-	ret1, _ := ret[1].(time.Time)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret := m.ctrl.Call(m, "FindRefreshToken", tokenHash)
+	ret0, _ := ret[0].(entity.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // FindRefreshToken indicates an expected call of FindRefreshToken.
-func (mr *MockAuthRepositoryMockRecorder) FindRefreshToken(token interface{}) *gomock.Call {
+func (mr *MockAuthRepositoryMockRecorder) FindRefreshToken(tokenHash interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRefreshToken", reflect.TypeOf((*MockAuthRepository)(nil).FindRefreshToken), token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRefreshToken", reflect.TypeOf((*MockAuthRepository)(nil).FindRefreshToken), tokenHash)
 }
 
-// SignIn mocks base method.
-func (m *MockAuthRepository) SignIn(username, passwordHash string) (int64, error) {
+// GetAccessTokens mocks base method.
+func (m *MockAuthRepository) GetAccessTokens(userId int64) ([]entity.AccessToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignIn", username, passwordHash)
-	ret0, _ := ret[0].(int64)
+	ret := m.ctrl.Call(m, "GetAccessTokens", userId)
+	ret0, _ := ret[0].([]entity.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessTokens indicates an expected call of GetAccessTokens.
+func (mr *MockAuthRepositoryMockRecorder) GetAccessTokens(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokens", reflect.TypeOf((*MockAuthRepository)(nil).GetAccessTokens), userId)
+}
+
+// GetByEmail mocks base method.
+func (m *MockAuthRepository) GetByEmail(email string) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", email)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockAuthRepositoryMockRecorder) GetByEmail(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockAuthRepository)(nil).GetByEmail), email)
+}
+
+// GetById mocks base method.
+func (m *MockAuthRepository) GetById(userId int64) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", userId)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockAuthRepositoryMockRecorder) GetById(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockAuthRepository)(nil).GetById), userId)
+}
+
+// GetByIdentity mocks base method.
+func (m *MockAuthRepository) GetByIdentity(provider, subject string) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIdentity", provider, subject)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIdentity indicates an expected call of GetByIdentity.
+func (mr *MockAuthRepositoryMockRecorder) GetByIdentity(provider, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIdentity", reflect.TypeOf((*MockAuthRepository)(nil).GetByIdentity), provider, subject)
+}
+
+// GetByUsername mocks base method.
+func (m *MockAuthRepository) GetByUsername(username string) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUsername", username)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUsername indicates an expected call of GetByUsername.
+func (mr *MockAuthRepositoryMockRecorder) GetByUsername(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUsername", reflect.TypeOf((*MockAuthRepository)(nil).GetByUsername), username)
+}
+
+// GetSessions mocks base method.
+func (m *MockAuthRepository) GetSessions(userId int64) ([]entity.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", userId)
+	ret0, _ := ret[0].([]entity.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockAuthRepositoryMockRecorder) GetSessions(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockAuthRepository)(nil).GetSessions), userId)
+}
+
+// LinkIdentity mocks base method.
+func (m *MockAuthRepository) LinkIdentity(i *entity.UserIdentity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkIdentity", i)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkIdentity indicates an expected call of LinkIdentity.
+func (mr *MockAuthRepositoryMockRecorder) LinkIdentity(i interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkIdentity", reflect.TypeOf((*MockAuthRepository)(nil).LinkIdentity), i)
+}
+
+// ListUsers mocks base method.
+func (m *MockAuthRepository) ListUsers(query dto.UserQueryDTO) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", query)
+	ret0, _ := ret[0].([]entity.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SignIn indicates an expected call of SignIn.
-func (mr *MockAuthRepositoryMockRecorder) SignIn(username, passwordHash interface{}) *gomock.Call {
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockAuthRepositoryMockRecorder) ListUsers(query interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockAuthRepository)(nil).SignIn), username, passwordHash)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockAuthRepository)(nil).ListUsers), query)
+}
+
+// MarkEmailVerified mocks base method.
+func (m *MockAuthRepository) MarkEmailVerified(userId int64, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailVerified", userId, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEmailVerified indicates an expected call of MarkEmailVerified.
+func (mr *MockAuthRepositoryMockRecorder) MarkEmailVerified(userId, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailVerified", reflect.TypeOf((*MockAuthRepository)(nil).MarkEmailVerified), userId, email)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockAuthRepository) RotateRefreshToken(parentId int64, s *entity.Session, tokenHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", parentId, s, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockAuthRepositoryMockRecorder) RotateRefreshToken(parentId, s, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockAuthRepository)(nil).RotateRefreshToken), parentId, s, tokenHash)
+}
+
+// SetTOTPSecret mocks base method.
+func (m *MockAuthRepository) SetTOTPSecret(userId int64, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", userId, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockAuthRepositoryMockRecorder) SetTOTPSecret(userId, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockAuthRepository)(nil).SetTOTPSecret), userId, secret)
 }
 
 // SignUp mocks base method.
@@ -205,3 +774,142 @@ func (mr *MockAuthRepositoryMockRecorder) SignUp(u interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockAuthRepository)(nil).SignUp), u)
 }
+
+// SignUpWithIdentity mocks base method.
+func (m *MockAuthRepository) SignUpWithIdentity(u *entity.User, i *entity.UserIdentity) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignUpWithIdentity", u, i)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignUpWithIdentity indicates an expected call of SignUpWithIdentity.
+func (mr *MockAuthRepositoryMockRecorder) SignUpWithIdentity(u, i interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUpWithIdentity", reflect.TypeOf((*MockAuthRepository)(nil).SignUpWithIdentity), u, i)
+}
+
+// UpdatePasswordHash mocks base method.
+func (m *MockAuthRepository) UpdatePasswordHash(userId int64, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordHash", userId, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordHash indicates an expected call of UpdatePasswordHash.
+func (mr *MockAuthRepositoryMockRecorder) UpdatePasswordHash(userId, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordHash", reflect.TypeOf((*MockAuthRepository)(nil).UpdatePasswordHash), userId, passwordHash)
+}
+
+// UpdateUser mocks base method.
+func (m *MockAuthRepository) UpdateUser(userId int64, input dto.UpdateUserDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", userId, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockAuthRepositoryMockRecorder) UpdateUser(userId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockAuthRepository)(nil).UpdateUser), userId, input)
+}
+
+// UseAccessToken mocks base method.
+func (m *MockAuthRepository) UseAccessToken(tokenHash string) (entity.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseAccessToken", tokenHash)
+	ret0, _ := ret[0].(entity.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseAccessToken indicates an expected call of UseAccessToken.
+func (mr *MockAuthRepositoryMockRecorder) UseAccessToken(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseAccessToken", reflect.TypeOf((*MockAuthRepository)(nil).UseAccessToken), tokenHash)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockAuthRepository) UseTOTPStep(userId, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", userId, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockAuthRepositoryMockRecorder) UseTOTPStep(userId, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockAuthRepository)(nil).UseTOTPStep), userId, step)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateEvent mocks base method.
+func (m *MockAuditRepository) CreateEvent(e *entity.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEvent", e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEvent indicates an expected call of CreateEvent.
+func (mr *MockAuditRepositoryMockRecorder) CreateEvent(e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEvent", reflect.TypeOf((*MockAuditRepository)(nil).CreateEvent), e)
+}
+
+// DeleteEventsBefore mocks base method.
+func (m *MockAuditRepository) DeleteEventsBefore(t time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEventsBefore", t)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteEventsBefore indicates an expected call of DeleteEventsBefore.
+func (mr *MockAuditRepositoryMockRecorder) DeleteEventsBefore(t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEventsBefore", reflect.TypeOf((*MockAuditRepository)(nil).DeleteEventsBefore), t)
+}
+
+// ListEvents mocks base method.
+func (m *MockAuditRepository) ListEvents(query dto.AuditQueryDTO) ([]entity.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEvents", query)
+	ret0, _ := ret[0].([]entity.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEvents indicates an expected call of ListEvents.
+func (mr *MockAuditRepositoryMockRecorder) ListEvents(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEvents", reflect.TypeOf((*MockAuditRepository)(nil).ListEvents), query)
+}
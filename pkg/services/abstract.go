@@ -3,6 +3,8 @@ package services
 import (
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/lockout"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/mailer"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/implserv"
 )
@@ -11,29 +13,86 @@ import (
 
 type ProjectService interface {
 	Create(p dto.ProjectDTO, userId int64) (int64, error)
-	GetById(id int64, userId int64) (dto.ProjectDTO, error)
-	GetAll(userId int64) ([]dto.ProjectDTO, error)
+	GetById(id int64, userId int64) (dto.ProjectResponseDTO, error)
+	GetAll(userId int64) ([]dto.ProjectResponseDTO, error)
+	List(userId int64, query dto.ProjectQueryDTO) (dto.ProjectPageDTO, error)
 	UpdateById(id int64, p dto.UpdateProjectDTO, userId int64) error
 	DeleteById(id int64, userId int64) error
+	GetMembers(projectId int64, userId int64) ([]dto.ProjectMemberDTO, error)
+	AddMember(projectId int64, userId int64, input dto.AddProjectMemberDTO) (dto.ProjectMemberDTO, error)
+	UpdateMemberRole(projectId int64, userId int64, memberId int64, input dto.UpdateProjectMemberDTO) error
+	RemoveMember(projectId int64, userId int64, memberId int64) error
+	TransferOwnership(projectId int64, userId int64, input dto.TransferProjectDTO) error
+	MemberIds(projectId int64) ([]int64, error)
+}
+
+type TaskService interface {
+	Create(projectId int64, t dto.TaskDTO, userId int64) (int64, error)
+	GetById(projectId int64, id int64, userId int64) (dto.TaskResponseDTO, error)
+	List(projectId int64, userId int64, query dto.TaskQueryDTO) ([]dto.TaskResponseDTO, error)
+	UpdateById(projectId int64, id int64, t dto.UpdateTaskDTO, userId int64) error
+	DeleteById(projectId int64, id int64, userId int64) error
 }
 
 type AuthService interface {
-	SignUp(su dto.SignUpDTO) (int64, error)
-	SignIn(si dto.SignInDTO) (int64, error)
-	HashPassword(password string) string
-	GenerateTokens(id int64) (string, string, error)
-	UpdateTokens(rt string) (string, string, error)
-	ParseToken(input string) (int64, error)
+	SignUp(su dto.SignUpDTO, client dto.ClientInfo) (int64, error)
+	SignIn(si dto.SignInDTO, client dto.ClientInfo) (int64, string, error)
+	SignInTwoFactor(input dto.SignInTwoFactorDTO, client dto.ClientInfo) (int64, error)
+	StartOIDC(provider string) (dto.OIDCStartDTO, error)
+	SignInOIDC(provider string, cb dto.OIDCCallbackDTO, flow string, client dto.ClientInfo) (int64, string, error)
+	HashPassword(password string) (string, error)
+	GenerateTokens(id int64, client dto.ClientInfo) (string, string, error)
+	UpdateTokens(rt string, client dto.ClientInfo) (string, string, error)
+	SignOut(rt string) error
+	SignOutAll(userId int64) error
+	GetSessions(userId int64, rt string) ([]dto.SessionDTO, error)
+	DeleteSession(id int64, userId int64) error
+	ParseToken(input string) (dto.TokenClaimsDTO, error)
+	JWKS() dto.JWKSetDTO
+	VerifyEmail(token string) error
+	ResendVerification(email string) error
+	ForgotPassword(email string) error
+	ResetPassword(rp dto.ResetPasswordDTO, client dto.ClientInfo) (int64, error)
+	ChangePassword(userId int64, cp dto.ChangePasswordDTO, client dto.ClientInfo) error
+	GetUser(userId int64) (dto.UserResponseDTO, error)
+	UpdateUser(userId int64, input dto.UpdateUserDTO) (dto.UserResponseDTO, error)
+	DeleteUser(userId int64, password string) error
+	EnrollTOTP(userId int64) (dto.TOTPEnrollmentDTO, error)
+	ConfirmTOTP(userId int64, code string) (dto.RecoveryCodesDTO, error)
+	DisableTOTP(userId int64, password string) error
+	CreateAccessToken(userId int64, input dto.CreateAccessTokenDTO) (dto.CreatedAccessTokenDTO, error)
+	GetAccessTokens(userId int64) ([]dto.AccessTokenDTO, error)
+	DeleteAccessToken(id int64, userId int64) error
+	ParseAccessToken(token string) (int64, []string, error)
+}
+
+type AdminService interface {
+	ListUsers(query dto.UserQueryDTO) (dto.UserPageDTO, error)
+	GetUserById(userId int64) (dto.AdminUserDTO, error)
+	DisableUser(adminId int64, userId int64) error
+	EnableUser(userId int64) error
+	SignOutUser(userId int64) error
+	ListAuditEvents(query dto.AuditQueryDTO) (dto.AuditPageDTO, error)
 }
 
 type AbstractService struct {
 	ProjectService
+	TaskService
 	AuthService
+	AdminService
 }
 
-func NewService(repo *repositories.AbstractRepository, cfg *config.Config) *AbstractService {
+func NewService(repo *repositories.AbstractRepository, mailer mailer.Mailer, attempts lockout.Store,
+	cfg *config.Config) (*AbstractService, error) {
+	auth, err := implserv.NewAuthService(repo.AuthRepository, mailer, attempts, repo.AuditRepository, cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return &AbstractService{
 		ProjectService: implserv.NewProjectService(repo.ProjectRepository),
-		AuthService:    implserv.NewAuthService(repo.AuthRepository, cfg),
-	}
+		TaskService:    implserv.NewTaskService(repo.TaskRepository, repo.ProjectRepository),
+		AuthService:    auth,
+		AdminService:   implserv.NewAdminService(repo.AuthRepository, repo.AuditRepository),
+	}, nil
 }
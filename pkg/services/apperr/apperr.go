@@ -0,0 +1,77 @@
+// Package apperr defines the errors the services return for failures
+// that the client caused and can act upon.
+package apperr
+
+import (
+	"errors"
+	"time"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindTooManyRequests
+)
+
+// Error is a domain error. Code is a stable machine-readable identifier,
+// Message is a human-readable description safe to show to the client.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	// RetryAfter tells the client when to try again, it is set for KindTooManyRequests
+	RetryAfter time.Duration
+}
+
+// FieldError describes a single rule that a field of the input failed.
+type FieldError struct {
+	Field  string `json:"field"`
+	Rule   string `json:"rule"`
+	Detail string `json:"detail"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message, Fields: fields}
+}
+
+func Validation(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Fields: fields}
+}
+
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+func TooManyRequests(code, message string, retryAfter time.Duration) *Error {
+	return &Error{Kind: KindTooManyRequests, Code: code, Message: message, RetryAfter: retryAfter}
+}
+
+// KindOf returns the kind of the domain error in the chain of err
+// or KindInternal if there is none.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	return KindInternal
+}
@@ -0,0 +1,75 @@
+package implserv
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+)
+
+// CreateAccessToken issues a personal access token. Only its hash is stored,
+// so the token is returned once.
+func (service *AuthServiceImpl) CreateAccessToken(userId int64, input dto.CreateAccessTokenDTO) (dto.CreatedAccessTokenDTO, error) {
+	token, err := newRandomToken()
+	if err != nil {
+		return dto.CreatedAccessTokenDTO{}, err
+	}
+	token = dto.AccessTokenPrefix + token
+
+	t := entity.AccessToken{
+		UserId:    userId,
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		ExpiresAt: time.Now().AddDate(0, 0, input.ExpiresInDays),
+	}
+
+	if err := service.repo.CreateAccessToken(&t, service.hashToken(token)); err != nil {
+		return dto.CreatedAccessTokenDTO{}, err
+	}
+
+	return dto.CreatedAccessTokenDTO{AccessTokenDTO: t.ToDTO(), Token: token}, nil
+}
+
+func (service *AuthServiceImpl) GetAccessTokens(userId int64) ([]dto.AccessTokenDTO, error) {
+	tokens, err := service.repo.GetAccessTokens(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.AccessTokenDTO, len(tokens))
+	for i, t := range tokens {
+		res[i] = t.ToDTO()
+	}
+
+	return res, nil
+}
+
+func (service *AuthServiceImpl) DeleteAccessToken(id int64, userId int64) error {
+	if err := service.repo.DeleteAccessToken(id, userId); err != nil {
+		if err == sql.ErrNoRows {
+			return errAccessTokenNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ParseAccessToken returns the id of the user and the scopes of a personal access token.
+func (service *AuthServiceImpl) ParseAccessToken(token string) (int64, []string, error) {
+	if !strings.HasPrefix(token, dto.AccessTokenPrefix) {
+		return 0, nil, errInvalidAccessToken
+	}
+
+	t, err := service.repo.UseAccessToken(service.hashToken(token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil, errInvalidAccessToken
+		}
+		return 0, nil, err
+	}
+
+	return t.UserId, t.Scopes, nil
+}
@@ -0,0 +1,139 @@
+package implserv
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	mock_repositories "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthService_CreateAccessToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	input := dto.CreateAccessTokenDTO{Name: "ci", Scopes: []string{dto.ScopeProjectsRead}, ExpiresInDays: 30}
+
+	var tokenHash string
+	repo := mock_repositories.NewMockAuthRepository(ctrl)
+	repo.EXPECT().CreateAccessToken(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(token *entity.AccessToken, hash string) error {
+			assert.Equal(t, token.UserId, int64(1))
+			assert.Equal(t, token.Name, "ci")
+			assert.WithinDuration(t, token.ExpiresAt, time.Now().AddDate(0, 0, 30), time.Minute)
+
+			tokenHash = hash
+			token.Id, token.CreatedAt = 3, createdAt
+			return nil
+		})
+
+	serv := newTestAuthService(t, repo, &config.Config{Auth: config.Auth{RefreshSecret: "refresh_secret"}})
+
+	got, err := serv.CreateAccessToken(1, input)
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(got.Token, dto.AccessTokenPrefix))
+	assert.Equal(t, tokenHash, testTokenHash(got.Token))
+	assert.Equal(t, got.Id, int64(3))
+	assert.Equal(t, got.CreatedAt, createdAt)
+	assert.Equal(t, got.Scopes, []string{dto.ScopeProjectsRead})
+}
+
+func TestAuthService_DeleteAccessToken(t *testing.T) {
+	cases := []struct {
+		name        string
+		repoErr     error
+		expectedErr error
+	}{
+		{
+			name: "OK",
+		},
+		{
+			name:        "Not found",
+			repoErr:     sql.ErrNoRows,
+			expectedErr: errAccessTokenNotFound,
+		},
+		{
+			name:        "Repository error",
+			repoErr:     errSome,
+			expectedErr: errSome,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			repo.EXPECT().DeleteAccessToken(int64(3), int64(1)).Return(c.repoErr)
+
+			err := newTestAuthService(t, repo, &config.Config{}).DeleteAccessToken(3, 1)
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
+
+func TestAuthService_ParseAccessToken(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository)
+
+	cases := []struct {
+		name           string
+		token          string
+		mockBehavior   mockBehavior
+		expectedId     int64
+		expectedScopes []string
+		expectedErr    error
+	}{
+		{
+			name:  "OK",
+			token: "pat_token",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().UseAccessToken(testTokenHash("pat_token")).
+					Return(entity.AccessToken{UserId: 1, Scopes: []string{dto.ScopeProjectsRead}}, nil)
+			},
+			expectedId:     1,
+			expectedScopes: []string{dto.ScopeProjectsRead},
+		},
+		{
+			name:         "Without prefix",
+			token:        "token",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {},
+			expectedErr:  errInvalidAccessToken,
+		},
+		{
+			name:  "Unknown or expired",
+			token: "pat_token",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().UseAccessToken(testTokenHash("pat_token")).Return(entity.AccessToken{}, sql.ErrNoRows)
+			},
+			expectedErr: errInvalidAccessToken,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			c.mockBehavior(repo)
+
+			serv := newTestAuthService(t, repo, &config.Config{Auth: config.Auth{RefreshSecret: "refresh_secret"}})
+
+			id, scopes, err := serv.ParseAccessToken(c.token)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, id, c.expectedId)
+			assert.Equal(t, scopes, c.expectedScopes)
+		})
+	}
+}
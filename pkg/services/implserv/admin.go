@@ -0,0 +1,121 @@
+package implserv
+
+import (
+	"database/sql"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories"
+	"github.com/sirupsen/logrus"
+)
+
+type AdminServiceImpl struct {
+	repo     repositories.AuthRepository
+	auditLog repositories.AuditRepository
+}
+
+func NewAdminService(repo repositories.AuthRepository, auditLog repositories.AuditRepository) *AdminServiceImpl {
+	return &AdminServiceImpl{repo: repo, auditLog: auditLog}
+}
+
+func (service *AdminServiceImpl) ListUsers(query dto.UserQueryDTO) (dto.UserPageDTO, error) {
+	limit := query.Limit
+	// one extra row tells whether there is a next page
+	query.Limit++
+
+	users, err := service.repo.ListUsers(query)
+	if err != nil {
+		return dto.UserPageDTO{}, err
+	}
+
+	var next string
+	if len(users) > limit {
+		users = users[:limit]
+		next = dto.EncodeIdCursor(int64(users[limit-1].Id))
+	}
+
+	items := make([]dto.AdminUserDTO, len(users))
+	for i, u := range users {
+		items[i] = u.ToAdminDTO()
+	}
+
+	return dto.UserPageDTO{Items: items, NextCursor: next}, nil
+}
+
+func (service *AdminServiceImpl) GetUserById(userId int64) (dto.AdminUserDTO, error) {
+	user, err := service.repo.GetById(userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return dto.AdminUserDTO{}, errUserNotFound
+		}
+		return dto.AdminUserDTO{}, err
+	}
+
+	return user.ToAdminDTO(), nil
+}
+
+// DisableUser blocks sign in and refresh of the user and revokes the sessions.
+// The handler revokes the access tokens already issued to the user as well.
+func (service *AdminServiceImpl) DisableUser(adminId int64, userId int64) error {
+	if adminId == userId {
+		return errDisableSelf
+	}
+
+	if err := service.repo.DisableUser(userId); err != nil {
+		if err == sql.ErrNoRows {
+			return errUserNotFound
+		}
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":    "user_disabled",
+		"user_id":  userId,
+		"admin_id": adminId,
+	}).Info("user disabled by an admin")
+
+	return nil
+}
+
+func (service *AdminServiceImpl) EnableUser(userId int64) error {
+	if err := service.repo.EnableUser(userId); err != nil {
+		if err == sql.ErrNoRows {
+			return errUserNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// SignOutUser revokes every session of the user.
+func (service *AdminServiceImpl) SignOutUser(userId int64) error {
+	if _, err := service.GetUserById(userId); err != nil {
+		return err
+	}
+
+	return service.repo.DeleteRefreshTokens(userId)
+}
+
+func (service *AdminServiceImpl) ListAuditEvents(query dto.AuditQueryDTO) (dto.AuditPageDTO, error) {
+	limit := query.Limit
+	// one extra row tells whether there is a next page
+	query.Limit++
+
+	events, err := service.auditLog.ListEvents(query)
+	if err != nil {
+		return dto.AuditPageDTO{}, err
+	}
+
+	var next string
+	if len(events) > limit {
+		events = events[:limit]
+		next = dto.EncodeIdCursor(events[limit-1].Id)
+	}
+
+	items := make([]dto.AuditEventDTO, len(events))
+	for i, e := range events {
+		items[i] = e.ToDTO()
+	}
+
+	return dto.AuditPageDTO{Items: items, NextCursor: next}, nil
+}
@@ -0,0 +1,158 @@
+package implserv
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	mock_repositories "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminService_ListUsers(t *testing.T) {
+	disabledAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+
+	cases := []struct {
+		name     string
+		users    []entity.User
+		expected dto.UserPageDTO
+	}{
+		{
+			name:  "Last page",
+			users: []entity.User{{Id: 1, Role: dto.RoleAdmin}, {Id: 2, Role: dto.RoleUser, DisabledAt: &disabledAt}},
+			expected: dto.UserPageDTO{Items: []dto.AdminUserDTO{
+				{UserResponseDTO: dto.UserResponseDTO{Id: 1, Role: dto.RoleAdmin}},
+				{UserResponseDTO: dto.UserResponseDTO{Id: 2, Role: dto.RoleUser}, DisabledAt: &disabledAt},
+			}},
+		},
+		{
+			name:  "Next page",
+			users: []entity.User{{Id: 1}, {Id: 2}, {Id: 3}},
+			expected: dto.UserPageDTO{
+				Items: []dto.AdminUserDTO{
+					{UserResponseDTO: dto.UserResponseDTO{Id: 1}},
+					{UserResponseDTO: dto.UserResponseDTO{Id: 2}},
+				},
+				NextCursor: dto.EncodeIdCursor(2),
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			repo.EXPECT().ListUsers(dto.UserQueryDTO{Limit: 3, Search: "name"}).Return(c.users, nil)
+
+			got, err := NewAdminService(repo, nil).ListUsers(dto.UserQueryDTO{Limit: 2, Search: "name"})
+
+			assert.NoError(t, err)
+			assert.Equal(t, got, c.expected)
+		})
+	}
+}
+
+func TestAdminService_DisableUser(t *testing.T) {
+	cases := []struct {
+		name         string
+		adminId      int64
+		mockBehavior func(s *mock_repositories.MockAuthRepository)
+		expectedErr  error
+	}{
+		{
+			name:    "OK",
+			adminId: 1,
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().DisableUser(int64(2)).Return(nil)
+			},
+		},
+		{
+			name:         "Own account",
+			adminId:      2,
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {},
+			expectedErr:  errDisableSelf,
+		},
+		{
+			name:    "Not found",
+			adminId: 1,
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().DisableUser(int64(2)).Return(sql.ErrNoRows)
+			},
+			expectedErr: errUserNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			c.mockBehavior(repo)
+
+			err := NewAdminService(repo, nil).DisableUser(c.adminId, 2)
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
+
+func TestAdminService_EnableUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_repositories.NewMockAuthRepository(ctrl)
+	repo.EXPECT().EnableUser(int64(2)).Return(nil)
+	repo.EXPECT().EnableUser(int64(3)).Return(sql.ErrNoRows)
+
+	serv := NewAdminService(repo, nil)
+
+	assert.NoError(t, serv.EnableUser(2))
+	assert.Equal(t, serv.EnableUser(3), errUserNotFound)
+}
+
+func TestAdminService_SignOutUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_repositories.NewMockAuthRepository(ctrl)
+	repo.EXPECT().GetById(int64(2)).Return(entity.User{Id: 2}, nil)
+	repo.EXPECT().DeleteRefreshTokens(int64(2)).Return(nil)
+	repo.EXPECT().GetById(int64(3)).Return(entity.User{}, sql.ErrNoRows)
+
+	serv := NewAdminService(repo, nil)
+
+	assert.NoError(t, serv.SignOutUser(2))
+	assert.Equal(t, serv.SignOutUser(3), errUserNotFound)
+}
+
+func TestAdminService_ListAuditEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	userId := int64(1)
+
+	auditLog := mock_repositories.NewMockAuditRepository(ctrl)
+	auditLog.EXPECT().ListEvents(dto.AuditQueryDTO{Limit: 3, Event: entity.AuditSignIn}).Return([]entity.AuditEvent{
+		{Id: 9, Event: entity.AuditSignIn, UserId: &userId, IP: "192.0.2.1", CreatedAt: createdAt},
+		{Id: 7, Event: entity.AuditSignIn, CreatedAt: createdAt},
+		{Id: 4, Event: entity.AuditSignIn, CreatedAt: createdAt},
+	}, nil)
+
+	got, err := NewAdminService(nil, auditLog).ListAuditEvents(dto.AuditQueryDTO{Limit: 2, Event: entity.AuditSignIn})
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, dto.AuditPageDTO{
+		Items: []dto.AuditEventDTO{
+			{Id: 9, Event: entity.AuditSignIn, UserId: &userId, IP: "192.0.2.1", CreatedAt: createdAt},
+			{Id: 7, Event: entity.AuditSignIn, CreatedAt: createdAt},
+		},
+		NextCursor: dto.EncodeIdCursor(7),
+	})
+}
@@ -0,0 +1,83 @@
+package implserv
+
+import (
+	"context"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultAuditPurgeInterval = time.Hour
+
+// audit records the event of the user in the audit log, userId is 0 if the user is unknown.
+// A failure of the audit log must not change the outcome of the operation.
+func (service *AuthServiceImpl) audit(event string, userId int64, client dto.ClientInfo, detail string) {
+	e := entity.AuditEvent{
+		Event:     event,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		RequestId: client.RequestId,
+		Detail:    detail,
+	}
+	if userId != 0 {
+		e.UserId = &userId
+	}
+
+	if err := service.auditLog.CreateEvent(&e); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"event":   event,
+			"user_id": userId,
+			"error":   err,
+		}).Error("failed to record audit event")
+	}
+}
+
+// AuditRetention deletes the audit events older than the retention period.
+type AuditRetention struct {
+	repo      repositories.AuditRepository
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewAuditRetention(repo repositories.AuditRepository, retention, interval time.Duration) *AuditRetention {
+	return &AuditRetention{
+		repo:      repo,
+		retention: retention,
+		interval:  durationOr(interval, defaultAuditPurgeInterval),
+	}
+}
+
+// Purge deletes the events that expired at now and returns how many were deleted.
+func (r *AuditRetention) Purge(now time.Time) (int64, error) {
+	if r.retention <= 0 {
+		return 0, nil
+	}
+
+	return r.repo.DeleteEventsBefore(now.Add(-r.retention))
+}
+
+// Run purges expired events every interval until the context is done.
+// It returns at once if events are kept forever.
+func (r *AuditRetention) Run(ctx context.Context) {
+	if r.retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Purge(time.Now()); err != nil {
+			logrus.WithField("error", err).Error("failed to purge audit events")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
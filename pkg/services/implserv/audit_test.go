@@ -0,0 +1,217 @@
+package implserv
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	mock_repositories "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testAuditClient = dto.ClientInfo{UserAgent: "curl", IP: "192.0.2.1", RequestId: "request"}
+
+// recordAuditEvents makes the service collect its audit events into the returned slice.
+func recordAuditEvents(ctrl *gomock.Controller, serv *AuthServiceImpl) *[]entity.AuditEvent {
+	events := new([]entity.AuditEvent)
+
+	auditLog := mock_repositories.NewMockAuditRepository(ctrl)
+	auditLog.EXPECT().CreateEvent(gomock.Any()).
+		DoAndReturn(func(e *entity.AuditEvent) error {
+			*events = append(*events, *e)
+			return nil
+		}).AnyTimes()
+	serv.auditLog = auditLog
+
+	return events
+}
+
+func TestAuthService_audit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userId := int64(1)
+
+	auditLog := mock_repositories.NewMockAuditRepository(ctrl)
+	auditLog.EXPECT().CreateEvent(&entity.AuditEvent{
+		Event: entity.AuditSignIn, UserId: &userId, IP: "192.0.2.1", UserAgent: "curl", RequestId: "request",
+	}).Return(nil)
+	auditLog.EXPECT().CreateEvent(&entity.AuditEvent{
+		Event: entity.AuditSignInFailed, IP: "192.0.2.1", UserAgent: "curl", RequestId: "request", Detail: "unknown user",
+	}).Return(errSome)
+
+	serv := newTestAuthService(t, nil, &config.Config{})
+	serv.auditLog = auditLog
+
+	serv.audit(entity.AuditSignIn, 1, testAuditClient, "")
+	// a failure of the audit log is only logged
+	serv.audit(entity.AuditSignInFailed, 0, testAuditClient, "unknown user")
+}
+
+func TestAuthService_SignInAudit(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository, hash string)
+
+	cases := []struct {
+		name           string
+		password       string
+		mockBehavior   mockBehavior
+		expectedEvents []string
+		expectedDetail string
+		expectedUserId int64
+	}{
+		{
+			name:     "Invalid password",
+			password: "invalid_password",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetByUsername("username").Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+			},
+			expectedEvents: []string{entity.AuditSignInFailed, entity.AuditSignInLocked, entity.AuditSignInLocked},
+			expectedDetail: "invalid password",
+			expectedUserId: 1,
+		},
+		{
+			name:     "Unknown user",
+			password: "password",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetByUsername("username").Return(entity.User{}, sql.ErrNoRows)
+			},
+			expectedEvents: []string{entity.AuditSignInFailed, entity.AuditSignInLocked, entity.AuditSignInLocked},
+			expectedDetail: "unknown user",
+		},
+		{
+			name:     "Disabled account",
+			password: "password",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				disabledAt := time.Now()
+				s.EXPECT().GetByUsername("username").
+					Return(entity.User{Id: 1, PasswordHash: hash, DisabledAt: &disabledAt}, nil)
+			},
+			expectedEvents: []string{entity.AuditSignInFailed},
+			expectedDetail: "account disabled",
+			expectedUserId: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			// the first failure locks the account and the client IP
+			serv := newTestAuthService(t, repo, &config.Config{
+				Password: testPasswordConfig,
+				SignIn:   config.SignIn{Lockout: config.Lockout{Threshold: 1}},
+			})
+			events := recordAuditEvents(ctrl, serv)
+
+			hash, err := serv.HashPassword("password")
+			assert.NoError(t, err)
+			c.mockBehavior(repo, hash)
+
+			_, _, err = serv.SignIn(dto.SignInDTO{Username: "username", Password: c.password}, testAuditClient)
+			assert.Error(t, err)
+
+			got := make([]string, len(*events))
+			for i, e := range *events {
+				got[i] = e.Event
+			}
+			assert.Equal(t, got, c.expectedEvents)
+
+			failed := (*events)[0]
+			assert.Equal(t, failed.Detail, c.expectedDetail)
+			assert.Equal(t, failed.IP, testAuditClient.IP)
+			assert.Equal(t, failed.UserAgent, testAuditClient.UserAgent)
+			assert.Equal(t, failed.RequestId, testAuditClient.RequestId)
+			if c.expectedUserId == 0 {
+				assert.Nil(t, failed.UserId)
+			} else {
+				assert.Equal(t, *failed.UserId, c.expectedUserId)
+			}
+		})
+	}
+}
+
+func TestAuthService_UpdateTokensAudit(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository, token string)
+
+	rotatedAt := time.Now()
+
+	cases := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		expectedEvent string
+	}{
+		{
+			name: "Refreshed",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, token string) {
+				s.EXPECT().FindRefreshToken(token).
+					Return(entity.Session{Id: 2, UserId: 1, FamilyId: 3, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1}, nil)
+				s.EXPECT().RotateRefreshToken(int64(2), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedEvent: entity.AuditTokenRefreshed,
+		},
+		{
+			name: "Reused",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, token string) {
+				s.EXPECT().FindRefreshToken(token).
+					Return(entity.Session{Id: 2, UserId: 1, FamilyId: 3, RotatedAt: &rotatedAt}, nil)
+				s.EXPECT().DeleteTokenFamily(int64(3)).Return(nil)
+			},
+			expectedEvent: entity.AuditRefreshTokenReuse,
+		},
+		{
+			name: "Expired",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, token string) {
+				s.EXPECT().FindRefreshToken(token).
+					Return(entity.Session{Id: 2, UserId: 1, FamilyId: 3, ExpiresAt: time.Now().Add(-time.Hour)}, nil)
+				s.EXPECT().DeleteRefreshToken(token).Return(nil)
+			},
+			expectedEvent: entity.AuditRefreshRejected,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			serv := newTestAuthService(t, repo, &config.Config{Auth: config.Auth{RefreshSecret: "refresh_secret"}})
+			events := recordAuditEvents(ctrl, serv)
+			c.mockBehavior(repo, testTokenHash("token"))
+
+			_, _, _ = serv.UpdateTokens("token", testAuditClient)
+
+			assert.Len(t, *events, 1)
+			assert.Equal(t, (*events)[0].Event, c.expectedEvent)
+			assert.Equal(t, *(*events)[0].UserId, int64(1))
+		})
+	}
+}
+
+func TestAuditRetention_Purge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, time.December, 31, 0, 0, 0, 0, time.Local)
+
+	repo := mock_repositories.NewMockAuditRepository(ctrl)
+	repo.EXPECT().DeleteEventsBefore(time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)).Return(int64(4), nil)
+
+	got, err := NewAuditRetention(repo, 30*24*time.Hour, 0).Purge(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, int64(4))
+
+	// events are kept forever without a retention
+	got, err = NewAuditRetention(repo, 0, 0).Purge(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, int64(0))
+}
@@ -1,138 +1,531 @@
 package implserv
 
 import (
+	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/lockout"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/mailer"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/oidc"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
 )
 
 type AuthServiceImpl struct {
-	repo repositories.AuthRepository
+	repo   repositories.AuthRepository
+	mailer mailer.Mailer
+	// attempts counts failed sign ins to lock out guessing
+	attempts lockout.Store
+	// auditLog records authentication events
+	auditLog repositories.AuditRepository
+	// oidc are the OpenID Connect providers by name
+	oidc map[string]*oidc.Provider
 	cfg  authConfig
 }
 
 type authConfig struct {
-	salt      string
-	signature string
-	jwt       time.Duration
-	refresh   time.Duration
+	salt          string
+	keys          *keySet
+	refreshSecret []byte
+	jwt           time.Duration
+	refresh       time.Duration
+	verification  time.Duration
+	passwordReset time.Duration
+	twoFactor     time.Duration
+	oidcFlow      time.Duration
+	password      passwordParams
+	policy        passwordPolicy
+	// requireVerifiedEmail blocks sign in until the email of the user is verified
+	requireVerifiedEmail bool
+	// baseURL is the address of the client links in emails point to
+	baseURL string
+	totp    *totpCipher
+	// oidcSealer encrypts the state of OpenID Connect sign ins kept by the client
+	oidcSealer cipher.AEAD
+	totpIssuer string
+	lockout    lockoutPolicy
 }
 
-func NewAuthService(repo repositories.AuthRepository, config *config.Config) *AuthServiceImpl {
+func NewAuthService(repo repositories.AuthRepository, mailer mailer.Mailer, attempts lockout.Store,
+	auditLog repositories.AuditRepository, config *config.Config) (*AuthServiceImpl, error) {
 	auth := config.Auth
+
+	keys, err := newKeySet(config.JWT, auth.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	totp, err := newTOTPCipher([]byte(config.TwoFactor.Key.EncryptionKey))
+	if err != nil {
+		return nil, err
+	}
+
+	providers, err := oidc.New(config.OIDC, &http.Client{Timeout: oidcTimeout})
+	if err != nil {
+		return nil, err
+	}
+
+	oidcSealer, err := newAEAD(deriveKey([]byte(auth.RefreshSecret), "oidc_flow"))
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := newPasswordPolicy(config.Password.Policy)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := config.TwoFactor.Issuer
+	if issuer == "" {
+		issuer = defaultTwoFactorIssuer
+	}
+
 	return &AuthServiceImpl{
-		repo: repo,
+		repo:     repo,
+		mailer:   mailer,
+		attempts: attempts,
+		auditLog: auditLog,
+		oidc:     providers,
 		cfg: authConfig{
-			salt:      auth.Salt,
-			signature: auth.Signature,
-			jwt:       auth.JWT,
-			refresh:   auth.Refresh,
+			salt:                 auth.Salt,
+			keys:                 keys,
+			refreshSecret:        []byte(auth.RefreshSecret),
+			jwt:                  auth.JWT,
+			refresh:              auth.Refresh,
+			verification:         durationOr(auth.Verification, defaultVerificationTTL),
+			passwordReset:        durationOr(auth.PasswordReset, defaultPasswordResetTTL),
+			twoFactor:            durationOr(auth.TwoFactor, defaultTwoFactorTTL),
+			oidcFlow:             durationOr(auth.OIDC, defaultOIDCFlowTTL),
+			password:             newPasswordParams(config.Password),
+			policy:               policy,
+			requireVerifiedEmail: config.SignIn.RequireVerifiedEmail,
+			baseURL:              config.Mailer.BaseURL,
+			totp:                 totp,
+			oidcSealer:           oidcSealer,
+			totpIssuer:           issuer,
+			lockout:              newLockoutPolicy(config.SignIn.Lockout),
 		},
+	}, nil
+}
+
+func durationOr(d, fallback time.Duration) time.Duration {
+	if d == 0 {
+		return fallback
+	}
+
+	return d
+}
+
+func newPasswordParams(cfg config.Password) passwordParams {
+	p := defaultPasswordParams
+	if cfg.Memory != 0 {
+		p.memory = cfg.Memory
+	}
+	if cfg.Iterations != 0 {
+		p.iterations = cfg.Iterations
+	}
+	if cfg.Parallelism != 0 {
+		p.parallelism = cfg.Parallelism
+	}
+	if cfg.SaltLength != 0 {
+		p.saltLength = cfg.SaltLength
+	}
+	if cfg.KeyLength != 0 {
+		p.keyLength = cfg.KeyLength
+	}
+
+	return p
+}
+
+func (service *AuthServiceImpl) SignUp(su dto.SignUpDTO, client dto.ClientInfo) (int64, error) {
+	if err := service.cfg.policy.check("password", su.Password, su.Username, su.Email); err != nil {
+		return 0, err
+	}
+
+	passwordHash, err := service.HashPassword(su.Password)
+	if err != nil {
+		return 0, err
+	}
+
+	user := entity.FromSignUpDTO(su, passwordHash)
+
+	id, err := service.repo.SignUp(user)
+	if err != nil {
+		return 0, constraintError(err, userConstraintErrors)
+	}
+	service.audit(entity.AuditSignUp, id, client, "")
+
+	// the account exists even if the email could not be sent, a new one can be requested
+	if err := service.sendVerification(id, user.Email); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id": id,
+			"error":   err,
+		}).Error("failed to send verification email")
+	}
+
+	return id, nil
+}
+
+// SignIn returns the id of the user whose credentials match. The user is found
+// by the email or the username, both case-insensitively. If two-factor
+// authentication is enabled, the id is 0 and a challenge token is returned
+// instead, which SignInTwoFactor exchanges together with a code.
+// Repeated failures lock the account and the client IP out for a while.
+func (service *AuthServiceImpl) SignIn(si dto.SignInDTO, client dto.ClientInfo) (int64, string, error) {
+	user, lookupErr := service.signInUser(si)
+	if lookupErr != nil && lookupErr != sql.ErrNoRows {
+		return 0, "", lookupErr
+	}
+
+	userId := int64(user.Id)
+	keys := signInKeys(user, si, client.IP)
+	if err := service.checkLockout(keys); err != nil {
+		service.audit(entity.AuditSignInFailed, userId, client, "locked")
+		return 0, "", err
+	}
+
+	if lookupErr == sql.ErrNoRows {
+		// keep the response time close to the one of an existing user
+		service.cfg.password.hash(si.Password)
+		service.audit(entity.AuditSignInFailed, 0, client, "unknown user")
+		service.recordFailure(keys, 0, client)
+		return 0, "", errInvalidCredentials
+	}
+
+	ok, rehash, err := service.cfg.password.verify(si.Password, user.PasswordHash, service.cfg.salt)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if !ok {
+		service.audit(entity.AuditSignInFailed, userId, client, "invalid password")
+		service.recordFailure(keys, userId, client)
+		return 0, "", errInvalidCredentials
 	}
+
+	if user.DisabledAt != nil {
+		service.audit(entity.AuditSignInFailed, userId, client, "account disabled")
+		return 0, "", errAccountDisabled
+	}
+
+	if rehash {
+		service.rehashPassword(userId, si.Password)
+	}
+
+	if service.cfg.requireVerifiedEmail && user.EmailVerifiedAt == nil {
+		service.audit(entity.AuditSignInFailed, userId, client, "email not verified")
+		return 0, "", errEmailNotVerified
+	}
+
+	// the failures are kept until the second factor is verified as well
+	if user.TOTPEnabledAt != nil {
+		challenge, err := service.issueUserToken(userId, user.Email, entity.TokenPurposeTwoFactor,
+			service.cfg.twoFactor)
+		if err != nil {
+			return 0, "", err
+		}
+		return 0, challenge, nil
+	}
+
+	service.resetFailures(keys[0], userId, client)
+
+	return userId, "", nil
 }
 
-func (service *AuthServiceImpl) SignUp(su dto.SignUpDTO) (int64, error) {
-	return service.repo.SignUp(entity.FromSignUpDTO(su, service.HashPassword(su.Password)))
+// signInUser finds the user by the email of the sign in or else by the username.
+func (service *AuthServiceImpl) signInUser(si dto.SignInDTO) (entity.User, error) {
+	if si.Email != "" {
+		return service.repo.GetByEmail(si.Email)
+	}
+
+	return service.repo.GetByUsername(si.Username)
 }
 
-func (service *AuthServiceImpl) SignIn(si dto.SignInDTO) (int64, error) {
-	return service.repo.SignIn(si.Username, service.HashPassword(si.Password))
+func (service *AuthServiceImpl) HashPassword(password string) (string, error) {
+	return service.cfg.password.hash(password)
 }
 
-func (service *AuthServiceImpl) HashPassword(password string) string {
-	h := sha256.New()
-	h.Write([]byte(password))
+// rehashPassword upgrades a legacy or outdated hash after a successful sign in.
+// A failure here must not prevent the user from signing in.
+func (service *AuthServiceImpl) rehashPassword(userId int64, password string) {
+	passwordHash, err := service.HashPassword(password)
+	if err == nil {
+		err = service.repo.UpdatePasswordHash(userId, passwordHash)
+	}
 
-	return fmt.Sprintf("%x", h.Sum([]byte(service.cfg.salt)))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id": userId,
+			"error":   err,
+		}).Error("failed to rehash password")
+	}
 }
 
-func (service *AuthServiceImpl) GenerateTokens(id int64) (string, string, error) {
-	jwtt := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.RegisteredClaims{
-		Subject:   strconv.FormatInt(id, 10),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(service.cfg.jwt)),
-	})
-	jt, err := jwtt.SignedString([]byte(service.cfg.signature))
+func (service *AuthServiceImpl) GenerateTokens(id int64, client dto.ClientInfo) (string, string, error) {
+	user, err := service.repo.GetById(id)
 	if err != nil {
 		return "", "", err
 	}
 
-	token := make([]byte, 32)
-	if _, err = rand.Read(token); err != nil {
+	if user.DisabledAt != nil {
+		return "", "", errAccountDisabled
+	}
+
+	rt, err := newRandomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	session := &entity.Session{
+		UserId:    id,
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+		ExpiresAt: time.Now().Add(service.cfg.refresh),
+	}
+	if err = service.repo.CreateRefreshToken(session, service.hashToken(rt)); err != nil {
 		return "", "", err
 	}
 
-	rt := fmt.Sprintf("%x", token)
-	if err = service.repo.CreateRefreshToken(id, rt, time.Now().Add(service.cfg.refresh)); err != nil {
+	jt, err := service.accessToken(user, session.FamilyId)
+	if err != nil {
 		return "", "", err
 	}
+	service.audit(entity.AuditSignIn, id, client, "")
 
 	return jt, rt, nil
 }
 
-func (service *AuthServiceImpl) UpdateTokens(rt string) (string, string, error) {
-	id, expiresAt, err := service.repo.FindRefreshToken(rt)
+// UpdateTokens rotates the refresh token rt. Presenting a token that was
+// already rotated means that it leaked, so the whole family is revoked.
+func (service *AuthServiceImpl) UpdateTokens(rt string, client dto.ClientInfo) (string, string, error) {
+	hash := service.hashToken(rt)
+
+	session, err := service.repo.FindRefreshToken(hash)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return "", "", errors.New("invalid refresh token")
+			service.audit(entity.AuditRefreshRejected, 0, client, "unknown refresh token")
+			return "", "", errInvalidRefreshToken
 		}
 		return "", "", err
 	}
 
-	if time.Now().After(expiresAt) {
-		if err := service.repo.DeleteRefreshToken(rt); err != nil {
+	if session.RotatedAt != nil {
+		return "", "", service.revokeFamily(session, client)
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		if err := service.repo.DeleteRefreshToken(hash); err != nil {
 			return "", "", err
 		}
-		return "", "", errors.New("token is expired")
+		service.audit(entity.AuditRefreshRejected, session.UserId, client, "expired refresh token")
+		return "", "", errExpiredRefreshToken
+	}
+
+	user, err := service.repo.GetById(session.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			service.audit(entity.AuditRefreshRejected, session.UserId, client, "user not found")
+			return "", "", errInvalidRefreshToken
+		}
+		return "", "", err
+	}
+
+	if user.DisabledAt != nil {
+		service.audit(entity.AuditRefreshRejected, session.UserId, client, "account disabled")
+		return "", "", errAccountDisabled
+	}
+
+	jt, err := service.accessToken(user, session.FamilyId)
+	if err != nil {
+		return "", "", err
+	}
+
+	newRt, err := newRandomToken()
+	if err != nil {
+		return "", "", err
 	}
 
-	if err := service.repo.DeleteRefreshToken(rt); err != nil {
+	next := &entity.Session{
+		UserId:    session.UserId,
+		FamilyId:  session.FamilyId,
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+		CreatedAt: session.CreatedAt,
+		ExpiresAt: time.Now().Add(service.cfg.refresh),
+	}
+	if err := service.repo.RotateRefreshToken(session.Id, next, service.hashToken(newRt)); err != nil {
+		if err == sql.ErrNoRows {
+			// rotated by a concurrent request with the same token
+			return "", "", service.revokeFamily(session, client)
+		}
 		return "", "", err
 	}
+	service.audit(entity.AuditTokenRefreshed, session.UserId, client, "")
+
+	return jt, newRt, nil
+}
+
+// revokeFamily deletes every token of the family of the reused token
+// and records the event as a possible token theft.
+func (service *AuthServiceImpl) revokeFamily(session entity.Session, client dto.ClientInfo) error {
+	logrus.WithFields(logrus.Fields{
+		"event":      "refresh_token_reuse",
+		"user_id":    session.UserId,
+		"family_id":  session.FamilyId,
+		"token_id":   session.Id,
+		"ip":         client.IP,
+		"user_agent": client.UserAgent,
+	}).Error("reuse of a rotated refresh token, revoking the token family")
+	service.audit(entity.AuditRefreshTokenReuse, session.UserId, client,
+		fmt.Sprintf("token family %d revoked", session.FamilyId))
+
+	if err := service.repo.DeleteTokenFamily(session.FamilyId); err != nil {
+		return err
+	}
+
+	return errReusedRefreshToken
+}
+
+func (service *AuthServiceImpl) SignOut(rt string) error {
+	return service.repo.DeleteRefreshToken(service.hashToken(rt))
+}
 
-	return service.GenerateTokens(id)
+func (service *AuthServiceImpl) SignOutAll(userId int64) error {
+	return service.repo.DeleteRefreshTokens(userId)
 }
 
-func (service *AuthServiceImpl) ParseToken(input string) (int64, error) {
-	token, err := jwt.Parse(input, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+// GetSessions lists the active sessions of the user and marks the one
+// the refresh token rt belongs to as current.
+func (service *AuthServiceImpl) GetSessions(userId int64, rt string) ([]dto.SessionDTO, error) {
+	var currentId int64
+	if rt != "" {
+		current, err := service.repo.FindRefreshToken(service.hashToken(rt))
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
 		}
+		currentId = current.FamilyId
+	}
 
-		return []byte(service.cfg.signature), nil
-	})
+	sessions, err := service.repo.GetSessions(userId)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	if !token.Valid {
-		return 0, errors.New("invalid token")
+	res := make([]dto.SessionDTO, len(sessions))
+	for i, s := range sessions {
+		res[i] = s.ToDTO(s.FamilyId == currentId)
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return 0, errors.New("invalid token")
+	return res, nil
+}
+
+func (service *AuthServiceImpl) DeleteSession(id int64, userId int64) error {
+	if err := service.repo.DeleteSession(id, userId); err != nil {
+		if err == sql.ErrNoRows {
+			return errSessionNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// accessClaims are the claims of access tokens. Role is the role of the user
+// when the token was issued, tokens issued before roles existed have none.
+// SessionId is the family id of the refresh token issued together with the token.
+type accessClaims struct {
+	Role      string `json:"role,omitempty"`
+	SessionId int64  `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func (service *AuthServiceImpl) accessToken(user entity.User, sessionId int64) (string, error) {
+	jti, err := newRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	return service.cfg.keys.sign(&accessClaims{
+		Role:      user.Role,
+		SessionId: sessionId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   strconv.Itoa(user.Id),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(service.cfg.jwt)),
+		},
+	})
+}
+
+// newRandomToken returns 256 random bits, hex encoded.
+func newRandomToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
 	}
 
-	sub, err := claims.GetSubject()
+	return fmt.Sprintf("%x", token), nil
+}
+
+// hashToken returns the keyed hash refresh and user tokens are stored and
+// looked up by, so that a copy of the database is not enough to use them.
+func (service *AuthServiceImpl) hashToken(rt string) string {
+	mac := hmac.New(sha256.New, service.cfg.refreshSecret)
+	mac.Write([]byte(rt))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseToken verifies an access token and returns its claims. Whether the
+// token was revoked is up to the caller.
+func (service *AuthServiceImpl) ParseToken(input string) (dto.TokenClaimsDTO, error) {
+	var claims accessClaims
+	token, err := service.cfg.keys.parse(input, &claims)
 	if err != nil {
-		return 0, errors.New("invalid token")
+		return dto.TokenClaimsDTO{}, err
 	}
 
-	id, err := strconv.Atoi(sub)
+	if !token.Valid {
+		return dto.TokenClaimsDTO{}, errors.New("invalid token")
+	}
+
+	id, err := strconv.ParseInt(claims.Subject, 10, 64)
 	if err != nil {
-		return 0, errors.New("invalid token")
+		return dto.TokenClaimsDTO{}, errors.New("invalid token")
+	}
+
+	res := dto.TokenClaimsDTO{
+		UserId:    id,
+		Role:      claims.Role,
+		Id:        claims.ID,
+		SessionId: claims.SessionId,
+	}
+	if res.Role == "" {
+		res.Role = dto.RoleUser
 	}
+	if claims.IssuedAt != nil {
+		res.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		res.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	return res, nil
+}
 
-	return int64(id), nil
+func (service *AuthServiceImpl) JWKS() dto.JWKSetDTO {
+	return service.cfg.keys.jwks()
 }
@@ -1,27 +1,63 @@
 package implserv
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/lockout"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/mailer"
+	mock_mailer "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/mailer/mocks"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories"
 	mock_repositories "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories/mocks"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
+// newTestAuthService creates the service under test and stops the test if its config is invalid.
+// Audit events are accepted and ignored, tests of the audit log replace auditLog.
+// The two-factor key is set to testTwoFactorKey unless the config sets one.
+func newTestAuthService(t *testing.T, repo repositories.AuthRepository, cfg *config.Config) *AuthServiceImpl {
+	t.Helper()
+
+	if cfg.TwoFactor.Key.EncryptionKey == "" {
+		cfg.TwoFactor.Key.EncryptionKey = testTwoFactorKey
+	}
+
+	auditLog := mock_repositories.NewMockAuditRepository(gomock.NewController(t))
+	auditLog.EXPECT().CreateEvent(gomock.Any()).Return(nil).AnyTimes()
+
+	serv, err := NewAuthService(repo, nil, lockout.NewMemoryStore(), auditLog, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return serv
+}
+
 func TestAuthService_SignUp(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mock_repositories.NewMockAuthRepository(ctrl)
+	mail := mock_mailer.NewMockMailer(ctrl)
 
-	serv := AuthServiceImpl{repo: repo}
+	serv := newTestAuthService(t, repo, &config.Config{
+		Password: testPasswordConfig,
+		Mailer:   config.Mailer{BaseURL: "http://app"},
+	})
+	serv.mailer = mail
 
 	input := dto.SignUpDTO{
 		Name:     "Name",
@@ -30,25 +66,109 @@ func TestAuthService_SignUp(t *testing.T) {
 		Password: "password",
 	}
 
-	expectedUser := entity.FromSignUpDTO(input, serv.HashPassword(input.Password))
-
-	repo.EXPECT().SignUp(expectedUser).Return(int64(1), nil)
+	repo.EXPECT().SignUp(gomock.Any()).DoAndReturn(func(u *entity.User) (int64, error) {
+		assert.Equal(t, u.Name, input.Name)
+		assert.Equal(t, u.Email, input.Email)
+		assert.Equal(t, u.Username, input.Username)
+		assert.True(t, strings.HasPrefix(u.PasswordHash, argon2idPrefix))
+
+		ok, _, err := serv.cfg.password.verify(input.Password, u.PasswordHash, "")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		return int64(1), nil
+	})
+	repo.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(token *entity.UserToken, tokenHash string) error {
+			assert.Equal(t, token.UserId, int64(1))
+			assert.Equal(t, token.Purpose, entity.TokenPurposeEmailVerification)
+			assert.Equal(t, token.Email, input.Email)
+			assert.WithinDuration(t, token.ExpiresAt, time.Now().Add(defaultVerificationTTL), time.Minute)
+			assert.Len(t, tokenHash, 64)
+			return nil
+		})
+	mail.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg mailer.Message) error {
+		assert.Equal(t, msg.To, input.Email)
+		assert.Contains(t, msg.Body, "http://app/verify-email?token=")
+		return errors.New("mail server is down")
+	})
 
-	got, err := serv.SignUp(input)
+	got, err := serv.SignUp(input, dto.ClientInfo{})
 
-	assert.NoError(t, err)
+	assert.NoError(t, err, "a failed email does not fail the sign up")
 	assert.Equal(t, got, int64(1))
 }
 
+func TestAuthService_SignUpConflict(t *testing.T) {
+	cases := []struct {
+		name        string
+		repoErr     error
+		expectedErr error
+	}{
+		{
+			name:        "Email taken",
+			repoErr:     &pq.Error{Code: "23505", Constraint: "users_email_lower_key"},
+			expectedErr: errEmailTaken,
+		},
+		{
+			name:        "Username taken",
+			repoErr:     &pq.Error{Code: "23505", Constraint: "users_username_lower_key"},
+			expectedErr: errUsernameTaken,
+		},
+		{
+			name:        "Other error",
+			repoErr:     &pq.Error{Code: "23502", Column: "name"},
+			expectedErr: &pq.Error{Code: "23502", Column: "name"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			repo.EXPECT().SignUp(gomock.Any()).Return(int64(0), c.repoErr)
+
+			serv := newTestAuthService(t, repo, &config.Config{Password: testPasswordConfig})
+
+			_, err := serv.SignUp(dto.SignUpDTO{Username: "username", Password: "password"}, dto.ClientInfo{})
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
+
+func TestAuthService_SignUpWeakPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serv := newTestAuthService(t, mock_repositories.NewMockAuthRepository(ctrl), &config.Config{
+		Password: config.Password{Policy: config.PasswordPolicy{MinLength: 12, RequireDigit: true}},
+	})
+
+	_, err := serv.SignUp(dto.SignUpDTO{Email: "aaa@bbb.ccc", Username: "username", Password: "password"}, dto.ClientInfo{})
+
+	assert.Equal(t, err, errWeakPassword([]apperr.FieldError{
+		{Field: "password", Rule: "min_length", Detail: "must be at least 12 characters long"},
+		{Field: "password", Rule: "digit", Detail: "must contain a digit"},
+	}))
+}
+
 func TestAuthService_SignIn(t *testing.T) {
-	type mockBehavior func(s *mock_repositories.MockAuthRepository, username, passwordHash string)
+	type mockBehavior func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string)
+
+	outdated := passwordParams{memory: 512, iterations: 1, parallelism: 1, saltLength: 8, keyLength: 16}
+
+	verifiedAt := time.Now()
 
 	cases := []struct {
-		name         string
-		input        dto.SignInDTO
-		mockBehavior mockBehavior
-		expected     int64
-		expectedErr  bool
+		name              string
+		input             dto.SignInDTO
+		requireVerified   bool
+		mockBehavior      mockBehavior
+		expected          int64
+		expectedChallenge bool
+		expectedErr       error
 	}{
 		{
 			name: "OK",
@@ -56,21 +176,185 @@ func TestAuthService_SignIn(t *testing.T) {
 				Username: "username",
 				Password: "password",
 			},
-			mockBehavior: func(s *mock_repositories.MockAuthRepository, username, passwordHash string) {
-				s.EXPECT().SignIn(username, passwordHash).Return(int64(1), nil)
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				hash, _ := serv.HashPassword("password")
+				s.EXPECT().GetByUsername(username).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+			},
+			expected: 1,
+		},
+		{
+			name: "Two-factor challenge",
+			input: dto.SignInDTO{
+				Username: "username",
+				Password: "password",
+			},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				hash, _ := serv.HashPassword("password")
+				s.EXPECT().GetByUsername(username).
+					Return(entity.User{Id: 1, Email: "email@gmail.com", PasswordHash: hash, TOTPEnabledAt: &verifiedAt}, nil)
+				s.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(token *entity.UserToken, tokenHash string) error {
+						assert.Equal(t, token.UserId, int64(1))
+						assert.Equal(t, token.Purpose, entity.TokenPurposeTwoFactor)
+						assert.WithinDuration(t, token.ExpiresAt, time.Now().Add(defaultTwoFactorTTL), time.Minute)
+						return nil
+					})
+			},
+			expectedChallenge: true,
+		},
+		{
+			name: "Legacy hash is upgraded",
+			input: dto.SignInDTO{
+				Username: "username",
+				Password: "password",
+			},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				s.EXPECT().GetByUsername(username).
+					Return(entity.User{Id: 1, PasswordHash: legacyPasswordHash("password", "salt")}, nil)
+				s.EXPECT().UpdatePasswordHash(int64(1), gomock.Any()).
+					DoAndReturn(func(id int64, hash string) error {
+						assert.True(t, strings.HasPrefix(hash, argon2idPrefix))
+						return nil
+					})
+			},
+			expected: 1,
+		},
+		{
+			name: "Outdated parameters are upgraded",
+			input: dto.SignInDTO{
+				Username: "username",
+				Password: "password",
+			},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				hash, _ := outdated.hash("password")
+				s.EXPECT().GetByUsername(username).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+				s.EXPECT().UpdatePasswordHash(int64(1), gomock.Any()).Return(nil)
 			},
 			expected: 1,
 		},
 		{
-			name: "Invalid data",
+			name: "Failed rehash does not block sign in",
+			input: dto.SignInDTO{
+				Username: "username",
+				Password: "password",
+			},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				s.EXPECT().GetByUsername(username).
+					Return(entity.User{Id: 1, PasswordHash: legacyPasswordHash("password", "salt")}, nil)
+				s.EXPECT().UpdatePasswordHash(int64(1), gomock.Any()).Return(errors.New("some error"))
+			},
+			expected: 1,
+		},
+		{
+			name: "Invalid password",
+			input: dto.SignInDTO{
+				Username: "username",
+				Password: "invalid_password",
+			},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				hash, _ := serv.HashPassword("password")
+				s.EXPECT().GetByUsername(username).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+			},
+			expectedErr: errInvalidCredentials,
+		},
+		{
+			name: "Disabled account",
+			input: dto.SignInDTO{
+				Username: "username",
+				Password: "password",
+			},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				hash, _ := serv.HashPassword("password")
+				s.EXPECT().GetByUsername(username).
+					Return(entity.User{Id: 1, PasswordHash: hash, DisabledAt: &verifiedAt}, nil)
+			},
+			expectedErr: errAccountDisabled,
+		},
+		{
+			name: "Invalid legacy password",
+			input: dto.SignInDTO{
+				Username: "username",
+				Password: "invalid_password",
+			},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				s.EXPECT().GetByUsername(username).
+					Return(entity.User{Id: 1, PasswordHash: legacyPasswordHash("password", "salt")}, nil)
+			},
+			expectedErr: errInvalidCredentials,
+		},
+		{
+			name: "Invalid username",
 			input: dto.SignInDTO{
 				Username: "invalid_username",
+				Password: "password",
+			},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				s.EXPECT().GetByUsername(username).Return(entity.User{}, sql.ErrNoRows)
+			},
+			expectedErr: errInvalidCredentials,
+		},
+		{
+			name: "Email",
+			input: dto.SignInDTO{
+				Email:    "Email@gmail.com",
+				Password: "password",
+			},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				hash, _ := serv.HashPassword("password")
+				s.EXPECT().GetByEmail("Email@gmail.com").Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+			},
+			expected: 1,
+		},
+		{
+			name: "Invalid email",
+			input: dto.SignInDTO{
+				Email:    "invalid@gmail.com",
+				Password: "password",
+			},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				s.EXPECT().GetByEmail("invalid@gmail.com").Return(entity.User{}, sql.ErrNoRows)
+			},
+			expectedErr: errInvalidCredentials,
+		},
+		{
+			name: "Verified email",
+			input: dto.SignInDTO{
+				Username: "username",
+				Password: "password",
+			},
+			requireVerified: true,
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				hash, _ := serv.HashPassword("password")
+				s.EXPECT().GetByUsername(username).
+					Return(entity.User{Id: 1, PasswordHash: hash, EmailVerifiedAt: &verifiedAt}, nil)
+			},
+			expected: 1,
+		},
+		{
+			name: "Unverified email",
+			input: dto.SignInDTO{
+				Username: "username",
+				Password: "password",
+			},
+			requireVerified: true,
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				hash, _ := serv.HashPassword("password")
+				s.EXPECT().GetByUsername(username).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+			},
+			expectedErr: errEmailNotVerified,
+		},
+		{
+			name: "Unverified email with invalid password",
+			input: dto.SignInDTO{
+				Username: "username",
 				Password: "invalid_password",
 			},
-			mockBehavior: func(s *mock_repositories.MockAuthRepository, username, passwordHash string) {
-				s.EXPECT().SignIn(username, passwordHash).Return(int64(0), sql.ErrNoRows)
+			requireVerified: true,
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, serv *AuthServiceImpl, username string) {
+				hash, _ := serv.HashPassword("password")
+				s.EXPECT().GetByUsername(username).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
 			},
-			expectedErr: true,
+			expectedErr: errInvalidCredentials,
 		},
 	}
 
@@ -80,17 +364,20 @@ func TestAuthService_SignIn(t *testing.T) {
 			defer ctrl.Finish()
 
 			repo := mock_repositories.NewMockAuthRepository(ctrl)
-			serv := AuthServiceImpl{
-				repo: repo,
-			}
-			c.mockBehavior(repo, c.input.Username, serv.HashPassword(c.input.Password))
-
-			got, err := serv.SignIn(c.input)
-			if c.expectedErr {
-				assert.Error(t, err)
+			serv := newTestAuthService(t, repo, &config.Config{
+				Auth:     config.Auth{Salt: "salt"},
+				Password: testPasswordConfig,
+				SignIn:   config.SignIn{RequireVerifiedEmail: c.requireVerified},
+			})
+			c.mockBehavior(repo, serv, c.input.Username)
+
+			got, challenge, err := serv.SignIn(c.input, dto.ClientInfo{IP: "127.0.0.1"})
+			if c.expectedErr != nil {
+				assert.ErrorIs(t, err, c.expectedErr)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, got, c.expected)
+				assert.Equal(t, challenge != "", c.expectedChallenge)
 			}
 		})
 	}
@@ -102,81 +389,174 @@ func TestAuthService_GenerateTokens(t *testing.T) {
 
 	repo := mock_repositories.NewMockAuthRepository(ctrl)
 
+	var storedHash string
+	repo.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, Role: dto.RoleAdmin}, nil)
 	repo.
 		EXPECT().
-		CreateRefreshToken(int64(1), gomock.Any(), gomock.Any()).
-		Return(nil)
+		CreateRefreshToken(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(s *entity.Session, tokenHash string) error {
+			assert.Equal(t, s.UserId, int64(1))
+			assert.Equal(t, s.UserAgent, "agent")
+			assert.Equal(t, s.IP, "127.0.0.1")
+			assert.WithinDuration(t, s.ExpiresAt, time.Now().Add(time.Hour*2), time.Minute)
+			storedHash = tokenHash
+			s.Id, s.FamilyId = 3, 3
+			return nil
+		})
 
 	cfg := &config.Config{
 		Auth: config.Auth{
-			Signature: "signature",
-			JWT:       time.Hour,
-			Refresh:   time.Hour * 2,
+			Signature:     "signature",
+			RefreshSecret: "refresh_secret",
+			JWT:           time.Hour,
+			Refresh:       time.Hour * 2,
 		},
 	}
 
-	jt, rt, err := NewAuthService(repo, cfg).GenerateTokens(1)
+	serv := newTestAuthService(t, repo, cfg)
+
+	issuedAt := time.Now()
+	jt, rt, err := serv.GenerateTokens(1, testClient)
 
 	assert.NoError(t, err)
-	assert.NotEmpty(t, jt)
 	assert.NotEmpty(t, rt)
+	assert.Equal(t, storedHash, testTokenHash(rt))
+
+	claims, err := serv.ParseToken(jt)
+	assert.NoError(t, err)
+	assert.Equal(t, claims.UserId, int64(1))
+	assert.Equal(t, claims.Role, dto.RoleAdmin)
+	assert.Equal(t, claims.SessionId, int64(3))
+	assert.Len(t, claims.Id, 64)
+	assert.WithinDuration(t, claims.ExpiresAt, time.Now().Add(time.Hour), time.Minute)
+	// the issue time keeps its milliseconds for the revocation of every token of the user
+	assert.False(t, claims.IssuedAt.Before(issuedAt.Add(-2*time.Millisecond)))
+
+	disabledAt := time.Now()
+	repo.EXPECT().GetById(int64(2)).Return(entity.User{Id: 2, DisabledAt: &disabledAt}, nil)
+
+	_, _, err = serv.GenerateTokens(2, testClient)
+
+	assert.Equal(t, err, errAccountDisabled)
+}
+
+var testClient = dto.ClientInfo{UserAgent: "agent", IP: "127.0.0.1"}
+
+// testTokenHash is the HMAC-SHA256 of the token with the refresh secret of the tests.
+func testTokenHash(token string) string {
+	mac := hmac.New(sha256.New, []byte("refresh_secret"))
+	mac.Write([]byte(token))
+
+	return hex.EncodeToString(mac.Sum(nil))
 }
 
 func TestAuthService_UpdateTokens(t *testing.T) {
 	type mockBehavior func(s *mock_repositories.MockAuthRepository, token string)
 
+	createdAt := time.Now().Add(-time.Hour)
+	rotatedAt := time.Now().Add(-time.Minute)
+	session := entity.Session{Id: 2, UserId: 1, FamilyId: 3, CreatedAt: createdAt, ExpiresAt: time.Now().Add(time.Hour)}
+	rotated := entity.Session{Id: 2, UserId: 1, FamilyId: 3, RotatedAt: &rotatedAt, ExpiresAt: time.Now().Add(time.Hour)}
+
 	cases := []struct {
 		name         string
 		input        string
 		mockBehavior mockBehavior
-		expectedErr  bool
+		expectedErr  error
 	}{
 		{
 			name:  "OK",
 			input: "token",
 			mockBehavior: func(s *mock_repositories.MockAuthRepository, token string) {
-				s.EXPECT().FindRefreshToken(token).Return(int64(1),
-					time.Date(2025, time.January, 0, 0, 0, 0, 0, time.Local), nil)
-				s.EXPECT().DeleteRefreshToken(token).Return(nil)
-				s.EXPECT().CreateRefreshToken(int64(1), gomock.Any(), gomock.Any()).Return(nil)
+				s.EXPECT().FindRefreshToken(token).Return(session, nil)
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1}, nil)
+				s.EXPECT().RotateRefreshToken(int64(2), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(parentId int64, s *entity.Session, newHash string) error {
+						assert.Equal(t, s.UserId, int64(1))
+						assert.Equal(t, s.FamilyId, int64(3))
+						assert.Equal(t, s.CreatedAt, createdAt)
+						assert.Equal(t, s.UserAgent, "agent")
+						assert.Equal(t, s.IP, "127.0.0.1")
+						assert.Len(t, newHash, 64)
+						assert.NotEqual(t, newHash, token)
+						return nil
+					})
 			},
 		},
 		{
-			name:  "Failed to generate",
+			name:  "Failed to rotate",
 			input: "token",
 			mockBehavior: func(s *mock_repositories.MockAuthRepository, token string) {
-				s.EXPECT().FindRefreshToken(token).Return(int64(1),
-					time.Date(2025, time.January, 0, 0, 0, 0, 0, time.Local), nil)
-				s.EXPECT().DeleteRefreshToken(token).Return(nil)
-				s.EXPECT().CreateRefreshToken(int64(1), gomock.Any(), gomock.Any()).
-					Return(errors.New("some error"))
+				s.EXPECT().FindRefreshToken(token).Return(session, nil)
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1}, nil)
+				s.EXPECT().RotateRefreshToken(int64(2), gomock.Any(), gomock.Any()).Return(errSome)
 			},
-			expectedErr: true,
+			expectedErr: errSome,
+		},
+		{
+			name:  "Reused token",
+			input: "token",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, token string) {
+				s.EXPECT().FindRefreshToken(token).Return(rotated, nil)
+				s.EXPECT().DeleteTokenFamily(int64(3)).Return(nil)
+			},
+			expectedErr: errReusedRefreshToken,
+		},
+		{
+			name:  "Rotated concurrently",
+			input: "token",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, token string) {
+				s.EXPECT().FindRefreshToken(token).Return(session, nil)
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1}, nil)
+				s.EXPECT().RotateRefreshToken(int64(2), gomock.Any(), gomock.Any()).Return(sql.ErrNoRows)
+				s.EXPECT().DeleteTokenFamily(int64(3)).Return(nil)
+			},
+			expectedErr: errReusedRefreshToken,
+		},
+		{
+			name:  "Failed to revoke family",
+			input: "token",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, token string) {
+				s.EXPECT().FindRefreshToken(token).Return(rotated, nil)
+				s.EXPECT().DeleteTokenFamily(int64(3)).Return(errSome)
+			},
+			expectedErr: errSome,
+		},
+		{
+			name:  "Disabled user",
+			input: "token",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, token string) {
+				s.EXPECT().FindRefreshToken(token).Return(session, nil)
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, DisabledAt: &rotatedAt}, nil)
+			},
+			expectedErr: errAccountDisabled,
 		},
 		{
 			name:  "Invalid token",
 			input: "invalid_token",
 			mockBehavior: func(s *mock_repositories.MockAuthRepository, token string) {
-				s.EXPECT().FindRefreshToken(token).Return(int64(0), time.Time{}, sql.ErrNoRows)
+				s.EXPECT().FindRefreshToken(token).Return(entity.Session{}, sql.ErrNoRows)
 			},
-			expectedErr: true,
+			expectedErr: errInvalidRefreshToken,
 		},
 		{
 			name:  "Expired token",
 			input: "expired_token",
 			mockBehavior: func(s *mock_repositories.MockAuthRepository, token string) {
-				s.EXPECT().FindRefreshToken(token).Return(int64(1), time.Now().AddDate(0, 0, -1), nil)
+				s.EXPECT().FindRefreshToken(token).
+					Return(entity.Session{Id: 2, UserId: 1, ExpiresAt: time.Now().AddDate(0, 0, -1)}, nil)
 				s.EXPECT().DeleteRefreshToken(token).Return(nil)
 			},
-			expectedErr: true,
+			expectedErr: errExpiredRefreshToken,
 		},
 	}
 
 	cfg := &config.Config{
 		Auth: config.Auth{
-			Signature: "signature",
-			JWT:       time.Hour,
-			Refresh:   time.Hour * 2,
+			Signature:     "signature",
+			RefreshSecret: "refresh_secret",
+			JWT:           time.Hour,
+			Refresh:       time.Hour * 2,
 		},
 	}
 
@@ -186,11 +566,11 @@ func TestAuthService_UpdateTokens(t *testing.T) {
 			defer ctrl.Finish()
 
 			repo := mock_repositories.NewMockAuthRepository(ctrl)
-			c.mockBehavior(repo, c.input)
+			c.mockBehavior(repo, testTokenHash(c.input))
 
-			jt, rt, err := NewAuthService(repo, cfg).UpdateTokens(c.input)
-			if c.expectedErr {
-				assert.Error(t, err)
+			jt, rt, err := newTestAuthService(t, repo, cfg).UpdateTokens(c.input, testClient)
+			if c.expectedErr != nil {
+				assert.Equal(t, err, c.expectedErr)
 				assert.Empty(t, jt)
 				assert.Empty(t, rt)
 			} else {
@@ -202,6 +582,121 @@ func TestAuthService_UpdateTokens(t *testing.T) {
 	}
 }
 
+func TestAuthService_GetSessions(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository)
+
+	createdAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+	sessions := []entity.Session{
+		{Id: 4, UserId: 1, FamilyId: 2, UserAgent: "agent", CreatedAt: createdAt},
+		{Id: 1, UserId: 1, FamilyId: 1, UserAgent: "other agent", CreatedAt: createdAt},
+	}
+
+	cases := []struct {
+		name         string
+		token        string
+		mockBehavior mockBehavior
+		expected     []dto.SessionDTO
+		expectedErr  bool
+	}{
+		{
+			name:  "OK",
+			token: "token",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().FindRefreshToken(testTokenHash("token")).Return(sessions[0], nil)
+				s.EXPECT().GetSessions(int64(1)).Return(sessions, nil)
+			},
+			expected: []dto.SessionDTO{
+				{Id: 2, UserAgent: "agent", CreatedAt: createdAt, Current: true},
+				{Id: 1, UserAgent: "other agent", CreatedAt: createdAt},
+			},
+		},
+		{
+			name: "Without token",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetSessions(int64(1)).Return(sessions, nil)
+			},
+			expected: []dto.SessionDTO{
+				{Id: 2, UserAgent: "agent", CreatedAt: createdAt},
+				{Id: 1, UserAgent: "other agent", CreatedAt: createdAt},
+			},
+		},
+		{
+			name:  "Unknown token",
+			token: "unknown",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().FindRefreshToken(testTokenHash("unknown")).Return(entity.Session{}, sql.ErrNoRows)
+				s.EXPECT().GetSessions(int64(1)).Return(sessions[:1], nil)
+			},
+			expected: []dto.SessionDTO{
+				{Id: 2, UserAgent: "agent", CreatedAt: createdAt},
+			},
+		},
+		{
+			name: "Repository error",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetSessions(int64(1)).Return(nil, errors.New("some error"))
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			c.mockBehavior(repo)
+
+			cfg := &config.Config{Auth: config.Auth{RefreshSecret: "refresh_secret"}}
+
+			got, err := newTestAuthService(t, repo, cfg).GetSessions(1, c.token)
+			if c.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, got, c.expected)
+			}
+		})
+	}
+}
+
+func TestAuthService_DeleteSession(t *testing.T) {
+	cases := []struct {
+		name        string
+		repoErr     error
+		expectedErr error
+	}{
+		{
+			name: "OK",
+		},
+		{
+			name:        "Not found",
+			repoErr:     sql.ErrNoRows,
+			expectedErr: errSessionNotFound,
+		},
+		{
+			name:        "Repository error",
+			repoErr:     errSome,
+			expectedErr: errSome,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			repo.EXPECT().DeleteSession(int64(2), int64(1)).Return(c.repoErr)
+
+			err := newTestAuthService(t, repo, &config.Config{}).DeleteSession(2, 1)
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
+
 func TestAuthService_ParseToken(t *testing.T) {
 	mockGenerateToken := func(id string, signingMethod jwt.SigningMethod,
 		signature string, issuedAt, expiresAt time.Time) string {
@@ -215,17 +710,43 @@ func TestAuthService_ParseToken(t *testing.T) {
 		return jt
 	}
 
+	issuedAt := time.Now().Truncate(time.Second)
+	expiresAt := issuedAt.Add(time.Hour)
+
+	adminToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &accessClaims{
+		Role:      dto.RoleAdmin,
+		SessionId: 3,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "jti",
+			Subject:   "2",
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}).SignedString([]byte("signature"))
+
 	cases := []struct {
 		name        string
 		token       string
-		expected    int64
+		expected    dto.TokenClaimsDTO
 		expectedErr bool
 	}{
 		{
 			name: "Valid token",
 			token: mockGenerateToken("1", jwt.SigningMethodHS256,
-				"signature", time.Now(), time.Now().Add(time.Hour)),
-			expected: 1,
+				"signature", issuedAt, expiresAt),
+			expected: dto.TokenClaimsDTO{UserId: 1, Role: dto.RoleUser, IssuedAt: issuedAt, ExpiresAt: expiresAt},
+		},
+		{
+			name:  "Token with a role, jti and session",
+			token: adminToken,
+			expected: dto.TokenClaimsDTO{
+				UserId:    2,
+				Role:      dto.RoleAdmin,
+				Id:        "jti",
+				SessionId: 3,
+				IssuedAt:  issuedAt,
+				ExpiresAt: expiresAt,
+			},
 		},
 		{
 			name: "Invalid signing method",
@@ -259,17 +780,16 @@ func TestAuthService_ParseToken(t *testing.T) {
 		},
 	}
 
-	serv := NewAuthService(new(mock_repositories.MockAuthRepository), cfg)
+	serv := newTestAuthService(t, new(mock_repositories.MockAuthRepository), cfg)
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			id, err := serv.ParseToken(c.token)
+			got, err := serv.ParseToken(c.token)
 			if c.expectedErr {
 				assert.Error(t, err)
-				assert.Equal(t, id, c.expected)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, id, c.expected)
 			}
+			assert.Equal(t, got, c.expected)
 		})
 	}
 }
@@ -0,0 +1,115 @@
+package implserv
+
+import (
+	"errors"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	"github.com/lib/pq"
+)
+
+const uniqueViolation = "23505"
+
+var (
+	errProjectNotFound = apperr.NotFound("project_not_found", "project not found")
+	errSessionNotFound = apperr.NotFound("session_not_found", "session not found")
+	errUserNotFound    = apperr.NotFound("user_not_found", "user not found")
+
+	errProjectForbidden = apperr.Forbidden("project_forbidden", "the role in the project does not allow this")
+	errMemberNotFound   = apperr.NotFound("project_member_not_found", "project member not found")
+	errMemberExists     = apperr.Conflict("project_member_exists", "user is already a member of the project")
+	errOwnerMembership  = apperr.Conflict("project_owner",
+		"the owner can not be changed or removed, transfer the ownership first")
+
+	errTaskNotFound      = apperr.NotFound("task_not_found", "task not found")
+	errAssigneeNotMember = apperr.Validation("assignee_not_member", "assignee is not a member of the project",
+		apperr.FieldError{Field: "assignee_id", Rule: "member", Detail: "must be a member of the project"})
+
+	errAccessTokenNotFound = apperr.NotFound("access_token_not_found", "access token not found")
+	errInvalidAccessToken  = apperr.Unauthorized("invalid_access_token", "invalid or expired access token")
+
+	errInvalidCredentials  = apperr.Unauthorized("invalid_credentials", "invalid username or password")
+	errInvalidRefreshToken = apperr.Unauthorized("invalid_refresh_token", "invalid refresh token")
+	errExpiredRefreshToken = apperr.Unauthorized("expired_refresh_token", "refresh token is expired")
+	errReusedRefreshToken  = apperr.Unauthorized("reused_refresh_token",
+		"refresh token was already used, all tokens of the session are revoked")
+
+	errInvalidVerificationToken = apperr.Validation("invalid_verification_token", "invalid verification token")
+	errExpiredVerificationToken = apperr.Validation("expired_verification_token", "verification token is expired")
+	errEmailNotVerified         = apperr.Forbidden("email_not_verified", "email is not verified")
+	errAccountDisabled          = apperr.Forbidden("account_disabled", "account is disabled")
+	errDisableSelf              = apperr.Conflict("disable_self", "admins can not disable their own account")
+
+	errInvalidResetToken      = apperr.Validation("invalid_reset_token", "invalid password reset token")
+	errExpiredResetToken      = apperr.Validation("expired_reset_token", "password reset token is expired")
+	errInvalidCurrentPassword = apperr.Validation("invalid_current_password", "current password is invalid",
+		apperr.FieldError{Field: "current_password", Rule: "password", Detail: "does not match the password of the user"})
+	errInvalidPassword = apperr.Validation("invalid_password", "password is invalid",
+		apperr.FieldError{Field: "password", Rule: "password", Detail: "does not match the password of the user"})
+
+	errTwoFactorEnabled     = apperr.Conflict("two_factor_enabled", "two-factor authentication is already enabled")
+	errTwoFactorNotEnrolled = apperr.Conflict("two_factor_not_enrolled", "two-factor enrollment was not started")
+	errInvalidTOTPCode      = apperr.Validation("invalid_totp_code", "invalid TOTP code",
+		apperr.FieldError{Field: "code", Rule: "totp", Detail: "does not match the current code of the authenticator"})
+	errInvalidChallengeToken = apperr.Unauthorized("invalid_challenge_token", "invalid two-factor challenge token")
+	errExpiredChallengeToken = apperr.Unauthorized("expired_challenge_token", "two-factor challenge token is expired")
+	errInvalidTwoFactorCode  = apperr.Unauthorized("invalid_two_factor_code", "invalid two-factor code")
+
+	errEmailTaken = apperr.Conflict("email_taken", "email is already taken",
+		apperr.FieldError{Field: "email", Rule: "unique", Detail: "is already taken, regardless of case"})
+	errUsernameTaken = apperr.Conflict("username_taken", "username is already taken",
+		apperr.FieldError{Field: "username", Rule: "unique", Detail: "is already taken, regardless of case"})
+
+	errUnknownOIDCProvider = apperr.NotFound("unknown_oidc_provider", "OIDC provider not found")
+	errInvalidOIDCState    = apperr.Unauthorized("invalid_oidc_state",
+		"invalid or expired OIDC sign in, start the sign in again")
+	errOIDCAccessDenied  = apperr.Unauthorized("oidc_access_denied", "the OIDC provider did not sign the user in")
+	errInvalidOIDCCode   = apperr.Unauthorized("invalid_oidc_code", "the OIDC provider rejected the authorization code")
+	errInvalidIDToken    = apperr.Unauthorized("invalid_id_token", "invalid ID token of the OIDC provider")
+	errOIDCEmailRequired = apperr.Validation("oidc_email_required",
+		"the OIDC provider did not share the email of the user, allow the email scope")
+	errOIDCAccountExists = apperr.Conflict("oidc_account_exists",
+		"an account with the email exists, sign in with the password and verify the email to link it")
+	errIdentityLinked = apperr.Conflict("identity_linked", "the account of the OIDC provider is already linked")
+)
+
+// errSignInLocked is returned while the account or the client IP is locked after failed sign ins.
+func errSignInLocked(retryAfter time.Duration) error {
+	return apperr.TooManyRequests("sign_in_locked", "too many failed sign in attempts, try again later", retryAfter)
+}
+
+// errWeakPassword is returned for a password that breaks the password policy, fields lists every rule it breaks.
+func errWeakPassword(fields []apperr.FieldError) error {
+	return apperr.Validation("weak_password", "password does not meet the password policy", fields...)
+}
+
+// userConstraintErrors maps unique indexes of the users table to the errors reported to the client.
+var userConstraintErrors = map[string]error{
+	"users_email_lower_key":    errEmailTaken,
+	"users_username_lower_key": errUsernameTaken,
+}
+
+// memberConstraintErrors maps unique constraints of the project_members table to the errors reported to the client.
+var memberConstraintErrors = map[string]error{
+	"project_members_pkey": errMemberExists,
+}
+
+// identityConstraintErrors maps unique constraints violated by an OIDC sign up to the errors reported to the client.
+var identityConstraintErrors = map[string]error{
+	"user_identities_provider_subject_key": errIdentityLinked,
+	"users_email_lower_key":                errEmailTaken,
+	"users_username_lower_key":             errUsernameTaken,
+}
+
+// constraintError returns the domain error registered for the violated unique
+// constraint or err itself if it is not a unique violation.
+func constraintError(err error, constraints map[string]error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		if domainErr, ok := constraints[pqErr.Constraint]; ok {
+			return domainErr
+		}
+	}
+
+	return err
+}
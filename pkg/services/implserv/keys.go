@@ -0,0 +1,298 @@
+package implserv
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func init() {
+	// access tokens carry their issue time in milliseconds, so that revoking every
+	// token of a user does not reject the tokens issued later in the same second
+	jwt.TimePrecision = time.Millisecond
+}
+
+var signingMethods = map[string]jwt.SigningMethod{
+	jwt.SigningMethodHS256.Alg(): jwt.SigningMethodHS256,
+	jwt.SigningMethodRS256.Alg(): jwt.SigningMethodRS256,
+	jwt.SigningMethodES256.Alg(): jwt.SigningMethodES256,
+	jwt.SigningMethodEdDSA.Alg(): jwt.SigningMethodEdDSA,
+}
+
+// keySet holds the key access tokens are signed with and every key,
+// by kid, they are verified with.
+type keySet struct {
+	method     jwt.SigningMethod
+	signingKid string
+	signingKey interface{}
+	verifying  map[string]verificationKey
+	// algorithms are the algorithms of the verification keys
+	algorithms []string
+}
+
+// verificationKey is a key together with the only algorithm it verifies, so that
+// the keys of an algorithm kept during a rotation verify nothing else.
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    interface{}
+}
+
+// newKeySet loads the keys of the config. Every key verifies tokens of its own
+// algorithm, so tokens signed before the algorithm changed stay valid until they
+// expire. Tokens without a kid are HS256 tokens verified with the secret, which
+// is accepted as long as it is set.
+func newKeySet(cfg config.JWT, secret string) (*keySet, error) {
+	method, err := signingMethod(cfg.Algorithm, jwt.SigningMethodHS256)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &keySet{
+		method:    method,
+		verifying: make(map[string]verificationKey),
+	}
+
+	if method == jwt.SigningMethodHS256 {
+		ks.signingKey = []byte(secret)
+	}
+	if method == jwt.SigningMethodHS256 || secret != "" {
+		ks.addKey("", jwt.SigningMethodHS256, []byte(secret))
+	}
+
+	for _, k := range cfg.Keys {
+		if k.Kid == "" {
+			return nil, errors.New("jwt key without kid")
+		}
+		if _, ok := ks.verifying[k.Kid]; ok {
+			return nil, fmt.Errorf("duplicate jwt key: %q", k.Kid)
+		}
+
+		keyMethod, err := signingMethod(k.Algorithm, method)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %q: %w", k.Kid, err)
+		}
+		if keyMethod == jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("jwt key %q: HS256 uses the secret, keys need another algorithm", k.Kid)
+		}
+
+		private, public, err := loadKey(keyMethod, k)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %q: %w", k.Kid, err)
+		}
+
+		ks.addKey(k.Kid, keyMethod, public)
+		if method != jwt.SigningMethodHS256 && k.Kid == cfg.SigningKey {
+			if private == nil {
+				return nil, fmt.Errorf("jwt signing key %q has no private key", k.Kid)
+			}
+			if keyMethod != method {
+				return nil, fmt.Errorf("jwt signing key %q is not a %s key", k.Kid, method.Alg())
+			}
+			ks.signingKid, ks.signingKey = k.Kid, private
+		}
+	}
+
+	if ks.signingKey == nil {
+		return nil, fmt.Errorf("jwt signing key %q is not configured", cfg.SigningKey)
+	}
+
+	return ks, nil
+}
+
+// signingMethod returns the method of the algorithm, the fallback if it is empty.
+func signingMethod(alg string, fallback jwt.SigningMethod) (jwt.SigningMethod, error) {
+	if alg == "" {
+		return fallback, nil
+	}
+
+	method, ok := signingMethods[alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported jwt algorithm: %q", alg)
+	}
+
+	return method, nil
+}
+
+func (ks *keySet) addKey(kid string, method jwt.SigningMethod, key interface{}) {
+	ks.verifying[kid] = verificationKey{method: method, key: key}
+
+	for _, alg := range ks.algorithms {
+		if alg == method.Alg() {
+			return
+		}
+	}
+	ks.algorithms = append(ks.algorithms, method.Alg())
+}
+
+// loadKey reads the PEM files of the key. The public key is derived from
+// the private one when both are present.
+func loadKey(method jwt.SigningMethod, k config.JWTKey) (crypto.Signer, crypto.PublicKey, error) {
+	if k.PrivateKey != "" {
+		data, err := os.ReadFile(k.PrivateKey)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		private, err := parsePrivateKey(method, data)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return private, private.Public(), nil
+	}
+
+	if k.PublicKey == "" {
+		return nil, nil, errors.New("neither private nor public key is set")
+	}
+
+	data, err := os.ReadFile(k.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	public, err := parsePublicKey(method, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nil, public, nil
+}
+
+func parsePrivateKey(method jwt.SigningMethod, data []byte) (crypto.Signer, error) {
+	switch method {
+	case jwt.SigningMethodRS256:
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		return key, nil
+	case jwt.SigningMethodES256:
+		key, err := jwt.ParseECPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		if key.Curve != elliptic.P256() {
+			return nil, errors.New("ES256 requires a P-256 key")
+		}
+		return key, nil
+	case jwt.SigningMethodEdDSA:
+		key, err := jwt.ParseEdPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, jwt.ErrNotEdPrivateKey
+		}
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm: %q", method.Alg())
+	}
+}
+
+func parsePublicKey(method jwt.SigningMethod, data []byte) (crypto.PublicKey, error) {
+	switch method {
+	case jwt.SigningMethodRS256:
+		key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		return key, nil
+	case jwt.SigningMethodES256:
+		key, err := jwt.ParseECPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		if key.Curve != elliptic.P256() {
+			return nil, errors.New("ES256 requires a P-256 key")
+		}
+		return key, nil
+	case jwt.SigningMethodEdDSA:
+		return jwt.ParseEdPublicKeyFromPEM(data)
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm: %q", method.Alg())
+	}
+}
+
+func (ks *keySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.method, claims)
+	if ks.signingKid != "" {
+		token.Header["kid"] = ks.signingKid
+	}
+
+	return token.SignedString(ks.signingKey)
+}
+
+// parse verifies the token with the key of its kid header. Only the algorithm
+// of the key is accepted, so a public key can never be used as an HMAC secret.
+func (ks *keySet) parse(input string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(input, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		vk, ok := ks.verifying[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %q", kid)
+		}
+
+		if t.Method.Alg() != vk.method.Alg() {
+			return nil, fmt.Errorf("signing key %q does not verify %s", kid, t.Method.Alg())
+		}
+
+		return vk.key, nil
+	}, jwt.WithValidMethods(ks.algorithms))
+}
+
+// jwks returns the public verification keys, HMAC secrets are never published.
+func (ks *keySet) jwks() dto.JWKSetDTO {
+	kids := make([]string, 0, len(ks.verifying))
+	for kid := range ks.verifying {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := dto.JWKSetDTO{Keys: make([]dto.JWKDTO, 0, len(kids))}
+	for _, kid := range kids {
+		vk := ks.verifying[kid]
+		jwk := dto.JWKDTO{Use: "sig", Alg: vk.method.Alg(), Kid: kid}
+
+		switch key := vk.key.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64url(key.N.Bytes())
+			jwk.E = base64url(big.NewInt(int64(key.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (key.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = key.Curve.Params().Name
+			jwk.X = base64url(key.X.FillBytes(make([]byte, size)))
+			jwk.Y = base64url(key.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64url(key)
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func base64url(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
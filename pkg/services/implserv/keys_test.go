@@ -0,0 +1,366 @@
+package implserv
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeKeyPair stores the key as PKCS #8 and its public key as PKIX PEM files
+// and returns their paths.
+func writeKeyPair(t *testing.T, name string, key crypto.Signer) (string, string) {
+	t.Helper()
+
+	private, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	public, err := x509.MarshalPKIXPublicKey(key.Public())
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, name+".pem")
+	publicPath := filepath.Join(dir, name+".pub.pem")
+
+	assert.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: private}), 0600))
+	assert.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}), 0600))
+
+	return privatePath, publicPath
+}
+
+func TestKeySet_signAndParse(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name        string
+		algorithm   string
+		key         crypto.Signer
+		expectedKty string
+	}{
+		{
+			name:        "RS256",
+			algorithm:   "RS256",
+			key:         rsaKey,
+			expectedKty: "RSA",
+		},
+		{
+			name:        "ES256",
+			algorithm:   "ES256",
+			key:         ecKey,
+			expectedKty: "EC",
+		},
+		{
+			name:        "EdDSA",
+			algorithm:   "EdDSA",
+			key:         edKey,
+			expectedKty: "OKP",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			private, _ := writeKeyPair(t, "current", c.key)
+
+			ks, err := newKeySet(config.JWT{
+				Algorithm:  c.algorithm,
+				SigningKey: "current",
+				Keys:       []config.JWTKey{{Kid: "current", PrivateKey: private}},
+			}, "")
+			assert.NoError(t, err)
+
+			jt, err := ks.sign(&jwt.RegisteredClaims{
+				Subject:   "1",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			})
+			assert.NoError(t, err)
+
+			var claims jwt.RegisteredClaims
+			token, err := ks.parse(jt, &claims)
+			assert.NoError(t, err)
+			assert.Equal(t, token.Header["kid"], "current")
+			assert.Equal(t, token.Header["alg"], c.algorithm)
+			assert.Equal(t, claims.Subject, "1")
+
+			jwks := ks.jwks()
+			assert.Len(t, jwks.Keys, 1)
+			assert.Equal(t, jwks.Keys[0].Kid, "current")
+			assert.Equal(t, jwks.Keys[0].Kty, c.expectedKty)
+			assert.Equal(t, jwks.Keys[0].Alg, c.algorithm)
+		})
+	}
+}
+
+func TestKeySet_rotation(t *testing.T) {
+	previousKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	currentKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	previousPrivate, previousPublic := writeKeyPair(t, "previous", previousKey)
+	currentPrivate, _ := writeKeyPair(t, "current", currentKey)
+
+	before, err := newKeySet(config.JWT{
+		Algorithm:  "ES256",
+		SigningKey: "previous",
+		Keys:       []config.JWTKey{{Kid: "previous", PrivateKey: previousPrivate}},
+	}, "")
+	assert.NoError(t, err)
+
+	after, err := newKeySet(config.JWT{
+		Algorithm:  "ES256",
+		SigningKey: "current",
+		Keys: []config.JWTKey{
+			{Kid: "current", PrivateKey: currentPrivate},
+			{Kid: "previous", PublicKey: previousPublic},
+		},
+	}, "")
+	assert.NoError(t, err)
+
+	claims := &jwt.RegisteredClaims{Subject: "1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+
+	old, err := before.sign(claims)
+	assert.NoError(t, err)
+
+	_, err = after.parse(old, &jwt.RegisteredClaims{})
+	assert.NoError(t, err, "tokens of the previous key stay valid after the rotation")
+
+	current, err := after.sign(claims)
+	assert.NoError(t, err)
+
+	_, err = before.parse(current, &jwt.RegisteredClaims{})
+	assert.Error(t, err, "unknown kid")
+
+	jwks := after.jwks()
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, jwks.Keys[0].Kid, "current")
+	assert.Equal(t, jwks.Keys[1].Kid, "previous")
+}
+
+func TestKeySet_algorithmRotation(t *testing.T) {
+	previousKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	_, currentKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	previousPrivate, previousPublic := writeKeyPair(t, "previous", previousKey)
+	currentPrivate, _ := writeKeyPair(t, "current", currentKey)
+
+	hmac, err := newKeySet(config.JWT{}, "signature")
+	assert.NoError(t, err)
+
+	before, err := newKeySet(config.JWT{
+		Algorithm:  "ES256",
+		SigningKey: "previous",
+		Keys:       []config.JWTKey{{Kid: "previous", PrivateKey: previousPrivate}},
+	}, "")
+	assert.NoError(t, err)
+
+	cfg := config.JWT{
+		Algorithm:  "EdDSA",
+		SigningKey: "current",
+		Keys: []config.JWTKey{
+			{Kid: "current", PrivateKey: currentPrivate},
+			{Kid: "previous", Algorithm: "ES256", PublicKey: previousPublic},
+		},
+	}
+	after, err := newKeySet(cfg, "signature")
+	assert.NoError(t, err)
+
+	claims := &jwt.RegisteredClaims{Subject: "1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+
+	old, err := before.sign(claims)
+	assert.NoError(t, err)
+
+	_, err = after.parse(old, &jwt.RegisteredClaims{})
+	assert.NoError(t, err, "tokens of the previous algorithm stay valid after the rotation")
+
+	oldHMAC, err := hmac.sign(claims)
+	assert.NoError(t, err)
+
+	_, err = after.parse(oldHMAC, &jwt.RegisteredClaims{})
+	assert.NoError(t, err, "HS256 tokens stay valid while the secret is set")
+
+	withoutSecret, err := newKeySet(cfg, "")
+	assert.NoError(t, err)
+
+	_, err = withoutSecret.parse(oldHMAC, &jwt.RegisteredClaims{})
+	assert.Error(t, err, "HS256 tokens are rejected once the secret is removed")
+
+	// a key only verifies its own algorithm, even if another key of the set uses the other one
+	forged := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	forged.Header["kid"] = "previous"
+	jt, err := forged.SignedString(currentKey)
+	assert.NoError(t, err)
+
+	_, err = after.parse(jt, &jwt.RegisteredClaims{})
+	assert.Error(t, err)
+
+	jwks := after.jwks()
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, jwks.Keys[0].Kid, "current")
+	assert.Equal(t, jwks.Keys[0].Alg, "EdDSA")
+	assert.Equal(t, jwks.Keys[1].Kid, "previous")
+	assert.Equal(t, jwks.Keys[1].Alg, "ES256")
+}
+
+func TestKeySet_parseRejectsOtherAlgorithms(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	private, public := writeKeyPair(t, "current", key)
+
+	ks, err := newKeySet(config.JWT{
+		Algorithm:  "RS256",
+		SigningKey: "current",
+		Keys:       []config.JWTKey{{Kid: "current", PrivateKey: private}},
+	}, "")
+	assert.NoError(t, err)
+
+	// the public key is known to everybody and must not be accepted as an HMAC secret
+	publicPEM, err := os.ReadFile(public)
+	assert.NoError(t, err)
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.RegisteredClaims{Subject: "1"})
+	forged.Header["kid"] = "current"
+	jt, err := forged.SignedString(publicPEM)
+	assert.NoError(t, err)
+
+	_, err = ks.parse(jt, &jwt.RegisteredClaims{})
+	assert.Error(t, err)
+}
+
+func TestNewKeySet(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	private, public := writeKeyPair(t, "key", ecKey)
+	p384Private, _ := writeKeyPair(t, "p384", p384Key)
+
+	cases := []struct {
+		name        string
+		cfg         config.JWT
+		expectedErr bool
+	}{
+		{
+			name: "HS256 by default",
+			cfg:  config.JWT{},
+		},
+		{
+			name:        "Unsupported algorithm",
+			cfg:         config.JWT{Algorithm: "none"},
+			expectedErr: true,
+		},
+		{
+			name: "Missing signing key",
+			cfg: config.JWT{
+				Algorithm:  "ES256",
+				SigningKey: "other",
+				Keys:       []config.JWTKey{{Kid: "key", PrivateKey: private}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "Signing key without private key",
+			cfg: config.JWT{
+				Algorithm:  "ES256",
+				SigningKey: "key",
+				Keys:       []config.JWTKey{{Kid: "key", PublicKey: public}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "Duplicate kid",
+			cfg: config.JWT{
+				Algorithm:  "ES256",
+				SigningKey: "key",
+				Keys: []config.JWTKey{
+					{Kid: "key", PrivateKey: private},
+					{Kid: "key", PublicKey: public},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "Wrong curve",
+			cfg: config.JWT{
+				Algorithm:  "ES256",
+				SigningKey: "key",
+				Keys:       []config.JWTKey{{Kid: "key", PrivateKey: p384Private}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "HS256 key",
+			cfg: config.JWT{
+				Keys: []config.JWTKey{{Kid: "key", PublicKey: public}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "Unsupported key algorithm",
+			cfg: config.JWT{
+				Algorithm:  "ES256",
+				SigningKey: "key",
+				Keys: []config.JWTKey{
+					{Kid: "key", PrivateKey: private},
+					{Kid: "other", Algorithm: "none", PublicKey: public},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "Signing key of another algorithm",
+			cfg: config.JWT{
+				Algorithm:  "EdDSA",
+				SigningKey: "key",
+				Keys:       []config.JWTKey{{Kid: "key", Algorithm: "ES256", PrivateKey: private}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "Missing file",
+			cfg: config.JWT{
+				Algorithm:  "ES256",
+				SigningKey: "key",
+				Keys:       []config.JWTKey{{Kid: "key", PrivateKey: filepath.Join(t.TempDir(), "missing.pem")}},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ks, err := newKeySet(c.cfg, "signature")
+			if c.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Empty(t, ks.jwks().Keys)
+			}
+		})
+	}
+}
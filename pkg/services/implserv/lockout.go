@@ -0,0 +1,206 @@
+package implserv
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/lockout"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultLockoutThreshold     = 5
+	defaultLockoutWindow        = 15 * time.Minute
+	defaultLockoutBaseDelay     = time.Minute
+	defaultLockoutMaxDelay      = time.Hour
+	defaultLockoutPurgeInterval = time.Hour
+)
+
+// lockoutPolicy locks a key after threshold failed sign ins within window.
+// The lock lasts baseDelay and doubles with every further failure up to maxDelay.
+type lockoutPolicy struct {
+	threshold int
+	window    time.Duration
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+func newLockoutPolicy(cfg config.Lockout) lockoutPolicy {
+	p := lockoutPolicy{
+		threshold: cfg.Threshold,
+		window:    durationOr(cfg.Window, defaultLockoutWindow),
+		baseDelay: durationOr(cfg.BaseDelay, defaultLockoutBaseDelay),
+		maxDelay:  durationOr(cfg.MaxDelay, defaultLockoutMaxDelay),
+	}
+	if p.threshold == 0 {
+		p.threshold = defaultLockoutThreshold
+	}
+
+	return p
+}
+
+// delay returns how long a key is locked after the failures, 0 below the threshold.
+func (p lockoutPolicy) delay(failures int) time.Duration {
+	if failures < p.threshold {
+		return 0
+	}
+
+	d := p.baseDelay
+	for i := p.threshold; i < failures && d < p.maxDelay; i++ {
+		d *= 2
+	}
+
+	return min(d, p.maxDelay)
+}
+
+// signInKeys returns the keys failed sign ins are counted by: the account, so its
+// password can not be guessed from many addresses whichever identifier is sent,
+// and the client IP, so it can not guess the passwords of many accounts.
+// Unknown users are counted by the identifier instead of the account.
+func signInKeys(user entity.User, si dto.SignInDTO, ip string) []string {
+	key := "user:" + strconv.Itoa(user.Id)
+	if user.Id == 0 {
+		key = "login:" + strings.ToLower(si.Email+si.Username)
+	}
+
+	keys := []string{key}
+	if ip != "" {
+		keys = append(keys, "ip:"+ip)
+	}
+
+	return keys
+}
+
+// checkLockout returns errSignInLocked with the longest remaining lock if any of the keys is locked.
+func (service *AuthServiceImpl) checkLockout(keys []string) error {
+	now := time.Now()
+
+	var retryAfter time.Duration
+	for _, key := range keys {
+		a, err := service.attempts.Get(key)
+		if err != nil {
+			return err
+		}
+
+		if a.Locked(now) {
+			retryAfter = max(retryAfter, a.LockedUntil.Sub(now))
+		}
+	}
+
+	if retryAfter > 0 {
+		return errSignInLocked(retryAfter)
+	}
+
+	return nil
+}
+
+// recordFailure counts a failed sign in for every key and locks the keys that reached
+// the threshold. A failure of the store must not change the response of the sign in.
+func (service *AuthServiceImpl) recordFailure(keys []string, userId int64, client dto.ClientInfo) {
+	now := time.Now()
+
+	for _, key := range keys {
+		if err := service.failKey(key, now, userId, client); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"key":   key,
+				"error": err,
+			}).Error("failed to record failed sign in")
+		}
+	}
+}
+
+func (service *AuthServiceImpl) failKey(key string, now time.Time, userId int64, client dto.ClientInfo) error {
+	a, err := service.attempts.Fail(key, now, service.cfg.lockout.window)
+	if err != nil {
+		return err
+	}
+
+	delay := service.cfg.lockout.delay(a.Failures)
+	if delay == 0 {
+		return nil
+	}
+
+	until := now.Add(delay)
+	if err := service.attempts.Lock(key, until); err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":        "sign_in_locked",
+		"key":          key,
+		"failures":     a.Failures,
+		"locked_until": until,
+	}).Error("sign in locked after failed attempts")
+	service.audit(entity.AuditSignInLocked, userId, client,
+		fmt.Sprintf("%s locked until %s after %d failures", key, until.Format(time.RFC3339), a.Failures))
+
+	return nil
+}
+
+// resetFailures forgets the failures of the account once the user is fully authenticated.
+// The client IP keeps its count, so signing in to one account does not allow
+// guessing the passwords of others.
+func (service *AuthServiceImpl) resetFailures(key string, userId int64, client dto.ClientInfo) {
+	a, err := service.attempts.Reset(key)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"key":   key,
+			"error": err,
+		}).Error("failed to reset failed sign ins")
+		return
+	}
+
+	if !a.LockedUntil.IsZero() {
+		logrus.WithFields(logrus.Fields{
+			"event":    "sign_in_unlocked",
+			"key":      key,
+			"failures": a.Failures,
+		}).Error("sign in unlocked after a successful attempt")
+		service.audit(entity.AuditSignInUnlocked, userId, client, key)
+	}
+}
+
+// LockoutRetention deletes the attempts of the store that neither count nor lock anymore,
+// so that the keys of one-off clients do not pile up.
+type LockoutRetention struct {
+	store    lockout.Store
+	window   time.Duration
+	interval time.Duration
+}
+
+func NewLockoutRetention(store lockout.Store, cfg config.Lockout) *LockoutRetention {
+	return &LockoutRetention{
+		store:    store,
+		window:   durationOr(cfg.Window, defaultLockoutWindow),
+		interval: durationOr(cfg.PurgeInterval, defaultLockoutPurgeInterval),
+	}
+}
+
+// Purge deletes the attempts that expired at now and returns how many were deleted.
+func (r *LockoutRetention) Purge(now time.Time) (int64, error) {
+	return r.store.Purge(now.Add(-r.window))
+}
+
+// Run purges expired attempts every interval until the context is done.
+func (r *LockoutRetention) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Purge(time.Now()); err != nil {
+			logrus.WithField("error", err).Error("failed to purge sign in attempts")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
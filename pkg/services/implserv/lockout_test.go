@@ -0,0 +1,260 @@
+package implserv
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	mock_lockout "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/lockout/mocks"
+	mock_repositories "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories/mocks"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockoutPolicy_delay(t *testing.T) {
+	p := newLockoutPolicy(config.Lockout{Threshold: 3, BaseDelay: time.Minute, MaxDelay: 5 * time.Minute})
+
+	cases := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 1, expected: 0},
+		{failures: 2, expected: 0},
+		{failures: 3, expected: time.Minute},
+		{failures: 4, expected: 2 * time.Minute},
+		{failures: 5, expected: 4 * time.Minute},
+		{failures: 6, expected: 5 * time.Minute},
+		{failures: 60, expected: 5 * time.Minute},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, p.delay(c.failures), c.expected, "failures: %d", c.failures)
+	}
+}
+
+func TestNewLockoutPolicy_defaults(t *testing.T) {
+	p := newLockoutPolicy(config.Lockout{})
+
+	assert.Equal(t, p, lockoutPolicy{
+		threshold: defaultLockoutThreshold,
+		window:    defaultLockoutWindow,
+		baseDelay: defaultLockoutBaseDelay,
+		maxDelay:  defaultLockoutMaxDelay,
+	})
+}
+
+func TestSignInKeys(t *testing.T) {
+	cases := []struct {
+		name     string
+		user     entity.User
+		input    dto.SignInDTO
+		ip       string
+		expected []string
+	}{
+		{
+			name:     "Known user",
+			user:     entity.User{Id: 1},
+			input:    dto.SignInDTO{Username: "username"},
+			ip:       "192.0.2.1",
+			expected: []string{"user:1", "ip:192.0.2.1"},
+		},
+		{
+			name:     "Unknown username",
+			input:    dto.SignInDTO{Username: "UserName"},
+			ip:       "192.0.2.1",
+			expected: []string{"login:username", "ip:192.0.2.1"},
+		},
+		{
+			name:     "Unknown email without IP",
+			input:    dto.SignInDTO{Email: "Email@gmail.com"},
+			expected: []string{"login:email@gmail.com"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, signInKeys(c.user, c.input, c.ip), c.expected)
+		})
+	}
+}
+
+func TestAuthService_SignInLockout(t *testing.T) {
+	type attempt struct {
+		input dto.SignInDTO
+		ip    string
+	}
+
+	cases := []struct {
+		name        string
+		attempts    []attempt
+		expectedErr bool
+	}{
+		{
+			name: "Account is locked after the threshold",
+			attempts: []attempt{
+				{input: dto.SignInDTO{Username: "username", Password: "invalid_password"}, ip: "192.0.2.1"},
+				{input: dto.SignInDTO{Email: "email@gmail.com", Password: "invalid_password"}, ip: "192.0.2.2"},
+				{input: dto.SignInDTO{Username: "username", Password: "password"}, ip: "192.0.2.3"},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "IP is locked across users",
+			attempts: []attempt{
+				{input: dto.SignInDTO{Username: "first", Password: "invalid_password"}, ip: "192.0.2.1"},
+				{input: dto.SignInDTO{Username: "second", Password: "invalid_password"}, ip: "192.0.2.1"},
+				{input: dto.SignInDTO{Username: "username", Password: "password"}, ip: "192.0.2.1"},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "Success resets the account",
+			attempts: []attempt{
+				{input: dto.SignInDTO{Username: "username", Password: "invalid_password"}, ip: "192.0.2.1"},
+				{input: dto.SignInDTO{Username: "username", Password: "password"}, ip: "192.0.2.2"},
+				{input: dto.SignInDTO{Username: "username", Password: "invalid_password"}, ip: "192.0.2.3"},
+				{input: dto.SignInDTO{Username: "username", Password: "password"}, ip: "192.0.2.4"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			serv := newTestAuthService(t, repo, &config.Config{
+				Password: testPasswordConfig,
+				SignIn:   config.SignIn{Lockout: config.Lockout{Threshold: 2, BaseDelay: time.Minute}},
+			})
+
+			hash, err := serv.HashPassword("password")
+			assert.NoError(t, err)
+
+			user := entity.User{Id: 1, Email: "email@gmail.com", PasswordHash: hash}
+			repo.EXPECT().GetByEmail("email@gmail.com").Return(user, nil).AnyTimes()
+			repo.EXPECT().GetByUsername("username").Return(user, nil).AnyTimes()
+			repo.EXPECT().GetByUsername(gomock.Not("username")).Return(entity.User{}, sql.ErrNoRows).AnyTimes()
+
+			last := c.attempts[len(c.attempts)-1]
+			for _, a := range c.attempts[:len(c.attempts)-1] {
+				_, _, _ = serv.SignIn(a.input, dto.ClientInfo{IP: a.ip})
+			}
+
+			_, _, err = serv.SignIn(last.input, dto.ClientInfo{IP: last.ip})
+
+			if c.expectedErr {
+				var domainErr *apperr.Error
+				assert.ErrorAs(t, err, &domainErr)
+				assert.Equal(t, domainErr.Code, "sign_in_locked")
+				assert.InDelta(t, domainErr.RetryAfter, time.Minute, float64(time.Second))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAuthService_SignInTwoFactorLockout(t *testing.T) {
+	type attempt struct {
+		password string
+		code     string
+	}
+
+	cases := []struct {
+		name     string
+		attempts []attempt
+	}{
+		{
+			name: "Account is locked after wrong codes",
+			attempts: []attempt{
+				{password: "password", code: "000000"},
+				{password: "password", code: "000000"},
+				{password: "password"},
+			},
+		},
+		{
+			name: "Password alone does not reset the account",
+			attempts: []attempt{
+				{password: "invalid_password"},
+				{password: "password", code: "000000"},
+				{password: "password"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			serv := newTestAuthService(t, repo, &config.Config{
+				Auth:     config.Auth{RefreshSecret: "refresh_secret"},
+				Password: testPasswordConfig,
+				SignIn:   config.SignIn{Lockout: config.Lockout{Threshold: 2, BaseDelay: time.Minute}},
+			})
+
+			hash, err := serv.HashPassword("password")
+			assert.NoError(t, err)
+
+			sealed, err := serv.cfg.totp.seal(rfc6238Secret)
+			assert.NoError(t, err)
+
+			enabledAt := time.Now()
+			user := entity.User{Id: 1, Email: "email@gmail.com", PasswordHash: hash, TOTPSecret: &sealed,
+				TOTPEnabledAt: &enabledAt}
+			challenge := entity.UserToken{UserId: 1, ExpiresAt: time.Now().Add(time.Minute)}
+
+			repo.EXPECT().GetByUsername("username").Return(user, nil).AnyTimes()
+			repo.EXPECT().GetById(int64(1)).Return(user, nil).AnyTimes()
+			repo.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			repo.EXPECT().ConsumeUserToken(gomock.Any(), entity.TokenPurposeTwoFactor).Return(challenge, nil).AnyTimes()
+			repo.EXPECT().ConsumeRecoveryCode(int64(1), gomock.Any()).Return(sql.ErrNoRows).AnyTimes()
+
+			client := dto.ClientInfo{IP: "192.0.2.1"}
+			last := c.attempts[len(c.attempts)-1]
+			for _, a := range c.attempts[:len(c.attempts)-1] {
+				_, token, _ := serv.SignIn(dto.SignInDTO{Username: "username", Password: a.password}, client)
+				if token != "" {
+					_, err := serv.SignInTwoFactor(dto.SignInTwoFactorDTO{ChallengeToken: token, Code: a.code}, client)
+					assert.Equal(t, err, errInvalidTwoFactorCode)
+				}
+			}
+
+			_, _, err = serv.SignIn(dto.SignInDTO{Username: "username", Password: last.password}, client)
+
+			var domainErr *apperr.Error
+			assert.ErrorAs(t, err, &domainErr)
+			assert.Equal(t, domainErr.Code, "sign_in_locked")
+
+			code, err := totpCode(rfc6238Secret, totpStep(time.Now()))
+			assert.NoError(t, err)
+
+			_, err = serv.SignInTwoFactor(dto.SignInTwoFactorDTO{ChallengeToken: "challenge", Code: code}, client)
+
+			assert.ErrorAs(t, err, &domainErr)
+			assert.Equal(t, domainErr.Code, "sign_in_locked")
+		})
+	}
+}
+
+func TestLockoutRetention_Purge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, time.December, 1, 1, 0, 0, 0, time.Local)
+
+	store := mock_lockout.NewMockStore(ctrl)
+	store.EXPECT().Purge(time.Date(2024, time.December, 1, 0, 30, 0, 0, time.Local)).Return(int64(2), nil)
+
+	got, err := NewLockoutRetention(store, config.Lockout{Window: 30 * time.Minute}).Purge(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, got, int64(2))
+}
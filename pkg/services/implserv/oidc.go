@@ -0,0 +1,266 @@
+package implserv
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/oidc"
+)
+
+const (
+	defaultOIDCFlowTTL = 10 * time.Minute
+	oidcTimeout        = 10 * time.Second
+)
+
+// oidcFlow is the state of an OpenID Connect sign in between the start and the
+// callback. The client keeps it encrypted, so that it can be neither read, e.g.
+// the PKCE verifier, nor changed.
+type oidcFlow struct {
+	Provider  string `json:"p"`
+	State     string `json:"s"`
+	Nonce     string `json:"n"`
+	Verifier  string `json:"v"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// StartOIDC returns the address of the provider the user signs in at, with a new
+// state, nonce and PKCE verifier kept in the flow.
+func (service *AuthServiceImpl) StartOIDC(provider string) (dto.OIDCStartDTO, error) {
+	p, ok := service.oidc[provider]
+	if !ok {
+		return dto.OIDCStartDTO{}, errUnknownOIDCProvider
+	}
+
+	f := oidcFlow{Provider: provider}
+	for _, v := range []*string{&f.State, &f.Nonce, &f.Verifier} {
+		token, err := newRandomToken()
+		if err != nil {
+			return dto.OIDCStartDTO{}, err
+		}
+		*v = token
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcTimeout)
+	defer cancel()
+
+	authURL, err := p.AuthCodeURL(ctx, f.State, f.Nonce, f.Verifier)
+	if err != nil {
+		return dto.OIDCStartDTO{}, err
+	}
+
+	expiresAt := time.Now().Add(service.cfg.oidcFlow)
+	f.ExpiresAt = expiresAt.Unix()
+
+	flow, err := service.sealOIDCFlow(f)
+	if err != nil {
+		return dto.OIDCStartDTO{}, err
+	}
+
+	return dto.OIDCStartDTO{AuthURL: authURL, Flow: flow, ExpiresAt: expiresAt}, nil
+}
+
+// SignInOIDC redeems the code of the callback and returns the id of the user of the
+// provider account, like SignIn a challenge token if two-factor authentication is enabled.
+func (service *AuthServiceImpl) SignInOIDC(provider string, cb dto.OIDCCallbackDTO, flow string,
+	client dto.ClientInfo) (int64, string, error) {
+	p, ok := service.oidc[provider]
+	if !ok {
+		return 0, "", errUnknownOIDCProvider
+	}
+
+	f, err := service.openOIDCFlow(flow)
+	if err != nil || f.Provider != provider || subtle.ConstantTimeCompare([]byte(f.State), []byte(cb.State)) != 1 {
+		service.audit(entity.AuditSignInFailed, 0, client, provider+": invalid state")
+		return 0, "", errInvalidOIDCState
+	}
+
+	if cb.Error != "" {
+		service.audit(entity.AuditSignInFailed, 0, client, provider+": "+cb.Error)
+		return 0, "", errOIDCAccessDenied
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcTimeout)
+	defer cancel()
+
+	claims, err := p.Exchange(ctx, cb.Code, f.Verifier, f.Nonce)
+	if err != nil {
+		switch {
+		case errors.Is(err, oidc.ErrInvalidGrant):
+			service.audit(entity.AuditSignInFailed, 0, client, provider+": invalid code")
+			return 0, "", errInvalidOIDCCode
+		case errors.Is(err, oidc.ErrInvalidIDToken):
+			service.audit(entity.AuditSignInFailed, 0, client, provider+": invalid ID token")
+			return 0, "", errInvalidIDToken
+		}
+		return 0, "", err
+	}
+
+	user, err := service.oidcUser(provider, claims, client)
+	if err != nil {
+		return 0, "", err
+	}
+	userId := int64(user.Id)
+
+	if user.DisabledAt != nil {
+		service.audit(entity.AuditSignInFailed, userId, client, "account disabled")
+		return 0, "", errAccountDisabled
+	}
+
+	if service.cfg.requireVerifiedEmail && user.EmailVerifiedAt == nil {
+		service.audit(entity.AuditSignInFailed, userId, client, "email not verified")
+		return 0, "", errEmailNotVerified
+	}
+
+	if user.TOTPEnabledAt != nil {
+		challenge, err := service.issueUserToken(userId, user.Email, entity.TokenPurposeTwoFactor,
+			service.cfg.twoFactor)
+		if err != nil {
+			return 0, "", err
+		}
+		return 0, challenge, nil
+	}
+
+	return userId, "", nil
+}
+
+// oidcUser returns the user linked to the subject of the provider. A new subject is
+// linked to the user with its email if both the provider and the user verified it,
+// otherwise whoever registered the email first could take over the account of its
+// owner. Without such a user, a new one is created.
+func (service *AuthServiceImpl) oidcUser(provider string, claims oidc.Claims, client dto.ClientInfo) (entity.User, error) {
+	user, err := service.repo.GetByIdentity(provider, claims.Subject)
+	if err != sql.ErrNoRows {
+		return user, err
+	}
+
+	if claims.Email == "" {
+		return entity.User{}, errOIDCEmailRequired
+	}
+
+	identity := &entity.UserIdentity{Provider: provider, Subject: claims.Subject, Email: claims.Email}
+
+	user, err = service.repo.GetByEmail(claims.Email)
+	if err == nil {
+		if !claims.EmailVerified || user.EmailVerifiedAt == nil {
+			return entity.User{}, errOIDCAccountExists
+		}
+
+		identity.UserId = int64(user.Id)
+		if err := service.repo.LinkIdentity(identity); err != nil {
+			return entity.User{}, constraintError(err, identityConstraintErrors)
+		}
+		service.audit(entity.AuditIdentityLinked, identity.UserId, client, provider)
+
+		return user, nil
+	}
+
+	if err != sql.ErrNoRows {
+		return entity.User{}, err
+	}
+
+	return service.signUpOIDC(identity, claims, client)
+}
+
+// signUpOIDC creates a user without a password for the identity. If the username of
+// the provider is taken, a random suffix is added to it.
+func (service *AuthServiceImpl) signUpOIDC(identity *entity.UserIdentity, claims oidc.Claims,
+	client dto.ClientInfo) (entity.User, error) {
+	user := entity.User{
+		Name:     claims.Name,
+		Email:    claims.Email,
+		Username: oidcUsername(claims),
+	}
+	if user.Name == "" {
+		user.Name = user.Username
+	}
+	if claims.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	id, err := service.repo.SignUpWithIdentity(&user, identity)
+	if err != nil && constraintError(err, identityConstraintErrors) == errUsernameTaken {
+		var suffix string
+		if suffix, err = newRandomToken(); err != nil {
+			return entity.User{}, err
+		}
+		user.Username += "_" + suffix[:6]
+
+		id, err = service.repo.SignUpWithIdentity(&user, identity)
+	}
+	if err != nil {
+		return entity.User{}, constraintError(err, identityConstraintErrors)
+	}
+	service.audit(entity.AuditSignUp, id, client, identity.Provider)
+
+	user.Id = int(id)
+	return user, nil
+}
+
+// oidcUsername returns the preferred username of the claims or else the local part of the email.
+func oidcUsername(claims oidc.Claims) string {
+	username := claims.PreferredUsername
+	if username == "" {
+		username = claims.Email
+	}
+
+	username, _, _ = strings.Cut(username, "@")
+	if len(username) < 3 {
+		username = "user"
+	}
+
+	return username
+}
+
+func (service *AuthServiceImpl) sealOIDCFlow(f oidcFlow) (string, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+
+	aead := service.cfg.oidcSealer
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, data, nil)), nil
+}
+
+// openOIDCFlow returns the flow if it was sealed by the service and did not expire.
+func (service *AuthServiceImpl) openOIDCFlow(flow string) (oidcFlow, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(flow)
+	if err != nil {
+		return oidcFlow{}, errInvalidOIDCState
+	}
+
+	aead := service.cfg.oidcSealer
+	if len(sealed) < aead.NonceSize() {
+		return oidcFlow{}, errInvalidOIDCState
+	}
+
+	data, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return oidcFlow{}, errInvalidOIDCState
+	}
+
+	var f oidcFlow
+	if err := json.Unmarshal(data, &f); err != nil {
+		return oidcFlow{}, errInvalidOIDCState
+	}
+
+	if time.Now().After(time.Unix(f.ExpiresAt, 0)) {
+		return oidcFlow{}, errInvalidOIDCState
+	}
+
+	return f, nil
+}
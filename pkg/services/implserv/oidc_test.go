@@ -0,0 +1,295 @@
+package implserv
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/oidc"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/oidc/oidctest"
+	mock_repositories "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestOIDCService creates the service under test with the stub provider as "stub".
+func newTestOIDCService(t *testing.T, repo *mock_repositories.MockAuthRepository) (*AuthServiceImpl, *oidctest.Server) {
+	t.Helper()
+
+	server := oidctest.NewServer(t)
+	serv := newTestAuthService(t, repo, &config.Config{
+		Auth: config.Auth{RefreshSecret: "refresh_secret"},
+		OIDC: config.OIDC{Providers: map[string]config.OIDCProvider{
+			"stub": server.Provider("http://localhost:8000/auth/oidc/stub/callback"),
+		}},
+	})
+
+	return serv, server
+}
+
+func TestAuthService_SignInOIDC(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository)
+
+	verifiedAt := time.Now()
+	usernameTaken := &pq.Error{Code: uniqueViolation, Constraint: "users_username_lower_key"}
+
+	cases := []struct {
+		name         string
+		user         oidctest.User
+		mockBehavior mockBehavior
+		expected     int64
+		expectedErr  error
+	}{
+		{
+			name: "Linked identity",
+			user: oidctest.User{Subject: "subject", Email: "email@gmail.com", EmailVerified: true},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetByIdentity("stub", "subject").Return(entity.User{Id: 1}, nil)
+			},
+			expected: 1,
+		},
+		{
+			name: "New user",
+			user: oidctest.User{Subject: "subject", Email: "email@gmail.com", EmailVerified: true,
+				Name: "name", PreferredUsername: "username"},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetByIdentity("stub", "subject").Return(entity.User{}, sql.ErrNoRows)
+				s.EXPECT().GetByEmail("email@gmail.com").Return(entity.User{}, sql.ErrNoRows)
+				s.EXPECT().SignUpWithIdentity(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(u *entity.User, i *entity.UserIdentity) (int64, error) {
+						assert.Equal(t, u.Name, "name")
+						assert.Equal(t, u.Username, "username")
+						assert.Equal(t, u.PasswordHash, "")
+						assert.NotNil(t, u.EmailVerifiedAt)
+						assert.Equal(t, *i, entity.UserIdentity{Provider: "stub", Subject: "subject", Email: "email@gmail.com"})
+						return 2, nil
+					})
+			},
+			expected: 2,
+		},
+		{
+			name: "New user with a taken username",
+			user: oidctest.User{Subject: "subject", Email: "email@gmail.com"},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetByIdentity("stub", "subject").Return(entity.User{}, sql.ErrNoRows)
+				s.EXPECT().GetByEmail("email@gmail.com").Return(entity.User{}, sql.ErrNoRows)
+				s.EXPECT().SignUpWithIdentity(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(u *entity.User, i *entity.UserIdentity) (int64, error) {
+						assert.Equal(t, u.Username, "email")
+						assert.Nil(t, u.EmailVerifiedAt)
+						return 0, usernameTaken
+					})
+				s.EXPECT().SignUpWithIdentity(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(u *entity.User, i *entity.UserIdentity) (int64, error) {
+						assert.True(t, strings.HasPrefix(u.Username, "email_"))
+						return 2, nil
+					})
+			},
+			expected: 2,
+		},
+		{
+			name: "Linked by verified email",
+			user: oidctest.User{Subject: "subject", Email: "Email@gmail.com", EmailVerified: true},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetByIdentity("stub", "subject").Return(entity.User{}, sql.ErrNoRows)
+				s.EXPECT().GetByEmail("Email@gmail.com").
+					Return(entity.User{Id: 1, Email: "email@gmail.com", EmailVerifiedAt: &verifiedAt}, nil)
+				s.EXPECT().LinkIdentity(&entity.UserIdentity{UserId: 1, Provider: "stub", Subject: "subject",
+					Email: "Email@gmail.com"}).Return(nil)
+			},
+			expected: 1,
+		},
+		{
+			name: "Email not verified by the provider",
+			user: oidctest.User{Subject: "subject", Email: "email@gmail.com"},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetByIdentity("stub", "subject").Return(entity.User{}, sql.ErrNoRows)
+				s.EXPECT().GetByEmail("email@gmail.com").
+					Return(entity.User{Id: 1, Email: "email@gmail.com", EmailVerifiedAt: &verifiedAt}, nil)
+			},
+			expectedErr: errOIDCAccountExists,
+		},
+		{
+			name: "Email not verified by the user",
+			user: oidctest.User{Subject: "subject", Email: "email@gmail.com", EmailVerified: true},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetByIdentity("stub", "subject").Return(entity.User{}, sql.ErrNoRows)
+				s.EXPECT().GetByEmail("email@gmail.com").Return(entity.User{Id: 1, Email: "email@gmail.com"}, nil)
+			},
+			expectedErr: errOIDCAccountExists,
+		},
+		{
+			name: "Without email",
+			user: oidctest.User{Subject: "subject"},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetByIdentity("stub", "subject").Return(entity.User{}, sql.ErrNoRows)
+			},
+			expectedErr: errOIDCEmailRequired,
+		},
+		{
+			name: "Disabled account",
+			user: oidctest.User{Subject: "subject"},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetByIdentity("stub", "subject").Return(entity.User{Id: 1, DisabledAt: &verifiedAt}, nil)
+			},
+			expectedErr: errAccountDisabled,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			c.mockBehavior(repo)
+
+			serv, server := newTestOIDCService(t, repo)
+			server.SetUser(c.user)
+
+			start, err := serv.StartOIDC("stub")
+			assert.NoError(t, err)
+
+			callback := server.Authorize(t, start.AuthURL)
+			cb := dto.OIDCCallbackDTO{Code: callback.Get("code"), State: callback.Get("state")}
+
+			got, challenge, err := serv.SignInOIDC("stub", cb, start.Flow, dto.ClientInfo{})
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+			assert.Equal(t, challenge, "")
+		})
+	}
+}
+
+func TestAuthService_SignInOIDCRejected(t *testing.T) {
+	cases := []struct {
+		name        string
+		provider    string
+		modify      func(cb *dto.OIDCCallbackDTO, flow *string)
+		expectedErr error
+	}{
+		{
+			name:        "Unknown provider",
+			provider:    "other",
+			modify:      func(cb *dto.OIDCCallbackDTO, flow *string) {},
+			expectedErr: errUnknownOIDCProvider,
+		},
+		{
+			name:     "Other state",
+			provider: "stub",
+			modify: func(cb *dto.OIDCCallbackDTO, flow *string) {
+				cb.State = "other"
+			},
+			expectedErr: errInvalidOIDCState,
+		},
+		{
+			name:     "Without flow",
+			provider: "stub",
+			modify: func(cb *dto.OIDCCallbackDTO, flow *string) {
+				*flow = ""
+			},
+			expectedErr: errInvalidOIDCState,
+		},
+		{
+			name:     "Tampered flow",
+			provider: "stub",
+			modify: func(cb *dto.OIDCCallbackDTO, flow *string) {
+				b := []byte(*flow)
+				b[len(b)/2] ^= 1
+				*flow = string(b)
+			},
+			expectedErr: errInvalidOIDCState,
+		},
+		{
+			name:     "Denied by the provider",
+			provider: "stub",
+			modify: func(cb *dto.OIDCCallbackDTO, flow *string) {
+				cb.Code, cb.Error = "", "access_denied"
+			},
+			expectedErr: errOIDCAccessDenied,
+		},
+		{
+			name:     "Unknown code",
+			provider: "stub",
+			modify: func(cb *dto.OIDCCallbackDTO, flow *string) {
+				cb.Code = "other"
+			},
+			expectedErr: errInvalidOIDCCode,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			serv, server := newTestOIDCService(t, mock_repositories.NewMockAuthRepository(ctrl))
+			server.SetUser(oidctest.User{Subject: "subject"})
+
+			start, err := serv.StartOIDC("stub")
+			assert.NoError(t, err)
+
+			callback := server.Authorize(t, start.AuthURL)
+			cb := dto.OIDCCallbackDTO{Code: callback.Get("code"), State: callback.Get("state")}
+			flow := start.Flow
+			c.modify(&cb, &flow)
+
+			got, _, err := serv.SignInOIDC(c.provider, cb, flow, dto.ClientInfo{})
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, int64(0))
+		})
+	}
+}
+
+func TestAuthService_StartOIDC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serv, server := newTestOIDCService(t, mock_repositories.NewMockAuthRepository(ctrl))
+
+	got, err := serv.StartOIDC("stub")
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(got.AuthURL, server.URL+"/authorize?"))
+	assert.WithinDuration(t, got.ExpiresAt, time.Now().Add(defaultOIDCFlowTTL), time.Minute)
+
+	// the flow is encrypted, e.g. the state of the address can not be read from it
+	authURL, err := url.Parse(got.AuthURL)
+	assert.NoError(t, err)
+	flow, err := base64.RawURLEncoding.DecodeString(got.Flow)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(flow), authURL.Query().Get("state"))
+
+	_, err = serv.StartOIDC("other")
+
+	assert.Equal(t, err, errUnknownOIDCProvider)
+}
+
+func TestOIDCUsername(t *testing.T) {
+	cases := []struct {
+		name     string
+		claims   oidc.Claims
+		expected string
+	}{
+		{name: "Preferred username", claims: oidc.Claims{PreferredUsername: "username", Email: "email@gmail.com"},
+			expected: "username"},
+		{name: "Username as email", claims: oidc.Claims{PreferredUsername: "username@corp.com"}, expected: "username"},
+		{name: "Email", claims: oidc.Claims{Email: "email@gmail.com"}, expected: "email"},
+		{name: "Too short", claims: oidc.Claims{Email: "ab@gmail.com"}, expected: "user"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, oidcUsername(c.claims), c.expected)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package implserv
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const argon2idPrefix = "$argon2id$"
+
+var errInvalidPasswordHash = errors.New("invalid password hash format")
+
+type passwordParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	saltLength  uint32
+	keyLength   uint32
+}
+
+var defaultPasswordParams = passwordParams{
+	memory:      64 * 1024,
+	iterations:  3,
+	parallelism: 2,
+	saltLength:  16,
+	keyLength:   32,
+}
+
+// hash encodes the password as an argon2id PHC string:
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+func (p passwordParams) hash(password string) (string, error) {
+	salt := make([]byte, p.saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, p.keyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// verify reports whether password matches the encoded hash and whether the hash
+// should be replaced, either because it is a legacy SHA-256 digest or because
+// it was produced with parameters other than p.
+func (p passwordParams) verify(password, encoded, legacySalt string) (bool, bool, error) {
+	if !strings.HasPrefix(encoded, argon2idPrefix) {
+		legacy := legacyPasswordHash(password, legacySalt)
+		return subtle.ConstantTimeCompare([]byte(legacy), []byte(encoded)) == 1, true, nil
+	}
+
+	stored, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, false, err
+	}
+
+	actual := argon2.IDKey([]byte(password), salt, stored.iterations, stored.memory, stored.parallelism, stored.keyLength)
+	if subtle.ConstantTimeCompare(actual, key) != 1 {
+		return false, false, nil
+	}
+
+	return true, stored != p, nil
+}
+
+func decodeArgon2id(encoded string) (passwordParams, []byte, []byte, error) {
+	var p passwordParams
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, errInvalidPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, nil, nil, errInvalidPasswordHash
+	}
+	if version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("unsupported argon2 version: %d", version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return p, nil, nil, errInvalidPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, errInvalidPasswordHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, errInvalidPasswordHash
+	}
+
+	p.saltLength = uint32(len(salt))
+	p.keyLength = uint32(len(key))
+
+	return p, salt, key, nil
+}
+
+// legacyPasswordHash reproduces the original single-pass SHA-256 scheme so that
+// existing hashes can still be verified and upgraded on the next sign in.
+func legacyPasswordHash(password, salt string) string {
+	h := sha256.New()
+	h.Write([]byte(password))
+
+	return fmt.Sprintf("%x", h.Sum([]byte(salt)))
+}
@@ -0,0 +1,176 @@
+package implserv
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+)
+
+const (
+	// minPasswordLength is the length the DTOs of passwords require,
+	// the policy can only require longer passwords
+	minPasswordLength = 8
+	// breachedPrefixLength is the length of the SHA-1 prefixes the breached list is grouped by
+	breachedPrefixLength = 5
+	// minIdentifierLength is the shortest username or email part passwords are searched for
+	minIdentifierLength = 3
+)
+
+// passwordPolicy decides which passwords users may set.
+type passwordPolicy struct {
+	minLength     int
+	requireUpper  bool
+	requireLower  bool
+	requireDigit  bool
+	requireSymbol bool
+	breached      breachedPasswords
+}
+
+// breachedPasswords are the upper-case hex SHA-1 suffixes of breached passwords by their prefix.
+type breachedPasswords map[string]map[string]struct{}
+
+func newPasswordPolicy(cfg config.PasswordPolicy) (passwordPolicy, error) {
+	p := passwordPolicy{
+		minLength:     max(cfg.MinLength, minPasswordLength),
+		requireUpper:  cfg.RequireUpper,
+		requireLower:  cfg.RequireLower,
+		requireDigit:  cfg.RequireDigit,
+		requireSymbol: cfg.RequireSymbol,
+	}
+
+	if cfg.BreachedFile != "" {
+		breached, err := loadBreachedPasswords(cfg.BreachedFile)
+		if err != nil {
+			return passwordPolicy{}, err
+		}
+		p.breached = breached
+	}
+
+	return p, nil
+}
+
+// loadBreachedPasswords reads a file of SHA-1 hashes in the format of Pwned Passwords,
+// the counts after the hashes and lines starting with # are ignored.
+func loadBreachedPasswords(path string) (breachedPasswords, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	breached := make(breachedPasswords)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(text, ":")
+		hash = strings.ToUpper(hash)
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("%s:%d: invalid SHA-1 hash", path, line)
+		}
+
+		prefix, suffix := hash[:breachedPrefixLength], hash[breachedPrefixLength:]
+		if breached[prefix] == nil {
+			breached[prefix] = make(map[string]struct{})
+		}
+		breached[prefix][suffix] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return breached, nil
+}
+
+// contains looks the password up by the prefix of its SHA-1 hash, like the range
+// API of Pwned Passwords, without sending anything over the network.
+func (b breachedPasswords) contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	_, ok := b[hash[:breachedPrefixLength]][hash[breachedPrefixLength:]]
+	return ok
+}
+
+// check returns every rule the password of the field breaks. The password may
+// not contain the username or the email of its user, either of which can be empty.
+func (p passwordPolicy) check(field, password, username, email string) error {
+	var fields []apperr.FieldError
+	fail := func(rule, detail string) {
+		fields = append(fields, apperr.FieldError{Field: field, Rule: rule, Detail: detail})
+	}
+
+	if utf8.RuneCountInString(password) < p.minLength {
+		fail("min_length", fmt.Sprintf("must be at least %d characters long", p.minLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r), unicode.IsSymbol(r), unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	if p.requireUpper && !upper {
+		fail("uppercase", "must contain an uppercase letter")
+	}
+	if p.requireLower && !lower {
+		fail("lowercase", "must contain a lowercase letter")
+	}
+	if p.requireDigit && !digit {
+		fail("digit", "must contain a digit")
+	}
+	if p.requireSymbol && !symbol {
+		fail("symbol", "must contain a symbol")
+	}
+
+	lowered := strings.ToLower(password)
+	if containsIdentifier(lowered, username) {
+		fail("username", "must not contain the username")
+	}
+
+	// a password with the whole email contains its local part too
+	local, _, _ := strings.Cut(email, "@")
+	if containsIdentifier(lowered, local) {
+		fail("email", "must not contain the email address")
+	}
+
+	if p.breached.contains(password) {
+		fail("breached", "appears in a list of breached passwords, choose another one")
+	}
+
+	if len(fields) > 0 {
+		return errWeakPassword(fields)
+	}
+
+	return nil
+}
+
+// containsIdentifier reports whether the lower-case password contains the identifier,
+// identifiers too short to be telling are ignored.
+func containsIdentifier(password, identifier string) bool {
+	if utf8.RuneCountInString(identifier) < minIdentifierLength {
+		return false
+	}
+
+	return strings.Contains(password, strings.ToLower(identifier))
+}
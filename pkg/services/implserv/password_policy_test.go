@@ -0,0 +1,135 @@
+package implserv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	"github.com/stretchr/testify/assert"
+)
+
+// testBreachedPasswords contains "password1".
+var testBreachedPasswords = breachedPasswords{
+	"E38AD": {"214943DAAD1D64C102FAEC29DE4AFE9DA3D": {}},
+}
+
+func TestPasswordPolicy_check(t *testing.T) {
+	policy := passwordPolicy{
+		minLength:     10,
+		requireUpper:  true,
+		requireLower:  true,
+		requireDigit:  true,
+		requireSymbol: true,
+		breached:      testBreachedPasswords,
+	}
+
+	cases := []struct {
+		name     string
+		policy   passwordPolicy
+		password string
+		expected []apperr.FieldError
+	}{
+		{
+			name:     "OK",
+			policy:   policy,
+			password: "Correct-Horse-9",
+		},
+		{
+			name:     "Every class missing",
+			policy:   policy,
+			password: "        ",
+			expected: []apperr.FieldError{
+				{Field: "password", Rule: "min_length", Detail: "must be at least 10 characters long"},
+				{Field: "password", Rule: "uppercase", Detail: "must contain an uppercase letter"},
+				{Field: "password", Rule: "lowercase", Detail: "must contain a lowercase letter"},
+				{Field: "password", Rule: "digit", Detail: "must contain a digit"},
+			},
+		},
+		{
+			name:     "Length in characters",
+			policy:   passwordPolicy{minLength: 8},
+			password: "пароль",
+			expected: []apperr.FieldError{
+				{Field: "password", Rule: "min_length", Detail: "must be at least 8 characters long"},
+			},
+		},
+		{
+			name:     "Username and email",
+			policy:   passwordPolicy{minLength: 8},
+			password: "JohnDoe+John.Doe",
+			expected: []apperr.FieldError{
+				{Field: "password", Rule: "username", Detail: "must not contain the username"},
+				{Field: "password", Rule: "email", Detail: "must not contain the email address"},
+			},
+		},
+		{
+			name:     "Breached",
+			policy:   passwordPolicy{minLength: 8, breached: testBreachedPasswords},
+			password: "password1",
+			expected: []apperr.FieldError{
+				{Field: "password", Rule: "breached", Detail: "appears in a list of breached passwords, choose another one"},
+			},
+		},
+		{
+			name:     "Breached with another case",
+			policy:   passwordPolicy{minLength: 8, breached: testBreachedPasswords},
+			password: "Password1",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.policy.check("password", c.password, "johndoe", "john.doe@mail.com")
+
+			if c.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, err, errWeakPassword(c.expected))
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_checkShortIdentifiers(t *testing.T) {
+	policy := passwordPolicy{minLength: 8}
+
+	// a username or email of two characters would reject too many passwords
+	assert.NoError(t, policy.check("password", "jo-at-the-lake", "jo", "jo@mail.com"))
+}
+
+func TestLoadBreachedPasswords(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.txt")
+	assert.NoError(t, os.WriteFile(valid, []byte("# comment\n\ne38ad214943daad1d64c102faec29de4afe9da3d:2413945\n"), 0o600))
+
+	breached, err := loadBreachedPasswords(valid)
+
+	assert.NoError(t, err)
+	assert.Equal(t, breached, testBreachedPasswords)
+
+	invalid := filepath.Join(dir, "invalid.txt")
+	assert.NoError(t, os.WriteFile(invalid, []byte("password1\n"), 0o600))
+
+	_, err = loadBreachedPasswords(invalid)
+
+	assert.EqualError(t, err, invalid+":1: invalid SHA-1 hash")
+
+	_, err = loadBreachedPasswords(filepath.Join(dir, "missing.txt"))
+
+	assert.Error(t, err)
+}
+
+func TestNewPasswordPolicy(t *testing.T) {
+	policy, err := newPasswordPolicy(config.PasswordPolicy{
+		MinLength:    4,
+		BreachedFile: "../../../configs/breached_passwords.txt",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, policy.minLength, minPasswordLength, "the policy can not allow shorter passwords than the DTOs")
+	assert.True(t, policy.breached.contains("password1"))
+	assert.False(t, policy.breached.contains("Correct-Horse-9"))
+}
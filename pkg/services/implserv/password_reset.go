@@ -0,0 +1,129 @@
+package implserv
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/mailer"
+)
+
+const defaultPasswordResetTTL = time.Hour
+
+// ForgotPassword sends a password reset token and invalidates the previous ones.
+// Unknown emails are ignored, so the response does not reveal whether an account exists.
+func (service *AuthServiceImpl) ForgotPassword(email string) error {
+	user, err := service.repo.GetByEmail(email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	if err := service.repo.DeleteUserTokens(int64(user.Id), entity.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	token, err := service.issueUserToken(int64(user.Id), user.Email, entity.TokenPurposePasswordReset,
+		service.cfg.passwordReset)
+	if err != nil {
+		return err
+	}
+
+	return service.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("To set a new password open %s/reset-password?token=%s\n\n"+
+			"The link expires in %s. If you did not request a password reset, ignore this email.",
+			service.cfg.baseURL, token, service.cfg.passwordReset),
+	})
+}
+
+// ResetPassword consumes the reset token, sets the new password and returns
+// the id of the user. Every session of the user is revoked. A password the
+// policy rejects leaves the token valid, so that another one can be chosen.
+func (service *AuthServiceImpl) ResetPassword(rp dto.ResetPasswordDTO, client dto.ClientInfo) (int64, error) {
+	t, err := service.repo.ConsumeUserToken(service.hashToken(rp.Token), entity.TokenPurposePasswordReset)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errInvalidResetToken
+		}
+		return 0, err
+	}
+
+	if time.Now().After(t.ExpiresAt) {
+		return 0, errExpiredResetToken
+	}
+
+	user, err := service.repo.GetById(t.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errInvalidResetToken
+		}
+		return 0, err
+	}
+
+	// the email was changed after the token was sent
+	if user.Email != t.Email {
+		return 0, errInvalidResetToken
+	}
+
+	if policyErr := service.cfg.policy.check("password", rp.Password, user.Username, user.Email); policyErr != nil {
+		// the token was consumed, it is stored again for the next attempt
+		if err := service.repo.CreateUserToken(&t, service.hashToken(rp.Token)); err != nil {
+			return 0, err
+		}
+		return 0, policyErr
+	}
+
+	if err := service.setPassword(t.UserId, rp.Password); err != nil {
+		return 0, err
+	}
+	service.audit(entity.AuditPasswordReset, t.UserId, client, "")
+
+	return t.UserId, nil
+}
+
+// ChangePassword sets the new password of the user if the current one is correct.
+// Users signed up with OpenID Connect have none and set their first password.
+// Every session of the user is revoked.
+func (service *AuthServiceImpl) ChangePassword(userId int64, cp dto.ChangePasswordDTO, client dto.ClientInfo) error {
+	user, err := service.repo.GetById(userId)
+	if err != nil {
+		return err
+	}
+
+	if user.PasswordHash != "" {
+		ok, _, err := service.cfg.password.verify(cp.CurrentPassword, user.PasswordHash, service.cfg.salt)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return errInvalidCurrentPassword
+		}
+	}
+
+	if err := service.cfg.policy.check("new_password", cp.NewPassword, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	if err := service.setPassword(userId, cp.NewPassword); err != nil {
+		return err
+	}
+	service.audit(entity.AuditPasswordChanged, userId, client, "")
+
+	return nil
+}
+
+func (service *AuthServiceImpl) setPassword(userId int64, password string) error {
+	passwordHash, err := service.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return service.repo.ChangePassword(userId, passwordHash)
+}
@@ -0,0 +1,277 @@
+package implserv
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/mailer"
+	mock_mailer "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/mailer/mocks"
+	mock_repositories "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories/mocks"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/services/apperr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthService_ForgotPassword(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, email string)
+
+	cases := []struct {
+		name         string
+		email        string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:  "OK",
+			email: "email@gmail.com",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, email string) {
+				s.EXPECT().GetByEmail(email).Return(entity.User{Id: 1, Email: email}, nil)
+				s.EXPECT().DeleteUserTokens(int64(1), entity.TokenPurposePasswordReset).Return(nil)
+				s.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(token *entity.UserToken, tokenHash string) error {
+						assert.Equal(t, token.UserId, int64(1))
+						assert.Equal(t, token.Purpose, entity.TokenPurposePasswordReset)
+						assert.Equal(t, token.Email, email)
+						assert.WithinDuration(t, token.ExpiresAt, time.Now().Add(defaultPasswordResetTTL), time.Minute)
+						return nil
+					})
+				m.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg mailer.Message) error {
+					assert.Equal(t, msg.To, email)
+					assert.Contains(t, msg.Body, "http://app/reset-password?token=")
+					return nil
+				})
+			},
+		},
+		{
+			name:  "Unknown email",
+			email: "unknown@gmail.com",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, email string) {
+				s.EXPECT().GetByEmail(email).Return(entity.User{}, sql.ErrNoRows)
+			},
+		},
+		{
+			name:  "Repository error",
+			email: "email@gmail.com",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, email string) {
+				s.EXPECT().GetByEmail(email).Return(entity.User{Id: 1, Email: email}, nil)
+				s.EXPECT().DeleteUserTokens(int64(1), entity.TokenPurposePasswordReset).Return(errSome)
+			},
+			expectedErr: errSome,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			mail := mock_mailer.NewMockMailer(ctrl)
+			c.mockBehavior(repo, mail, c.email)
+
+			serv := newTestAuthService(t, repo, &config.Config{Mailer: config.Mailer{BaseURL: "http://app"}})
+			serv.mailer = mail
+
+			err := serv.ForgotPassword(c.email)
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository, tokenHash string)
+
+	token := entity.UserToken{
+		UserId:    1,
+		Purpose:   entity.TokenPurposePasswordReset,
+		Email:     "email@gmail.com",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	cases := []struct {
+		name         string
+		password     string
+		mockBehavior mockBehavior
+		expected     int64
+		expectedErr  error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, tokenHash string) {
+				s.EXPECT().ConsumeUserToken(tokenHash, entity.TokenPurposePasswordReset).Return(token, nil)
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, Email: "email@gmail.com"}, nil)
+				s.EXPECT().ChangePassword(int64(1), gomock.Any()).
+					DoAndReturn(func(userId int64, passwordHash string) error {
+						assert.True(t, strings.HasPrefix(passwordHash, argon2idPrefix))
+						return nil
+					})
+			},
+			expected: 1,
+		},
+		{
+			name: "Unknown token",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, tokenHash string) {
+				s.EXPECT().ConsumeUserToken(tokenHash, entity.TokenPurposePasswordReset).
+					Return(entity.UserToken{}, sql.ErrNoRows)
+			},
+			expectedErr: errInvalidResetToken,
+		},
+		{
+			name: "Expired token",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, tokenHash string) {
+				expired := token
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				s.EXPECT().ConsumeUserToken(tokenHash, entity.TokenPurposePasswordReset).Return(expired, nil)
+			},
+			expectedErr: errExpiredResetToken,
+		},
+		{
+			name:     "Breached password",
+			password: "password1",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, tokenHash string) {
+				s.EXPECT().ConsumeUserToken(tokenHash, entity.TokenPurposePasswordReset).Return(token, nil)
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, Email: "email@gmail.com"}, nil)
+				// the token stays valid for another password
+				s.EXPECT().CreateUserToken(&token, tokenHash).Return(nil)
+			},
+			expectedErr: errWeakPassword([]apperr.FieldError{{
+				Field:  "password",
+				Rule:   "breached",
+				Detail: "appears in a list of breached passwords, choose another one",
+			}}),
+		},
+		{
+			name: "Email changed",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, tokenHash string) {
+				s.EXPECT().ConsumeUserToken(tokenHash, entity.TokenPurposePasswordReset).Return(token, nil)
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, Email: "new@gmail.com"}, nil)
+			},
+			expectedErr: errInvalidResetToken,
+		},
+		{
+			name: "Repository error",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, tokenHash string) {
+				s.EXPECT().ConsumeUserToken(tokenHash, entity.TokenPurposePasswordReset).Return(token, nil)
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, Email: "email@gmail.com"}, nil)
+				s.EXPECT().ChangePassword(int64(1), gomock.Any()).Return(errSome)
+			},
+			expectedErr: errSome,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			c.mockBehavior(repo, testTokenHash("token"))
+
+			serv := newTestAuthService(t, repo, &config.Config{
+				Auth:     config.Auth{RefreshSecret: "refresh_secret"},
+				Password: testPasswordConfig,
+			})
+			serv.cfg.policy.breached = testBreachedPasswords
+
+			password := c.password
+			if password == "" {
+				password = "new_password"
+			}
+
+			got, err := serv.ResetPassword(dto.ResetPasswordDTO{Token: "token", Password: password}, dto.ClientInfo{})
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+		})
+	}
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository, hash string)
+
+	cases := []struct {
+		name         string
+		input        dto.ChangePasswordDTO
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:  "OK",
+			input: dto.ChangePasswordDTO{CurrentPassword: "password", NewPassword: "new_password"},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+				s.EXPECT().ChangePassword(int64(1), gomock.Any()).
+					DoAndReturn(func(userId int64, passwordHash string) error {
+						assert.NotEqual(t, passwordHash, hash)
+						return nil
+					})
+			},
+		},
+		{
+			name:  "Invalid current password",
+			input: dto.ChangePasswordDTO{CurrentPassword: "invalid_password", NewPassword: "new_password"},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+			},
+			expectedErr: errInvalidCurrentPassword,
+		},
+		{
+			name:  "First password",
+			input: dto.ChangePasswordDTO{NewPassword: "new_password"},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1}, nil)
+				s.EXPECT().ChangePassword(int64(1), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:  "Without current password",
+			input: dto.ChangePasswordDTO{NewPassword: "new_password"},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+			},
+			expectedErr: errInvalidCurrentPassword,
+		},
+		{
+			name:  "Password with the username",
+			input: dto.ChangePasswordDTO{CurrentPassword: "password", NewPassword: "Username2025"},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, Username: "username", PasswordHash: hash}, nil)
+			},
+			expectedErr: errWeakPassword([]apperr.FieldError{
+				{Field: "new_password", Rule: "username", Detail: "must not contain the username"},
+			}),
+		},
+		{
+			name:  "Repository error",
+			input: dto.ChangePasswordDTO{CurrentPassword: "password", NewPassword: "new_password"},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{}, errSome)
+			},
+			expectedErr: errSome,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			serv := newTestAuthService(t, repo, &config.Config{Password: testPasswordConfig})
+
+			hash, err := serv.HashPassword("password")
+			assert.NoError(t, err)
+			c.mockBehavior(repo, hash)
+
+			err = serv.ChangePassword(1, c.input, dto.ClientInfo{})
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
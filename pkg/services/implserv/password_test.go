@@ -0,0 +1,99 @@
+package implserv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPasswordConfig = config.Password{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+}
+
+func TestPasswordParams_verify(t *testing.T) {
+	params := newPasswordParams(testPasswordConfig)
+
+	hash, err := params.hash("password")
+	assert.NoError(t, err)
+
+	outdated, err := passwordParams{memory: 512, iterations: 1, parallelism: 1, saltLength: 8, keyLength: 16}.
+		hash("password")
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name           string
+		password       string
+		encoded        string
+		expectedOk     bool
+		expectedRehash bool
+		expectedErr    bool
+	}{
+		{
+			name:       "OK",
+			password:   "password",
+			encoded:    hash,
+			expectedOk: true,
+		},
+		{
+			name:     "Invalid password",
+			password: "invalid_password",
+			encoded:  hash,
+		},
+		{
+			name:           "Legacy hash",
+			password:       "password",
+			encoded:        legacyPasswordHash("password", "salt"),
+			expectedOk:     true,
+			expectedRehash: true,
+		},
+		{
+			name:           "Outdated parameters",
+			password:       "password",
+			encoded:        outdated,
+			expectedOk:     true,
+			expectedRehash: true,
+		},
+		{
+			name:        "Malformed hash",
+			password:    "password",
+			encoded:     argon2idPrefix + "v=19$m=1024",
+			expectedErr: true,
+		},
+		{
+			name:        "Unsupported version",
+			password:    "password",
+			encoded:     strings.Replace(hash, "v=19", "v=16", 1),
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ok, rehash, err := params.verify(c.password, c.encoded, "salt")
+			if c.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, ok, c.expectedOk)
+				assert.Equal(t, rehash, c.expectedRehash)
+			}
+		})
+	}
+}
+
+func TestPasswordParams_hash(t *testing.T) {
+	params := newPasswordParams(testPasswordConfig)
+
+	first, err := params.hash("password")
+	assert.NoError(t, err)
+
+	second, err := params.hash("password")
+	assert.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, argon2idPrefix+"v=19$m=1024,t=1,p=1$"))
+	assert.NotEqual(t, first, second)
+}
@@ -1,6 +1,11 @@
 package implserv
 
 import (
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories"
@@ -21,27 +26,116 @@ func (service *ProjectServiceImpl) Create(p dto.ProjectDTO, userId int64) (int64
 	return service.repo.Create(project)
 }
 
-func (service *ProjectServiceImpl) GetById(id int64, userId int64) (dto.ProjectDTO, error) {
+func (service *ProjectServiceImpl) GetById(id int64, userId int64) (dto.ProjectResponseDTO, error) {
 	project, err := service.repo.GetById(id, userId)
+	if err != nil {
+		return dto.ProjectResponseDTO{}, projectError(err)
+	}
 
-	return *project.ToDTO(), err
+	return *project.ToResponseDTO(), nil
 }
 
-func (service *ProjectServiceImpl) GetAll(userId int64) ([]dto.ProjectDTO, error) {
+func (service *ProjectServiceImpl) GetAll(userId int64) ([]dto.ProjectResponseDTO, error) {
 	projects, err := service.repo.GetAll(userId)
 
-	dtos := make([]dto.ProjectDTO, len(projects))
+	dtos := make([]dto.ProjectResponseDTO, len(projects))
 	for i, p := range projects {
-		dtos[i] = *p.ToDTO()
+		dtos[i] = *p.ToResponseDTO()
 	}
 
 	return dtos, err
 }
 
+func (service *ProjectServiceImpl) List(userId int64, query dto.ProjectQueryDTO) (dto.ProjectPageDTO, error) {
+	query.SetDefaults()
+
+	limit := query.Limit
+	// one extra row tells whether there is a next page
+	query.Limit++
+
+	projects, err := service.repo.List(userId, query)
+	if err != nil {
+		return dto.ProjectPageDTO{}, err
+	}
+
+	var next string
+	if len(projects) > limit {
+		projects = projects[:limit]
+		next = projectCursor(projects[limit-1], query.Sort).Encode()
+	}
+
+	items := make([]dto.ProjectResponseDTO, len(projects))
+	for i, p := range projects {
+		items[i] = *p.ToResponseDTO()
+	}
+
+	return dto.ProjectPageDTO{Items: items, NextCursor: next}, nil
+}
+
 func (service *ProjectServiceImpl) UpdateById(id int64, input dto.UpdateProjectDTO, userId int64) error {
-	return service.repo.UpdateById(id, input, userId)
+	err := service.repo.UpdateById(id, input, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.roleError(id, userId, dto.ProjectRoleOwner, dto.ProjectRoleEditor)
+	}
+
+	return err
 }
 
+// DeleteById deletes the project, only its owner may delete it.
 func (service *ProjectServiceImpl) DeleteById(id int64, userId int64) error {
-	return service.repo.DeleteById(id, userId)
+	err := service.repo.DeleteById(id, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.roleError(id, userId, dto.ProjectRoleOwner)
+	}
+
+	return err
+}
+
+// roleError explains why a statement on the project itself that requires one of
+// the roles changed nothing. With one of the roles the project was deleted meanwhile.
+func (service *ProjectServiceImpl) roleError(projectId int64, userId int64, roles ...string) error {
+	if err := requireProjectRole(service.repo, projectId, userId, roles...); err != nil {
+		return err
+	}
+
+	return errProjectNotFound
+}
+
+// requireProjectRole returns nil if the user has one of the roles in the project. Projects
+// the user is not a member of are reported as not found, so that they stay invisible.
+func requireProjectRole(projects repositories.ProjectRepository, projectId int64, userId int64, roles ...string) error {
+	role, err := projects.GetMemberRole(projectId, userId)
+	if err != nil {
+		return projectError(err)
+	}
+
+	if !slices.Contains(roles, role) {
+		return errProjectForbidden
+	}
+
+	return nil
+}
+
+// projectError reports a missing project, including a project the user is not a member of, as not found.
+func projectError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return errProjectNotFound
+	}
+
+	return err
+}
+
+func projectCursor(p entity.Project, sort string) dto.ProjectCursor {
+	cursor := dto.ProjectCursor{Sort: sort, Id: p.Id}
+
+	switch sort {
+	case "title":
+		cursor.Value = p.Title
+	case "updated_at":
+		cursor.Value = p.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		cursor.Value = p.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	return cursor
 }
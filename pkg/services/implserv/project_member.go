@@ -0,0 +1,129 @@
+package implserv
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+)
+
+// GetMembers returns the members of the project to any of its members.
+func (service *ProjectServiceImpl) GetMembers(projectId int64, userId int64) ([]dto.ProjectMemberDTO, error) {
+	members, err := service.repo.GetMembers(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(members) == 0 {
+		return nil, errProjectNotFound
+	}
+
+	dtos := make([]dto.ProjectMemberDTO, len(members))
+	for i, m := range members {
+		dtos[i] = m.ToDTO()
+	}
+
+	return dtos, nil
+}
+
+// AddMember invites a user to the project, only the owner may add members.
+func (service *ProjectServiceImpl) AddMember(projectId int64, userId int64,
+	input dto.AddProjectMemberDTO) (dto.ProjectMemberDTO, error) {
+	member, err := service.repo.AddMember(projectId, userId, input)
+	if errors.Is(err, sql.ErrNoRows) {
+		if err := requireProjectRole(service.repo, projectId, userId, dto.ProjectRoleOwner); err != nil {
+			return dto.ProjectMemberDTO{}, err
+		}
+
+		return dto.ProjectMemberDTO{}, errUserNotFound
+	}
+
+	if err != nil {
+		return dto.ProjectMemberDTO{}, constraintError(err, memberConstraintErrors)
+	}
+
+	return member.ToDTO(), nil
+}
+
+// UpdateMemberRole changes the role of a member, only the owner may change roles.
+func (service *ProjectServiceImpl) UpdateMemberRole(projectId int64, userId int64, memberId int64,
+	input dto.UpdateProjectMemberDTO) error {
+	err := service.repo.UpdateMemberRole(projectId, userId, memberId, input.Role)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err := requireProjectRole(service.repo, projectId, userId, dto.ProjectRoleOwner); err != nil {
+		return err
+	}
+
+	return service.memberError(projectId, memberId)
+}
+
+// RemoveMember removes a member from the project. The owner may remove any other
+// member and every other member may leave the project.
+func (service *ProjectServiceImpl) RemoveMember(projectId int64, userId int64, memberId int64) error {
+	err := service.repo.RemoveMember(projectId, userId, memberId)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if memberId == userId {
+		err := requireProjectRole(service.repo, projectId, userId, dto.ProjectRoleEditor, dto.ProjectRoleViewer)
+		if errors.Is(err, errProjectForbidden) {
+			return errOwnerMembership
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return errProjectNotFound
+	}
+
+	if err := requireProjectRole(service.repo, projectId, userId, dto.ProjectRoleOwner); err != nil {
+		return err
+	}
+
+	return service.memberError(projectId, memberId)
+}
+
+// TransferOwnership makes another member the owner of the project, only the owner
+// may transfer the ownership. The previous owner stays in the project as an editor.
+func (service *ProjectServiceImpl) TransferOwnership(projectId int64, userId int64, input dto.TransferProjectDTO) error {
+	err := service.repo.TransferOwnership(projectId, userId, input.UserId)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err := requireProjectRole(service.repo, projectId, userId, dto.ProjectRoleOwner); err != nil {
+		return err
+	}
+
+	return errMemberNotFound
+}
+
+// MemberIds returns the ids of the members of the project, without checking
+// who asks for them. Handlers use them to invalidate cached projects.
+func (service *ProjectServiceImpl) MemberIds(projectId int64) ([]int64, error) {
+	return service.repo.MemberIds(projectId)
+}
+
+// memberError explains why a statement of the owner on a member changed nothing:
+// the member is missing or is the owner, whose membership can not be changed.
+func (service *ProjectServiceImpl) memberError(projectId int64, memberId int64) error {
+	role, err := service.repo.GetMemberRole(projectId, memberId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errMemberNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if role == dto.ProjectRoleOwner {
+		return errOwnerMembership
+	}
+
+	return errMemberNotFound
+}
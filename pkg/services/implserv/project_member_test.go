@@ -0,0 +1,312 @@
+package implserv
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	mock_repositories "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectService_GetMembers(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockProjectRepository)
+
+	cases := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expected     []dto.ProjectMemberDTO
+		expectedErr  error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository) {
+				s.EXPECT().GetMembers(int64(1), int64(2)).Return([]entity.ProjectMember{
+					{ProjectId: 1, UserId: 2, Username: "owner", Role: dto.ProjectRoleOwner},
+				}, nil)
+			},
+			expected: []dto.ProjectMemberDTO{{UserId: 2, Username: "owner", Role: dto.ProjectRoleOwner}},
+		},
+		{
+			name: "Not a member",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository) {
+				s.EXPECT().GetMembers(int64(1), int64(2)).Return(nil, nil)
+			},
+			expectedErr: errProjectNotFound,
+		},
+		{
+			name: "Failed",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository) {
+				s.EXPECT().GetMembers(int64(1), int64(2)).Return(nil, errSome)
+			},
+			expectedErr: errSome,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockProjectRepository(ctrl)
+			c.mockBehavior(repo)
+
+			got, err := NewProjectService(repo).GetMembers(1, 2)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+		})
+	}
+}
+
+func TestProjectService_AddMember(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockProjectRepository, input dto.AddProjectMemberDTO)
+
+	input := dto.AddProjectMemberDTO{Username: "editor", Role: dto.ProjectRoleEditor}
+
+	cases := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expected     dto.ProjectMemberDTO
+		expectedErr  error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, input dto.AddProjectMemberDTO) {
+				s.EXPECT().AddMember(int64(1), int64(2), input).
+					Return(entity.ProjectMember{ProjectId: 1, UserId: 3, Username: "editor", Role: dto.ProjectRoleEditor}, nil)
+			},
+			expected: dto.ProjectMemberDTO{UserId: 3, Username: "editor", Role: dto.ProjectRoleEditor},
+		},
+		{
+			name: "Unknown user",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, input dto.AddProjectMemberDTO) {
+				s.EXPECT().AddMember(int64(1), int64(2), input).Return(entity.ProjectMember{}, sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleOwner, nil)
+			},
+			expectedErr: errUserNotFound,
+		},
+		{
+			name: "Editor",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, input dto.AddProjectMemberDTO) {
+				s.EXPECT().AddMember(int64(1), int64(2), input).Return(entity.ProjectMember{}, sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleEditor, nil)
+			},
+			expectedErr: errProjectForbidden,
+		},
+		{
+			name: "Not a member",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, input dto.AddProjectMemberDTO) {
+				s.EXPECT().AddMember(int64(1), int64(2), input).Return(entity.ProjectMember{}, sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(int64(1), int64(2)).Return("", sql.ErrNoRows)
+			},
+			expectedErr: errProjectNotFound,
+		},
+		{
+			name: "Already a member",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, input dto.AddProjectMemberDTO) {
+				s.EXPECT().AddMember(int64(1), int64(2), input).
+					Return(entity.ProjectMember{}, &pq.Error{Code: "23505", Constraint: "project_members_pkey"})
+			},
+			expectedErr: errMemberExists,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockProjectRepository(ctrl)
+			c.mockBehavior(repo, input)
+
+			got, err := NewProjectService(repo).AddMember(1, 2, input)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+		})
+	}
+}
+
+func TestProjectService_UpdateMemberRole(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockProjectRepository)
+
+	cases := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository) {
+				s.EXPECT().UpdateMemberRole(int64(1), int64(2), int64(3), dto.ProjectRoleViewer).Return(nil)
+			},
+		},
+		{
+			name: "Viewer",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository) {
+				s.EXPECT().UpdateMemberRole(int64(1), int64(2), int64(3), dto.ProjectRoleViewer).Return(sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleViewer, nil)
+			},
+			expectedErr: errProjectForbidden,
+		},
+		{
+			name: "Missing member",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository) {
+				s.EXPECT().UpdateMemberRole(int64(1), int64(2), int64(3), dto.ProjectRoleViewer).Return(sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleOwner, nil)
+				s.EXPECT().GetMemberRole(int64(1), int64(3)).Return("", sql.ErrNoRows)
+			},
+			expectedErr: errMemberNotFound,
+		},
+		{
+			name: "Owner",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository) {
+				s.EXPECT().UpdateMemberRole(int64(1), int64(2), int64(3), dto.ProjectRoleViewer).Return(sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleOwner, nil)
+				s.EXPECT().GetMemberRole(int64(1), int64(3)).Return(dto.ProjectRoleOwner, nil)
+			},
+			expectedErr: errOwnerMembership,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockProjectRepository(ctrl)
+			c.mockBehavior(repo)
+
+			err := NewProjectService(repo).UpdateMemberRole(1, 2, 3, dto.UpdateProjectMemberDTO{Role: dto.ProjectRoleViewer})
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
+
+func TestProjectService_RemoveMember(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockProjectRepository, userId, memberId int64)
+
+	cases := []struct {
+		name         string
+		userId       int64
+		memberId     int64
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:     "OK",
+			userId:   2,
+			memberId: 3,
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, userId, memberId int64) {
+				s.EXPECT().RemoveMember(int64(1), userId, memberId).Return(nil)
+			},
+		},
+		{
+			name:     "Owner leaves",
+			userId:   2,
+			memberId: 2,
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, userId, memberId int64) {
+				s.EXPECT().RemoveMember(int64(1), userId, memberId).Return(sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(int64(1), userId).Return(dto.ProjectRoleOwner, nil)
+			},
+			expectedErr: errOwnerMembership,
+		},
+		{
+			name:     "Not a member leaves",
+			userId:   2,
+			memberId: 2,
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, userId, memberId int64) {
+				s.EXPECT().RemoveMember(int64(1), userId, memberId).Return(sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(int64(1), userId).Return("", sql.ErrNoRows)
+			},
+			expectedErr: errProjectNotFound,
+		},
+		{
+			name:     "Editor removes another member",
+			userId:   2,
+			memberId: 3,
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, userId, memberId int64) {
+				s.EXPECT().RemoveMember(int64(1), userId, memberId).Return(sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(int64(1), userId).Return(dto.ProjectRoleEditor, nil)
+			},
+			expectedErr: errProjectForbidden,
+		},
+		{
+			name:     "Missing member",
+			userId:   2,
+			memberId: 3,
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, userId, memberId int64) {
+				s.EXPECT().RemoveMember(int64(1), userId, memberId).Return(sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(int64(1), userId).Return(dto.ProjectRoleOwner, nil)
+				s.EXPECT().GetMemberRole(int64(1), memberId).Return("", sql.ErrNoRows)
+			},
+			expectedErr: errMemberNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockProjectRepository(ctrl)
+			c.mockBehavior(repo, c.userId, c.memberId)
+
+			err := NewProjectService(repo).RemoveMember(1, c.userId, c.memberId)
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
+
+func TestProjectService_TransferOwnership(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockProjectRepository)
+
+	cases := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository) {
+				s.EXPECT().TransferOwnership(int64(1), int64(2), int64(3)).Return(nil)
+			},
+		},
+		{
+			name: "Editor",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository) {
+				s.EXPECT().TransferOwnership(int64(1), int64(2), int64(3)).Return(sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleEditor, nil)
+			},
+			expectedErr: errProjectForbidden,
+		},
+		{
+			name: "New owner is not a member",
+			mockBehavior: func(s *mock_repositories.MockProjectRepository) {
+				s.EXPECT().TransferOwnership(int64(1), int64(2), int64(3)).Return(sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleOwner, nil)
+			},
+			expectedErr: errMemberNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockProjectRepository(ctrl)
+			c.mockBehavior(repo)
+
+			err := NewProjectService(repo).TransferOwnership(1, 2, dto.TransferProjectDTO{UserId: 3})
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
 	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
@@ -12,6 +13,8 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+var errSome = errors.New("some error")
+
 func TestProjectService_Create(t *testing.T) {
 	type mockBehavior func(s *mock_repositories.MockProjectRepository, p *entity.Project)
 
@@ -75,8 +78,8 @@ func TestProjectService_GetById(t *testing.T) {
 		inputId      int64
 		inputUserId  int64
 		mockBehavior mockBehavior
-		expected     dto.ProjectDTO
-		expectedErr  bool
+		expected     dto.ProjectResponseDTO
+		expectedErr  error
 	}{
 		{
 			name:        "OK",
@@ -84,14 +87,23 @@ func TestProjectService_GetById(t *testing.T) {
 			inputUserId: 2,
 			mockBehavior: func(s *mock_repositories.MockProjectRepository, id, userId int64) {
 				s.EXPECT().GetById(id, userId).Return(entity.Project{
-					Id:     1,
-					Title:  "title",
-					Done:   false,
-					UserId: 2,
+					Id:          1,
+					Title:       "title",
+					Done:        true,
+					UserId:      2,
+					CreatedAt:   time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+					UpdatedAt:   time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+					CompletedAt: timePointer(time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)),
 				}, nil)
 			},
-			expected: dto.ProjectDTO{
-				Title: "title",
+			expected: dto.ProjectResponseDTO{
+				Id:          1,
+				UserId:      2,
+				Title:       "title",
+				Done:        true,
+				CreatedAt:   time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+				UpdatedAt:   time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+				CompletedAt: timePointer(time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)),
 			},
 		},
 		{
@@ -101,7 +113,7 @@ func TestProjectService_GetById(t *testing.T) {
 			mockBehavior: func(s *mock_repositories.MockProjectRepository, id, userId int64) {
 				s.EXPECT().GetById(id, userId).Return(entity.Project{}, sql.ErrNoRows)
 			},
-			expectedErr: true,
+			expectedErr: errProjectNotFound,
 		},
 	}
 
@@ -115,8 +127,8 @@ func TestProjectService_GetById(t *testing.T) {
 
 			serv := NewProjectService(repo)
 			got, err := serv.GetById(c.inputId, c.inputUserId)
-			if c.expectedErr {
-				assert.Error(t, err)
+			if c.expectedErr != nil {
+				assert.ErrorIs(t, err, c.expectedErr)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, got, c.expected)
@@ -126,9 +138,9 @@ func TestProjectService_GetById(t *testing.T) {
 }
 
 func TestProjectService_GetAll(t *testing.T) {
-	expected := []dto.ProjectDTO{
-		{Title: "title", Description: "description", Done: false},
-		{Title: "title2", Description: "description2", Done: true},
+	expected := []dto.ProjectResponseDTO{
+		{Id: 1, UserId: 1, Title: "title", Description: "description", Done: false},
+		{Id: 2, UserId: 1, Title: "title2", Description: "description2", Done: true},
 	}
 
 	mockBehavior := func(s *mock_repositories.MockProjectRepository, userId int64) {
@@ -150,6 +162,93 @@ func TestProjectService_GetAll(t *testing.T) {
 	assert.Equal(t, got, expected)
 }
 
+func TestProjectService_List(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockProjectRepository, userId int64, query dto.ProjectQueryDTO)
+
+	projects := []entity.Project{
+		{Id: 1, Title: "title", UserId: 1},
+		{Id: 2, Title: "title2", UserId: 1},
+		{Id: 3, Title: "title3", UserId: 1},
+	}
+
+	cases := []struct {
+		name         string
+		query        dto.ProjectQueryDTO
+		mockBehavior mockBehavior
+		expected     dto.ProjectPageDTO
+		expectedErr  bool
+	}{
+		{
+			name:  "Last page",
+			query: dto.ProjectQueryDTO{Limit: 3, Sort: "title", Order: "asc"},
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, userId int64, query dto.ProjectQueryDTO) {
+				query.Limit = 4
+				s.EXPECT().List(userId, query).Return(projects, nil)
+			},
+			expected: dto.ProjectPageDTO{
+				Items: []dto.ProjectResponseDTO{
+					{Id: 1, Title: "title", UserId: 1},
+					{Id: 2, Title: "title2", UserId: 1},
+					{Id: 3, Title: "title3", UserId: 1},
+				},
+			},
+		},
+		{
+			name:  "Has next page",
+			query: dto.ProjectQueryDTO{Limit: 2, Sort: "title", Order: "asc"},
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, userId int64, query dto.ProjectQueryDTO) {
+				query.Limit = 3
+				s.EXPECT().List(userId, query).Return(projects, nil)
+			},
+			expected: dto.ProjectPageDTO{
+				Items: []dto.ProjectResponseDTO{
+					{Id: 1, Title: "title", UserId: 1},
+					{Id: 2, Title: "title2", UserId: 1},
+				},
+				NextCursor: dto.ProjectCursor{Sort: "title", Value: "title2", Id: 2}.Encode(),
+			},
+		},
+		{
+			name:  "Defaults",
+			query: dto.ProjectQueryDTO{},
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, userId int64, query dto.ProjectQueryDTO) {
+				s.EXPECT().List(userId, dto.ProjectQueryDTO{
+					Limit: dto.DefaultProjectsLimit + 1,
+					Sort:  "created_at",
+					Order: "asc",
+				}).Return(nil, nil)
+			},
+			expected: dto.ProjectPageDTO{Items: []dto.ProjectResponseDTO{}},
+		},
+		{
+			name:  "Repository failed",
+			query: dto.ProjectQueryDTO{Limit: 2, Sort: "title", Order: "asc"},
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, userId int64, query dto.ProjectQueryDTO) {
+				s.EXPECT().List(userId, gomock.Any()).Return(nil, errors.New("some error"))
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockProjectRepository(ctrl)
+			c.mockBehavior(repo, 1, c.query)
+
+			got, err := NewProjectService(repo).List(1, c.query)
+			if c.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, got, c.expected)
+			}
+		})
+	}
+}
+
 func TestProjectService_UpdateById(t *testing.T) {
 	type mockBehavior func(s *mock_repositories.MockProjectRepository,
 		id int64, input dto.UpdateProjectDTO, userId int64)
@@ -163,7 +262,7 @@ func TestProjectService_UpdateById(t *testing.T) {
 		name         string
 		args         args
 		mockBehavior mockBehavior
-		expectedErr  bool
+		expectedErr  error
 	}{
 		{
 			name: "OK",
@@ -179,6 +278,21 @@ func TestProjectService_UpdateById(t *testing.T) {
 				s.EXPECT().UpdateById(id, input, userId).Return(nil)
 			},
 		},
+		{
+			name: "Failed",
+			args: args{
+				input: dto.UpdateProjectDTO{
+					Title: stringPointer("New title"),
+				},
+				id:     1,
+				userId: 2,
+			},
+			mockBehavior: func(s *mock_repositories.MockProjectRepository,
+				id int64, input dto.UpdateProjectDTO, userId int64) {
+				s.EXPECT().UpdateById(id, input, userId).Return(errSome)
+			},
+			expectedErr: errSome,
+		},
 		{
 			name: "Not found",
 			args: args{
@@ -190,9 +304,26 @@ func TestProjectService_UpdateById(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_repositories.MockProjectRepository,
 				id int64, input dto.UpdateProjectDTO, userId int64) {
-				s.EXPECT().UpdateById(id, input, userId).Return(errors.New("some error"))
+				s.EXPECT().UpdateById(id, input, userId).Return(sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(id, userId).Return("", sql.ErrNoRows)
 			},
-			expectedErr: true,
+			expectedErr: errProjectNotFound,
+		},
+		{
+			name: "Viewer",
+			args: args{
+				input: dto.UpdateProjectDTO{
+					Title: stringPointer("New title"),
+				},
+				id:     1,
+				userId: 2,
+			},
+			mockBehavior: func(s *mock_repositories.MockProjectRepository,
+				id int64, input dto.UpdateProjectDTO, userId int64) {
+				s.EXPECT().UpdateById(id, input, userId).Return(sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(id, userId).Return(dto.ProjectRoleViewer, nil)
+			},
+			expectedErr: errProjectForbidden,
 		},
 	}
 
@@ -205,8 +336,8 @@ func TestProjectService_UpdateById(t *testing.T) {
 			c.mockBehavior(repo, c.args.id, c.args.input, c.args.userId)
 
 			err := NewProjectService(repo).UpdateById(c.args.id, c.args.input, c.args.userId)
-			if c.expectedErr {
-				assert.Error(t, err)
+			if c.expectedErr != nil {
+				assert.ErrorIs(t, err, c.expectedErr)
 			} else {
 				assert.NoError(t, err)
 			}
@@ -222,7 +353,7 @@ func TestProjectService_DeleteById(t *testing.T) {
 		inputId      int64
 		inputUserId  int64
 		mockBehavior mockBehavior
-		expectedErr  bool
+		expectedErr  error
 	}{
 		{
 			name:        "OK",
@@ -232,14 +363,34 @@ func TestProjectService_DeleteById(t *testing.T) {
 				s.EXPECT().DeleteById(id, userId).Return(nil)
 			},
 		},
+		{
+			name:        "Failed",
+			inputId:     1,
+			inputUserId: 2,
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, id, userId int64) {
+				s.EXPECT().DeleteById(id, userId).Return(errSome)
+			},
+			expectedErr: errSome,
+		},
 		{
 			name:        "Not found",
 			inputId:     1,
 			inputUserId: 2,
 			mockBehavior: func(s *mock_repositories.MockProjectRepository, id, userId int64) {
-				s.EXPECT().DeleteById(id, userId).Return(errors.New("some error"))
+				s.EXPECT().DeleteById(id, userId).Return(sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(id, userId).Return("", sql.ErrNoRows)
 			},
-			expectedErr: true,
+			expectedErr: errProjectNotFound,
+		},
+		{
+			name:        "Editor",
+			inputId:     1,
+			inputUserId: 2,
+			mockBehavior: func(s *mock_repositories.MockProjectRepository, id, userId int64) {
+				s.EXPECT().DeleteById(id, userId).Return(sql.ErrNoRows)
+				s.EXPECT().GetMemberRole(id, userId).Return(dto.ProjectRoleEditor, nil)
+			},
+			expectedErr: errProjectForbidden,
 		},
 	}
 
@@ -252,8 +403,8 @@ func TestProjectService_DeleteById(t *testing.T) {
 			c.mockBehavior(repo, c.inputId, c.inputUserId)
 
 			err := NewProjectService(repo).DeleteById(c.inputId, c.inputUserId)
-			if c.expectedErr {
-				assert.Error(t, err)
+			if c.expectedErr != nil {
+				assert.ErrorIs(t, err, c.expectedErr)
 			} else {
 				assert.NoError(t, err)
 			}
@@ -264,3 +415,7 @@ func TestProjectService_DeleteById(t *testing.T) {
 func stringPointer(str string) *string {
 	return &str
 }
+
+func timePointer(t time.Time) *time.Time {
+	return &t
+}
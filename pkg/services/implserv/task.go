@@ -0,0 +1,128 @@
+package implserv
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories"
+)
+
+// TaskServiceImpl manages the tasks of projects. Every member of a project can
+// read its tasks, owners and editors can change them.
+type TaskServiceImpl struct {
+	repo     repositories.TaskRepository
+	projects repositories.ProjectRepository
+}
+
+func NewTaskService(repo repositories.TaskRepository, projects repositories.ProjectRepository) *TaskServiceImpl {
+	return &TaskServiceImpl{repo: repo, projects: projects}
+}
+
+func (service *TaskServiceImpl) Create(projectId int64, input dto.TaskDTO, userId int64) (int64, error) {
+	task, err := entity.TaskFromDTO(input)
+	if err != nil {
+		return 0, err
+	}
+	task.ProjectId = projectId
+
+	id, err := service.repo.Create(task, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		if err := requireProjectRole(service.projects, projectId, userId,
+			dto.ProjectRoleOwner, dto.ProjectRoleEditor); err != nil {
+			return 0, err
+		}
+
+		return 0, errAssigneeNotMember
+	}
+
+	return id, err
+}
+
+func (service *TaskServiceImpl) GetById(projectId int64, id int64, userId int64) (dto.TaskResponseDTO, error) {
+	task, err := service.repo.GetById(projectId, id, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dto.TaskResponseDTO{}, service.taskError(projectId, userId)
+	}
+
+	if err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+
+	return *task.ToResponseDTO(), nil
+}
+
+// List returns the tasks of the project in their order.
+func (service *TaskServiceImpl) List(projectId int64, userId int64, query dto.TaskQueryDTO) ([]dto.TaskResponseDTO, error) {
+	tasks, err := service.repo.List(projectId, userId, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// users who are not members get no tasks, just like members of a project without them
+	if len(tasks) == 0 {
+		if err := requireProjectRole(service.projects, projectId, userId,
+			dto.ProjectRoleOwner, dto.ProjectRoleEditor, dto.ProjectRoleViewer); err != nil {
+			return nil, err
+		}
+	}
+
+	dtos := make([]dto.TaskResponseDTO, len(tasks))
+	for i, t := range tasks {
+		dtos[i] = *t.ToResponseDTO()
+	}
+
+	return dtos, nil
+}
+
+func (service *TaskServiceImpl) UpdateById(projectId int64, id int64, input dto.UpdateTaskDTO, userId int64) error {
+	err := service.repo.UpdateById(projectId, id, input, userId)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err := requireProjectRole(service.projects, projectId, userId,
+		dto.ProjectRoleOwner, dto.ProjectRoleEditor); err != nil {
+		return err
+	}
+
+	// with an existing task only the assignee can be the cause
+	if input.AssigneeId != nil && *input.AssigneeId != 0 {
+		_, err := service.repo.GetById(projectId, id, userId)
+		if err == nil {
+			return errAssigneeNotMember
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+
+	return errTaskNotFound
+}
+
+func (service *TaskServiceImpl) DeleteById(projectId int64, id int64, userId int64) error {
+	err := service.repo.DeleteById(projectId, id, userId)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err := requireProjectRole(service.projects, projectId, userId,
+		dto.ProjectRoleOwner, dto.ProjectRoleEditor); err != nil {
+		return err
+	}
+
+	return errTaskNotFound
+}
+
+// taskError explains a missing task, tasks of projects the user is not a member of
+// are reported as a missing project.
+func (service *TaskServiceImpl) taskError(projectId int64, userId int64) error {
+	if err := requireProjectRole(service.projects, projectId, userId,
+		dto.ProjectRoleOwner, dto.ProjectRoleEditor, dto.ProjectRoleViewer); err != nil {
+		return err
+	}
+
+	return errTaskNotFound
+}
@@ -0,0 +1,339 @@
+package implserv
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	mock_repositories "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskService_Create(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository)
+
+	dueDate := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	assigneeId := int64(3)
+
+	cases := []struct {
+		name         string
+		input        dto.TaskDTO
+		mockBehavior mockBehavior
+		expected     int64
+		expectedErr  error
+	}{
+		{
+			name:  "OK",
+			input: dto.TaskDTO{Title: "title", DueDate: "2024-03-01", AssigneeId: &assigneeId},
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().Create(&entity.Task{
+					ProjectId: 1, Title: "title", Status: dto.TaskStatusTodo, DueDate: &dueDate, AssigneeId: &assigneeId,
+				}, int64(2)).Return(int64(4), nil)
+			},
+			expected: 4,
+		},
+		{
+			name:  "Viewer",
+			input: dto.TaskDTO{Title: "title"},
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().Create(gomock.Any(), int64(2)).Return(int64(0), sql.ErrNoRows)
+				p.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleViewer, nil)
+			},
+			expectedErr: errProjectForbidden,
+		},
+		{
+			name:  "Not a member",
+			input: dto.TaskDTO{Title: "title"},
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().Create(gomock.Any(), int64(2)).Return(int64(0), sql.ErrNoRows)
+				p.EXPECT().GetMemberRole(int64(1), int64(2)).Return("", sql.ErrNoRows)
+			},
+			expectedErr: errProjectNotFound,
+		},
+		{
+			name:  "Assignee not a member",
+			input: dto.TaskDTO{Title: "title", AssigneeId: &assigneeId},
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().Create(gomock.Any(), int64(2)).Return(int64(0), sql.ErrNoRows)
+				p.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleEditor, nil)
+			},
+			expectedErr: errAssigneeNotMember,
+		},
+		{
+			name:  "Failed",
+			input: dto.TaskDTO{Title: "title"},
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().Create(gomock.Any(), int64(2)).Return(int64(0), errSome)
+			},
+			expectedErr: errSome,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockTaskRepository(ctrl)
+			projects := mock_repositories.NewMockProjectRepository(ctrl)
+			c.mockBehavior(repo, projects)
+
+			got, err := NewTaskService(repo, projects).Create(1, c.input, 2)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+		})
+	}
+}
+
+func TestTaskService_GetById(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository)
+
+	dueDate := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	position := 2
+	formatted := "2024-03-01"
+
+	cases := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expected     dto.TaskResponseDTO
+		expectedErr  error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().GetById(int64(1), int64(4), int64(2)).Return(entity.Task{
+					Id: 4, ProjectId: 1, Title: "title", Status: dto.TaskStatusTodo, DueDate: &dueDate, Position: &position,
+				}, nil)
+			},
+			expected: dto.TaskResponseDTO{
+				Id: 4, ProjectId: 1, Title: "title", Status: dto.TaskStatusTodo, DueDate: &formatted, Position: 2,
+			},
+		},
+		{
+			name: "Task not found",
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().GetById(int64(1), int64(4), int64(2)).Return(entity.Task{}, sql.ErrNoRows)
+				p.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleViewer, nil)
+			},
+			expectedErr: errTaskNotFound,
+		},
+		{
+			name: "Not a member",
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().GetById(int64(1), int64(4), int64(2)).Return(entity.Task{}, sql.ErrNoRows)
+				p.EXPECT().GetMemberRole(int64(1), int64(2)).Return("", sql.ErrNoRows)
+			},
+			expectedErr: errProjectNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockTaskRepository(ctrl)
+			projects := mock_repositories.NewMockProjectRepository(ctrl)
+			c.mockBehavior(repo, projects)
+
+			got, err := NewTaskService(repo, projects).GetById(1, 4, 2)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+		})
+	}
+}
+
+func TestTaskService_List(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository)
+
+	query := dto.TaskQueryDTO{Status: dto.TaskStatusDone}
+
+	cases := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expected     []dto.TaskResponseDTO
+		expectedErr  error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().List(int64(1), int64(2), query).
+					Return([]entity.Task{{Id: 4, ProjectId: 1, Title: "title", Status: dto.TaskStatusDone}}, nil)
+			},
+			expected: []dto.TaskResponseDTO{{Id: 4, ProjectId: 1, Title: "title", Status: dto.TaskStatusDone}},
+		},
+		{
+			name: "No tasks",
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().List(int64(1), int64(2), query).Return(nil, nil)
+				p.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleViewer, nil)
+			},
+			expected: []dto.TaskResponseDTO{},
+		},
+		{
+			name: "Not a member",
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().List(int64(1), int64(2), query).Return(nil, nil)
+				p.EXPECT().GetMemberRole(int64(1), int64(2)).Return("", sql.ErrNoRows)
+			},
+			expectedErr: errProjectNotFound,
+		},
+		{
+			name: "Failed",
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().List(int64(1), int64(2), query).Return(nil, errSome)
+			},
+			expectedErr: errSome,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockTaskRepository(ctrl)
+			projects := mock_repositories.NewMockProjectRepository(ctrl)
+			c.mockBehavior(repo, projects)
+
+			got, err := NewTaskService(repo, projects).List(1, 2, query)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+		})
+	}
+}
+
+func TestTaskService_UpdateById(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository,
+		input dto.UpdateTaskDTO)
+
+	title := "title"
+	assigneeId := int64(3)
+
+	cases := []struct {
+		name         string
+		input        dto.UpdateTaskDTO
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:  "OK",
+			input: dto.UpdateTaskDTO{Title: &title},
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository,
+				input dto.UpdateTaskDTO) {
+				s.EXPECT().UpdateById(int64(1), int64(4), input, int64(2)).Return(nil)
+			},
+		},
+		{
+			name:  "Viewer",
+			input: dto.UpdateTaskDTO{Title: &title},
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository,
+				input dto.UpdateTaskDTO) {
+				s.EXPECT().UpdateById(int64(1), int64(4), input, int64(2)).Return(sql.ErrNoRows)
+				p.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleViewer, nil)
+			},
+			expectedErr: errProjectForbidden,
+		},
+		{
+			name:  "Task not found",
+			input: dto.UpdateTaskDTO{Title: &title},
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository,
+				input dto.UpdateTaskDTO) {
+				s.EXPECT().UpdateById(int64(1), int64(4), input, int64(2)).Return(sql.ErrNoRows)
+				p.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleEditor, nil)
+			},
+			expectedErr: errTaskNotFound,
+		},
+		{
+			name:  "Assignee not a member",
+			input: dto.UpdateTaskDTO{AssigneeId: &assigneeId},
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository,
+				input dto.UpdateTaskDTO) {
+				s.EXPECT().UpdateById(int64(1), int64(4), input, int64(2)).Return(sql.ErrNoRows)
+				p.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleOwner, nil)
+				s.EXPECT().GetById(int64(1), int64(4), int64(2)).Return(entity.Task{Id: 4}, nil)
+			},
+			expectedErr: errAssigneeNotMember,
+		},
+		{
+			name:  "Assignee of a missing task",
+			input: dto.UpdateTaskDTO{AssigneeId: &assigneeId},
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository,
+				input dto.UpdateTaskDTO) {
+				s.EXPECT().UpdateById(int64(1), int64(4), input, int64(2)).Return(sql.ErrNoRows)
+				p.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleOwner, nil)
+				s.EXPECT().GetById(int64(1), int64(4), int64(2)).Return(entity.Task{}, sql.ErrNoRows)
+			},
+			expectedErr: errTaskNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockTaskRepository(ctrl)
+			projects := mock_repositories.NewMockProjectRepository(ctrl)
+			c.mockBehavior(repo, projects, c.input)
+
+			err := NewTaskService(repo, projects).UpdateById(1, 4, c.input, 2)
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
+
+func TestTaskService_DeleteById(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository)
+
+	cases := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().DeleteById(int64(1), int64(4), int64(2)).Return(nil)
+			},
+		},
+		{
+			name: "Viewer",
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().DeleteById(int64(1), int64(4), int64(2)).Return(sql.ErrNoRows)
+				p.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleViewer, nil)
+			},
+			expectedErr: errProjectForbidden,
+		},
+		{
+			name: "Task not found",
+			mockBehavior: func(s *mock_repositories.MockTaskRepository, p *mock_repositories.MockProjectRepository) {
+				s.EXPECT().DeleteById(int64(1), int64(4), int64(2)).Return(sql.ErrNoRows)
+				p.EXPECT().GetMemberRole(int64(1), int64(2)).Return(dto.ProjectRoleEditor, nil)
+			},
+			expectedErr: errTaskNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockTaskRepository(ctrl)
+			projects := mock_repositories.NewMockProjectRepository(ctrl)
+			c.mockBehavior(repo, projects)
+
+			err := NewTaskService(repo, projects).DeleteById(1, 4, 2)
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package implserv
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfc6238Secret is the SHA-1 key of the test vectors of RFC 6238, base32 encoded.
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTotpCode(t *testing.T) {
+	// the last 6 of the 8 digits of the RFC 6238 test vectors
+	cases := []struct {
+		unix     int64
+		expected string
+	}{
+		{unix: 59, expected: "287082"},
+		{unix: 1111111109, expected: "081804"},
+		{unix: 1234567890, expected: "005924"},
+		{unix: 2000000000, expected: "279037"},
+	}
+
+	for _, c := range cases {
+		got, err := totpCode(rfc6238Secret, totpStep(time.Unix(c.unix, 0)))
+
+		assert.NoError(t, err)
+		assert.Equal(t, got, c.expected)
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := totpStep(now)
+
+	previous, err := totpCode(rfc6238Secret, current-1)
+	assert.NoError(t, err)
+
+	tooOld, err := totpCode(rfc6238Secret, current-2)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name         string
+		code         string
+		lastStep     int64
+		expectedStep int64
+		expectedOk   bool
+	}{
+		{
+			name:         "Current step",
+			code:         "005924",
+			expectedStep: current,
+			expectedOk:   true,
+		},
+		{
+			name:         "Previous step",
+			code:         previous,
+			expectedStep: current - 1,
+			expectedOk:   true,
+		},
+		{
+			name: "Outside of the skew",
+			code: tooOld,
+		},
+		{
+			name:     "Already used",
+			code:     "005924",
+			lastStep: current,
+		},
+		{
+			name: "Wrong code",
+			code: "000000",
+		},
+		{
+			name: "Wrong length",
+			code: "5924",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			step, ok, err := verifyTOTP(rfc6238Secret, c.code, now, c.lastStep)
+
+			assert.NoError(t, err)
+			assert.Equal(t, ok, c.expectedOk)
+			assert.Equal(t, step, c.expectedStep)
+		})
+	}
+}
+
+func TestTotpURI(t *testing.T) {
+	got := totpURI("crud_app", "email@gmail.com", "SECRET")
+
+	assert.Equal(t, got, "otpauth://totp/crud_app:email@gmail.com"+
+		"?algorithm=SHA1&digits=6&issuer=crud_app&period=30&secret=SECRET")
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, err := newRecoveryCodes()
+	assert.NoError(t, err)
+
+	assert.Len(t, codes, recoveryCodesCount)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		assert.Regexp(t, regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`), code)
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+
+	assert.Equal(t, normalizeRecoveryCode("ABCDE-FGHIJ"), "abcdefghij")
+	assert.Equal(t, normalizeRecoveryCode("abcde fghij"), "abcdefghij")
+}
+
+const testTwoFactorKey = "two_factor_key"
+
+// testRecoveryCodeHash returns the stored hash of a normalized recovery code.
+func testRecoveryCodeHash(code string) string {
+	key := hmac.New(sha256.New, []byte(testTwoFactorKey))
+	key.Write([]byte("recovery_code"))
+
+	mac := hmac.New(sha256.New, key.Sum(nil))
+	mac.Write([]byte(code))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestTotpCipher(t *testing.T) {
+	c, err := newTOTPCipher([]byte(testTwoFactorKey))
+	assert.NoError(t, err)
+
+	sealed, err := c.seal(rfc6238Secret)
+	assert.NoError(t, err)
+	assert.NotContains(t, sealed, rfc6238Secret)
+
+	opened, err := c.open(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, opened, rfc6238Secret)
+
+	other, err := newTOTPCipher([]byte("other_secret"))
+	assert.NoError(t, err)
+
+	_, err = other.open(sealed)
+	assert.Error(t, err, "secrets sealed with another key can not be opened")
+
+	assert.Equal(t, c.hashRecoveryCode("ABCDE-FGHIJ"), testRecoveryCodeHash("abcdefghij"))
+	assert.NotEqual(t, other.hashRecoveryCode("abcdefghij"), testRecoveryCodeHash("abcdefghij"))
+	assert.NotEqual(t, c.hashRecoveryCode("abcdefghij"), testTokenHash("abcdefghij"))
+
+	_, err = newTOTPCipher(nil)
+	assert.Error(t, err, "the two-factor key is required")
+}
@@ -0,0 +1,225 @@
+package implserv
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultTwoFactorTTL    = 5 * time.Minute
+	defaultTwoFactorIssuer = "crud_app"
+)
+
+// SignInTwoFactor consumes the challenge issued by SignIn and returns the id
+// of the user if the code is a valid TOTP or recovery code. The challenge can
+// be used once, so after a wrong code the user has to sign in again. Wrong codes
+// count as failed sign ins of the account and the client IP.
+func (service *AuthServiceImpl) SignInTwoFactor(input dto.SignInTwoFactorDTO, client dto.ClientInfo) (int64, error) {
+	t, err := service.repo.ConsumeUserToken(service.hashToken(input.ChallengeToken), entity.TokenPurposeTwoFactor)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errInvalidChallengeToken
+		}
+		return 0, err
+	}
+
+	if time.Now().After(t.ExpiresAt) {
+		return 0, errExpiredChallengeToken
+	}
+
+	user, err := service.repo.GetById(t.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errInvalidChallengeToken
+		}
+		return 0, err
+	}
+
+	if user.TOTPEnabledAt == nil || user.TOTPSecret == nil {
+		return 0, errInvalidChallengeToken
+	}
+
+	keys := signInKeys(user, dto.SignInDTO{}, client.IP)
+	if err := service.checkLockout(keys); err != nil {
+		service.audit(entity.AuditSignInFailed, t.UserId, client, "locked")
+		return 0, err
+	}
+
+	ok, err := service.verifySecondFactor(user, input.Code)
+	if err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		service.audit(entity.AuditSignInFailed, t.UserId, client, "invalid two-factor code")
+		service.recordFailure(keys, t.UserId, client)
+		return 0, errInvalidTwoFactorCode
+	}
+
+	service.resetFailures(keys[0], t.UserId, client)
+
+	return t.UserId, nil
+}
+
+// verifySecondFactor accepts a TOTP code that was not used before or consumes a recovery code.
+func (service *AuthServiceImpl) verifySecondFactor(user entity.User, code string) (bool, error) {
+	userId := int64(user.Id)
+
+	secret, err := service.cfg.totp.open(*user.TOTPSecret)
+	if err != nil {
+		return false, err
+	}
+
+	var lastStep int64
+	if user.TOTPLastStep != nil {
+		lastStep = *user.TOTPLastStep
+	}
+
+	step, ok, err := verifyTOTP(secret, code, time.Now(), lastStep)
+	if err != nil {
+		return false, err
+	}
+
+	if ok {
+		if err := service.repo.UseTOTPStep(userId, step); err != nil {
+			if err == sql.ErrNoRows {
+				// the code was accepted by a concurrent request
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	if err := service.repo.ConsumeRecoveryCode(userId, service.cfg.totp.hashRecoveryCode(code)); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":   "recovery_code_used",
+		"user_id": userId,
+	}).Info("signed in with a recovery code")
+
+	return true, nil
+}
+
+// EnrollTOTP generates a new secret for the user. It is not used for sign in
+// until ConfirmTOTP proves that the authenticator app of the user has it.
+func (service *AuthServiceImpl) EnrollTOTP(userId int64) (dto.TOTPEnrollmentDTO, error) {
+	user, err := service.repo.GetById(userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return dto.TOTPEnrollmentDTO{}, errUserNotFound
+		}
+		return dto.TOTPEnrollmentDTO{}, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return dto.TOTPEnrollmentDTO{}, errTwoFactorEnabled
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return dto.TOTPEnrollmentDTO{}, err
+	}
+
+	sealed, err := service.cfg.totp.seal(secret)
+	if err != nil {
+		return dto.TOTPEnrollmentDTO{}, err
+	}
+
+	if err := service.repo.SetTOTPSecret(userId, sealed); err != nil {
+		if err == sql.ErrNoRows {
+			return dto.TOTPEnrollmentDTO{}, errTwoFactorEnabled
+		}
+		return dto.TOTPEnrollmentDTO{}, err
+	}
+
+	return dto.TOTPEnrollmentDTO{
+		Secret: secret,
+		URI:    totpURI(service.cfg.totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the first code matches
+// and returns recovery codes. Only their hashes are stored, so they are shown once.
+func (service *AuthServiceImpl) ConfirmTOTP(userId int64, code string) (dto.RecoveryCodesDTO, error) {
+	user, err := service.repo.GetById(userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return dto.RecoveryCodesDTO{}, errUserNotFound
+		}
+		return dto.RecoveryCodesDTO{}, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return dto.RecoveryCodesDTO{}, errTwoFactorEnabled
+	}
+
+	if user.TOTPSecret == nil {
+		return dto.RecoveryCodesDTO{}, errTwoFactorNotEnrolled
+	}
+
+	secret, err := service.cfg.totp.open(*user.TOTPSecret)
+	if err != nil {
+		return dto.RecoveryCodesDTO{}, err
+	}
+
+	step, ok, err := verifyTOTP(secret, code, time.Now(), 0)
+	if err != nil {
+		return dto.RecoveryCodesDTO{}, err
+	}
+
+	if !ok {
+		return dto.RecoveryCodesDTO{}, errInvalidTOTPCode
+	}
+
+	codes, err := newRecoveryCodes()
+	if err != nil {
+		return dto.RecoveryCodesDTO{}, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = service.cfg.totp.hashRecoveryCode(c)
+	}
+
+	if err := service.repo.EnableTOTP(userId, step, hashes); err != nil {
+		if err == sql.ErrNoRows {
+			return dto.RecoveryCodesDTO{}, errTwoFactorEnabled
+		}
+		return dto.RecoveryCodesDTO{}, err
+	}
+
+	return dto.RecoveryCodesDTO{RecoveryCodes: codes}, nil
+}
+
+// DisableTOTP turns two-factor authentication off and deletes the recovery codes
+// if the password is correct.
+func (service *AuthServiceImpl) DisableTOTP(userId int64, password string) error {
+	user, err := service.repo.GetById(userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errUserNotFound
+		}
+		return err
+	}
+
+	ok, _, err := service.cfg.password.verify(password, user.PasswordHash, service.cfg.salt)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errInvalidPassword
+	}
+
+	return service.repo.DisableTOTP(userId)
+}
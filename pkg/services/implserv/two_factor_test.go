@@ -0,0 +1,303 @@
+package implserv
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	mock_repositories "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthService_SignInTwoFactor(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository, user entity.User)
+
+	enabledAt := time.Now()
+	challenge := entity.UserToken{UserId: 1, ExpiresAt: time.Now().Add(time.Minute)}
+
+	cases := []struct {
+		name         string
+		code         string
+		mockBehavior mockBehavior
+		expected     int64
+		expectedErr  error
+	}{
+		{
+			name: "TOTP code",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, user entity.User) {
+				s.EXPECT().ConsumeUserToken(testTokenHash("challenge"), entity.TokenPurposeTwoFactor).Return(challenge, nil)
+				s.EXPECT().GetById(int64(1)).Return(user, nil)
+				s.EXPECT().UseTOTPStep(int64(1), gomock.Any()).Return(nil)
+			},
+			expected: 1,
+		},
+		{
+			name: "Recovery code",
+			code: "ABCDE-FGHIJ",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, user entity.User) {
+				s.EXPECT().ConsumeUserToken(testTokenHash("challenge"), entity.TokenPurposeTwoFactor).Return(challenge, nil)
+				s.EXPECT().GetById(int64(1)).Return(user, nil)
+				s.EXPECT().ConsumeRecoveryCode(int64(1), testRecoveryCodeHash("abcdefghij")).Return(nil)
+			},
+			expected: 1,
+		},
+		{
+			name: "Wrong code",
+			code: "000000",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, user entity.User) {
+				s.EXPECT().ConsumeUserToken(testTokenHash("challenge"), entity.TokenPurposeTwoFactor).Return(challenge, nil)
+				s.EXPECT().GetById(int64(1)).Return(user, nil)
+				s.EXPECT().ConsumeRecoveryCode(int64(1), testRecoveryCodeHash("000000")).Return(sql.ErrNoRows)
+			},
+			expectedErr: errInvalidTwoFactorCode,
+		},
+		{
+			name: "Replayed code",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, user entity.User) {
+				s.EXPECT().ConsumeUserToken(testTokenHash("challenge"), entity.TokenPurposeTwoFactor).Return(challenge, nil)
+				s.EXPECT().GetById(int64(1)).Return(user, nil)
+				s.EXPECT().UseTOTPStep(int64(1), gomock.Any()).Return(sql.ErrNoRows)
+			},
+			expectedErr: errInvalidTwoFactorCode,
+		},
+		{
+			name: "Unknown challenge",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, user entity.User) {
+				s.EXPECT().ConsumeUserToken(testTokenHash("challenge"), entity.TokenPurposeTwoFactor).
+					Return(entity.UserToken{}, sql.ErrNoRows)
+			},
+			expectedErr: errInvalidChallengeToken,
+		},
+		{
+			name: "Expired challenge",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, user entity.User) {
+				s.EXPECT().ConsumeUserToken(testTokenHash("challenge"), entity.TokenPurposeTwoFactor).
+					Return(entity.UserToken{UserId: 1, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+			},
+			expectedErr: errExpiredChallengeToken,
+		},
+		{
+			name: "Two-factor disabled meanwhile",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, user entity.User) {
+				s.EXPECT().ConsumeUserToken(testTokenHash("challenge"), entity.TokenPurposeTwoFactor).Return(challenge, nil)
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1}, nil)
+			},
+			expectedErr: errInvalidChallengeToken,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			serv := newTestAuthService(t, repo, &config.Config{Auth: config.Auth{RefreshSecret: "refresh_secret"}})
+
+			sealed, err := serv.cfg.totp.seal(rfc6238Secret)
+			assert.NoError(t, err)
+			c.mockBehavior(repo, entity.User{Id: 1, TOTPSecret: &sealed, TOTPEnabledAt: &enabledAt})
+
+			code := c.code
+			if code == "" {
+				code, err = totpCode(rfc6238Secret, totpStep(time.Now()))
+				assert.NoError(t, err)
+			}
+
+			got, err := serv.SignInTwoFactor(dto.SignInTwoFactorDTO{ChallengeToken: "challenge", Code: code}, dto.ClientInfo{})
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+		})
+	}
+}
+
+func TestAuthService_EnrollTOTP(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository)
+
+	enabledAt := time.Now()
+
+	cases := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, Email: "email@gmail.com"}, nil)
+				s.EXPECT().SetTOTPSecret(int64(1), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "Already enabled",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, TOTPEnabledAt: &enabledAt}, nil)
+			},
+			expectedErr: errTwoFactorEnabled,
+		},
+		{
+			name: "Enabled by a concurrent request",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, Email: "email@gmail.com"}, nil)
+				s.EXPECT().SetTOTPSecret(int64(1), gomock.Any()).Return(sql.ErrNoRows)
+			},
+			expectedErr: errTwoFactorEnabled,
+		},
+		{
+			name: "Not found",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{}, sql.ErrNoRows)
+			},
+			expectedErr: errUserNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			c.mockBehavior(repo)
+
+			serv := newTestAuthService(t, repo, &config.Config{Auth: config.Auth{RefreshSecret: "refresh_secret"}})
+
+			got, err := serv.EnrollTOTP(1)
+
+			assert.Equal(t, err, c.expectedErr)
+			if c.expectedErr == nil {
+				assert.Len(t, got.Secret, 32)
+				assert.Equal(t, got.URI, totpURI(defaultTwoFactorIssuer, "email@gmail.com", got.Secret))
+			}
+		})
+	}
+}
+
+func TestAuthService_ConfirmTOTP(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository, sealed string)
+
+	enabledAt := time.Now()
+
+	cases := []struct {
+		name         string
+		code         string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, sealed string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, TOTPSecret: &sealed}, nil)
+				s.EXPECT().EnableTOTP(int64(1), gomock.Any(), gomock.Len(recoveryCodesCount)).Return(nil)
+			},
+		},
+		{
+			name: "Wrong code",
+			code: "000000",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, sealed string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, TOTPSecret: &sealed}, nil)
+			},
+			expectedErr: errInvalidTOTPCode,
+		},
+		{
+			name: "Not enrolled",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, sealed string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1}, nil)
+			},
+			expectedErr: errTwoFactorNotEnrolled,
+		},
+		{
+			name: "Already enabled",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, sealed string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, TOTPSecret: &sealed, TOTPEnabledAt: &enabledAt}, nil)
+			},
+			expectedErr: errTwoFactorEnabled,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			serv := newTestAuthService(t, repo, &config.Config{Auth: config.Auth{RefreshSecret: "refresh_secret"}})
+
+			sealed, err := serv.cfg.totp.seal(rfc6238Secret)
+			assert.NoError(t, err)
+			c.mockBehavior(repo, sealed)
+
+			code := c.code
+			if code == "" {
+				code, err = totpCode(rfc6238Secret, totpStep(time.Now()))
+				assert.NoError(t, err)
+			}
+
+			got, err := serv.ConfirmTOTP(1, code)
+
+			assert.Equal(t, err, c.expectedErr)
+			if c.expectedErr == nil {
+				assert.Len(t, got.RecoveryCodes, recoveryCodesCount)
+			}
+		})
+	}
+}
+
+func TestAuthService_DisableTOTP(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository, hash string)
+
+	cases := []struct {
+		name         string
+		password     string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:     "OK",
+			password: "password",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+				s.EXPECT().DisableTOTP(int64(1)).Return(nil)
+			},
+		},
+		{
+			name:     "Invalid password",
+			password: "invalid_password",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+			},
+			expectedErr: errInvalidPassword,
+		},
+		{
+			name:     "Not found",
+			password: "password",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{}, sql.ErrNoRows)
+			},
+			expectedErr: errUserNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			serv := newTestAuthService(t, repo, &config.Config{Password: testPasswordConfig})
+
+			hash, err := serv.HashPassword("password")
+			assert.NoError(t, err)
+			c.mockBehavior(repo, hash)
+
+			err = serv.DisableTOTP(1, c.password)
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
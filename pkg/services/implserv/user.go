@@ -0,0 +1,96 @@
+package implserv
+
+import (
+	"database/sql"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/sirupsen/logrus"
+)
+
+func (service *AuthServiceImpl) GetUser(userId int64) (dto.UserResponseDTO, error) {
+	user, err := service.repo.GetById(userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return dto.UserResponseDTO{}, errUserNotFound
+		}
+		return dto.UserResponseDTO{}, err
+	}
+
+	return *user.ToResponseDTO(), nil
+}
+
+// UpdateUser updates the profile of the user. A changed email loses
+// its verification and a new verification token is sent to it.
+func (service *AuthServiceImpl) UpdateUser(userId int64, input dto.UpdateUserDTO) (dto.UserResponseDTO, error) {
+	current, err := service.repo.GetById(userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return dto.UserResponseDTO{}, errUserNotFound
+		}
+		return dto.UserResponseDTO{}, err
+	}
+
+	if err := service.repo.UpdateUser(userId, input); err != nil {
+		if err == sql.ErrNoRows {
+			return dto.UserResponseDTO{}, errUserNotFound
+		}
+		return dto.UserResponseDTO{}, constraintError(err, userConstraintErrors)
+	}
+
+	if input.Email != nil && *input.Email != current.Email {
+		service.reverifyEmail(userId, *input.Email)
+	}
+
+	return service.GetUser(userId)
+}
+
+// reverifyEmail replaces the pending verification tokens of the previous email.
+// The profile is already updated, so a failure is only logged and a new token
+// can be requested.
+func (service *AuthServiceImpl) reverifyEmail(userId int64, email string) {
+	err := service.repo.DeleteUserTokens(userId, entity.TokenPurposeEmailVerification)
+	if err == nil {
+		err = service.sendVerification(userId, email)
+	}
+
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id": userId,
+			"error":   err,
+		}).Error("failed to send verification email")
+	}
+}
+
+// DeleteUser deletes the account of the user together with all of its
+// projects and sessions if the password is correct. Accounts without
+// a password are confirmed by the session alone.
+func (service *AuthServiceImpl) DeleteUser(userId int64, password string) error {
+	user, err := service.repo.GetById(userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errUserNotFound
+		}
+		return err
+	}
+
+	if user.PasswordHash != "" {
+		ok, _, err := service.cfg.password.verify(password, user.PasswordHash, service.cfg.salt)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return errInvalidPassword
+		}
+	}
+
+	if err := service.repo.DeleteUser(userId); err != nil {
+		if err == sql.ErrNoRows {
+			return errUserNotFound
+		}
+		return err
+	}
+
+	return nil
+}
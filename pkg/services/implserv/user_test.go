@@ -0,0 +1,225 @@
+package implserv
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/dto"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/mailer"
+	mock_mailer "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/mailer/mocks"
+	mock_repositories "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthService_GetUser(t *testing.T) {
+	verifiedAt := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local)
+
+	cases := []struct {
+		name        string
+		user        entity.User
+		repoErr     error
+		expected    dto.UserResponseDTO
+		expectedErr error
+	}{
+		{
+			name: "OK",
+			user: entity.User{Id: 1, Name: "name", Email: "email@gmail.com", EmailVerifiedAt: &verifiedAt,
+				Username: "username", PasswordHash: "hash"},
+			expected: dto.UserResponseDTO{Id: 1, Name: "name", Email: "email@gmail.com", EmailVerifiedAt: &verifiedAt,
+				Username: "username"},
+		},
+		{
+			name:        "Not found",
+			repoErr:     sql.ErrNoRows,
+			expectedErr: errUserNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			repo.EXPECT().GetById(int64(1)).Return(c.user, c.repoErr)
+
+			got, err := newTestAuthService(t, repo, &config.Config{}).GetUser(1)
+
+			assert.Equal(t, err, c.expectedErr)
+			assert.Equal(t, got, c.expected)
+		})
+	}
+}
+
+func TestAuthService_UpdateUser(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, input dto.UpdateUserDTO)
+
+	name, email, sameEmail := "new name", "new@gmail.com", "email@gmail.com"
+	current := entity.User{Id: 1, Name: "name", Email: "email@gmail.com", Username: "username"}
+
+	cases := []struct {
+		name         string
+		input        dto.UpdateUserDTO
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:  "Name",
+			input: dto.UpdateUserDTO{Name: &name},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, input dto.UpdateUserDTO) {
+				s.EXPECT().GetById(int64(1)).Return(current, nil)
+				s.EXPECT().UpdateUser(int64(1), input).Return(nil)
+				s.EXPECT().GetById(int64(1)).Return(current, nil)
+			},
+		},
+		{
+			name:  "Same email",
+			input: dto.UpdateUserDTO{Email: &sameEmail},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, input dto.UpdateUserDTO) {
+				s.EXPECT().GetById(int64(1)).Return(current, nil)
+				s.EXPECT().UpdateUser(int64(1), input).Return(nil)
+				s.EXPECT().GetById(int64(1)).Return(current, nil)
+			},
+		},
+		{
+			name:  "New email",
+			input: dto.UpdateUserDTO{Email: &email},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, input dto.UpdateUserDTO) {
+				s.EXPECT().GetById(int64(1)).Return(current, nil)
+				s.EXPECT().UpdateUser(int64(1), input).Return(nil)
+				s.EXPECT().DeleteUserTokens(int64(1), entity.TokenPurposeEmailVerification).Return(nil)
+				s.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(token *entity.UserToken, tokenHash string) error {
+						assert.Equal(t, token.Email, email)
+						return nil
+					})
+				m.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg mailer.Message) error {
+					assert.Equal(t, msg.To, email)
+					return nil
+				})
+				s.EXPECT().GetById(int64(1)).Return(current, nil)
+			},
+		},
+		{
+			name:  "Email taken",
+			input: dto.UpdateUserDTO{Email: &email},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, input dto.UpdateUserDTO) {
+				s.EXPECT().GetById(int64(1)).Return(current, nil)
+				s.EXPECT().UpdateUser(int64(1), input).
+					Return(&pq.Error{Code: uniqueViolation, Constraint: "users_email_lower_key"})
+			},
+			expectedErr: errEmailTaken,
+		},
+		{
+			name:  "Not found",
+			input: dto.UpdateUserDTO{Name: &name},
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, input dto.UpdateUserDTO) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{}, sql.ErrNoRows)
+			},
+			expectedErr: errUserNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			mail := mock_mailer.NewMockMailer(ctrl)
+			c.mockBehavior(repo, mail, c.input)
+
+			serv := newTestAuthService(t, repo, &config.Config{})
+			serv.mailer = mail
+
+			got, err := serv.UpdateUser(1, c.input)
+
+			assert.Equal(t, err, c.expectedErr)
+			if c.expectedErr == nil {
+				assert.Equal(t, got, *current.ToResponseDTO())
+			}
+		})
+	}
+}
+
+func TestAuthService_DeleteUser(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository, hash string)
+
+	cases := []struct {
+		name         string
+		password     string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:     "OK",
+			password: "password",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+				s.EXPECT().DeleteUser(int64(1)).Return(nil)
+			},
+		},
+		{
+			name:     "Invalid password",
+			password: "invalid_password",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+			},
+			expectedErr: errInvalidPassword,
+		},
+		{
+			name: "Without password",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+			},
+			expectedErr: errInvalidPassword,
+		},
+		{
+			name: "Account without a password",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1}, nil)
+				s.EXPECT().DeleteUser(int64(1)).Return(nil)
+			},
+		},
+		{
+			name:     "Not found",
+			password: "password",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{}, sql.ErrNoRows)
+			},
+			expectedErr: errUserNotFound,
+		},
+		{
+			name:     "Repository error",
+			password: "password",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, hash string) {
+				s.EXPECT().GetById(int64(1)).Return(entity.User{Id: 1, PasswordHash: hash}, nil)
+				s.EXPECT().DeleteUser(int64(1)).Return(errSome)
+			},
+			expectedErr: errSome,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			serv := newTestAuthService(t, repo, &config.Config{Password: testPasswordConfig})
+
+			hash, err := serv.HashPassword("password")
+			assert.NoError(t, err)
+			c.mockBehavior(repo, hash)
+
+			err = serv.DeleteUser(1, c.password)
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package implserv
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/mailer"
+)
+
+const defaultVerificationTTL = 24 * time.Hour
+
+// VerifyEmail consumes the verification token and marks the email it was sent to as verified.
+func (service *AuthServiceImpl) VerifyEmail(token string) error {
+	t, err := service.repo.ConsumeUserToken(service.hashToken(token), entity.TokenPurposeEmailVerification)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errInvalidVerificationToken
+		}
+		return err
+	}
+
+	if time.Now().After(t.ExpiresAt) {
+		return errExpiredVerificationToken
+	}
+
+	if err := service.repo.MarkEmailVerified(t.UserId, t.Email); err != nil {
+		if err == sql.ErrNoRows {
+			// the email was changed after the token was sent
+			return errInvalidVerificationToken
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ResendVerification sends a new verification token and invalidates the previous ones.
+// Unknown and already verified emails are ignored, so the response does not reveal
+// whether an account exists.
+func (service *AuthServiceImpl) ResendVerification(email string) error {
+	user, err := service.repo.GetByEmail(email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	if err := service.repo.DeleteUserTokens(int64(user.Id), entity.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	return service.sendVerification(int64(user.Id), user.Email)
+}
+
+func (service *AuthServiceImpl) sendVerification(userId int64, email string) error {
+	token, err := service.issueUserToken(userId, email, entity.TokenPurposeEmailVerification, service.cfg.verification)
+	if err != nil {
+		return err
+	}
+
+	return service.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("To verify your email open %s/verify-email?token=%s\n\nThe link expires in %s.",
+			service.cfg.baseURL, token, service.cfg.verification),
+	})
+}
+
+// issueUserToken stores the hash of a new single-use token of the purpose and returns the token.
+func (service *AuthServiceImpl) issueUserToken(userId int64, email, purpose string, ttl time.Duration) (string, error) {
+	token, err := newRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := service.repo.CreateUserToken(&entity.UserToken{
+		UserId:    userId,
+		Purpose:   purpose,
+		Email:     email,
+		ExpiresAt: time.Now().Add(ttl),
+	}, service.hashToken(token)); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
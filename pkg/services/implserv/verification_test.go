@@ -0,0 +1,163 @@
+package implserv
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/core/entity"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/config"
+	"github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/mailer"
+	mock_mailer "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/mailer/mocks"
+	mock_repositories "github.com/DmytroBeliasnyk/crud_app_rest_api/pkg/repositories/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthService_VerifyEmail(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository, tokenHash string)
+
+	cases := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, tokenHash string) {
+				s.EXPECT().ConsumeUserToken(tokenHash, entity.TokenPurposeEmailVerification).
+					Return(entity.UserToken{UserId: 1, Email: "email@gmail.com", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				s.EXPECT().MarkEmailVerified(int64(1), "email@gmail.com").Return(nil)
+			},
+		},
+		{
+			name: "Unknown token",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, tokenHash string) {
+				s.EXPECT().ConsumeUserToken(tokenHash, entity.TokenPurposeEmailVerification).
+					Return(entity.UserToken{}, sql.ErrNoRows)
+			},
+			expectedErr: errInvalidVerificationToken,
+		},
+		{
+			name: "Expired token",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, tokenHash string) {
+				s.EXPECT().ConsumeUserToken(tokenHash, entity.TokenPurposeEmailVerification).
+					Return(entity.UserToken{UserId: 1, Email: "email@gmail.com", ExpiresAt: time.Now().Add(-time.Hour)}, nil)
+			},
+			expectedErr: errExpiredVerificationToken,
+		},
+		{
+			name: "Email changed",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, tokenHash string) {
+				s.EXPECT().ConsumeUserToken(tokenHash, entity.TokenPurposeEmailVerification).
+					Return(entity.UserToken{UserId: 1, Email: "old@gmail.com", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				s.EXPECT().MarkEmailVerified(int64(1), "old@gmail.com").Return(sql.ErrNoRows)
+			},
+			expectedErr: errInvalidVerificationToken,
+		},
+		{
+			name: "Repository error",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, tokenHash string) {
+				s.EXPECT().ConsumeUserToken(tokenHash, entity.TokenPurposeEmailVerification).
+					Return(entity.UserToken{}, errSome)
+			},
+			expectedErr: errSome,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			c.mockBehavior(repo, testTokenHash("token"))
+
+			serv := newTestAuthService(t, repo, &config.Config{Auth: config.Auth{RefreshSecret: "refresh_secret"}})
+
+			err := serv.VerifyEmail("token")
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
+
+func TestAuthService_ResendVerification(t *testing.T) {
+	type mockBehavior func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, email string)
+
+	verifiedAt := time.Now()
+
+	cases := []struct {
+		name         string
+		email        string
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:  "OK",
+			email: "email@gmail.com",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, email string) {
+				s.EXPECT().GetByEmail(email).Return(entity.User{Id: 1, Email: email}, nil)
+				s.EXPECT().DeleteUserTokens(int64(1), entity.TokenPurposeEmailVerification).Return(nil)
+				s.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(token *entity.UserToken, tokenHash string) error {
+						assert.Equal(t, token.UserId, int64(1))
+						assert.Equal(t, token.Email, email)
+						assert.WithinDuration(t, token.ExpiresAt, time.Now().Add(2*time.Hour), time.Minute)
+						return nil
+					})
+				m.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg mailer.Message) error {
+					assert.Equal(t, msg.To, email)
+					assert.Contains(t, msg.Body, "http://app/verify-email?token=")
+					return nil
+				})
+			},
+		},
+		{
+			name:  "Unknown email",
+			email: "unknown@gmail.com",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, email string) {
+				s.EXPECT().GetByEmail(email).Return(entity.User{}, sql.ErrNoRows)
+			},
+		},
+		{
+			name:  "Already verified",
+			email: "email@gmail.com",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, email string) {
+				s.EXPECT().GetByEmail(email).Return(entity.User{Id: 1, Email: email, EmailVerifiedAt: &verifiedAt}, nil)
+			},
+		},
+		{
+			name:  "Mailer error",
+			email: "email@gmail.com",
+			mockBehavior: func(s *mock_repositories.MockAuthRepository, m *mock_mailer.MockMailer, email string) {
+				s.EXPECT().GetByEmail(email).Return(entity.User{Id: 1, Email: email}, nil)
+				s.EXPECT().DeleteUserTokens(int64(1), entity.TokenPurposeEmailVerification).Return(nil)
+				s.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().Send(gomock.Any()).Return(errSome)
+			},
+			expectedErr: errSome,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockAuthRepository(ctrl)
+			mail := mock_mailer.NewMockMailer(ctrl)
+			c.mockBehavior(repo, mail, c.email)
+
+			serv := newTestAuthService(t, repo, &config.Config{
+				Auth:   config.Auth{Verification: 2 * time.Hour},
+				Mailer: config.Mailer{BaseURL: "http://app"},
+			})
+			serv.mailer = mail
+
+			err := serv.ResendVerification(c.email)
+
+			assert.Equal(t, err, c.expectedErr)
+		})
+	}
+}
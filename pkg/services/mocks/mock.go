@@ -34,6 +34,21 @@ func (m *MockProjectService) EXPECT() *MockProjectServiceMockRecorder {
 	return m.recorder
 }
 
+// AddMember mocks base method.
+func (m *MockProjectService) AddMember(projectId, userId int64, input dto.AddProjectMemberDTO) (dto.ProjectMemberDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", projectId, userId, input)
+	ret0, _ := ret[0].(dto.ProjectMemberDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockProjectServiceMockRecorder) AddMember(projectId, userId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockProjectService)(nil).AddMember), projectId, userId, input)
+}
+
 // Create mocks base method.
 func (m *MockProjectService) Create(p dto.ProjectDTO, userId int64) (int64, error) {
 	m.ctrl.T.Helper()
@@ -64,10 +79,10 @@ func (mr *MockProjectServiceMockRecorder) DeleteById(id, userId interface{}) *go
 }
 
 // GetAll mocks base method.
-func (m *MockProjectService) GetAll(userId int64) ([]dto.ProjectDTO, error) {
+func (m *MockProjectService) GetAll(userId int64) ([]dto.ProjectResponseDTO, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAll", userId)
-	ret0, _ := ret[0].([]dto.ProjectDTO)
+	ret0, _ := ret[0].([]dto.ProjectResponseDTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -79,10 +94,10 @@ func (mr *MockProjectServiceMockRecorder) GetAll(userId interface{}) *gomock.Cal
 }
 
 // GetById mocks base method.
-func (m *MockProjectService) GetById(id, userId int64) (dto.ProjectDTO, error) {
+func (m *MockProjectService) GetById(id, userId int64) (dto.ProjectResponseDTO, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetById", id, userId)
-	ret0, _ := ret[0].(dto.ProjectDTO)
+	ret0, _ := ret[0].(dto.ProjectResponseDTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -93,6 +108,79 @@ func (mr *MockProjectServiceMockRecorder) GetById(id, userId interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockProjectService)(nil).GetById), id, userId)
 }
 
+// GetMembers mocks base method.
+func (m *MockProjectService) GetMembers(projectId, userId int64) ([]dto.ProjectMemberDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", projectId, userId)
+	ret0, _ := ret[0].([]dto.ProjectMemberDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockProjectServiceMockRecorder) GetMembers(projectId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockProjectService)(nil).GetMembers), projectId, userId)
+}
+
+// List mocks base method.
+func (m *MockProjectService) List(userId int64, query dto.ProjectQueryDTO) (dto.ProjectPageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", userId, query)
+	ret0, _ := ret[0].(dto.ProjectPageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockProjectServiceMockRecorder) List(userId, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockProjectService)(nil).List), userId, query)
+}
+
+// MemberIds mocks base method.
+func (m *MockProjectService) MemberIds(projectId int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MemberIds", projectId)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MemberIds indicates an expected call of MemberIds.
+func (mr *MockProjectServiceMockRecorder) MemberIds(projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MemberIds", reflect.TypeOf((*MockProjectService)(nil).MemberIds), projectId)
+}
+
+// RemoveMember mocks base method.
+func (m *MockProjectService) RemoveMember(projectId, userId, memberId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", projectId, userId, memberId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockProjectServiceMockRecorder) RemoveMember(projectId, userId, memberId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockProjectService)(nil).RemoveMember), projectId, userId, memberId)
+}
+
+// TransferOwnership mocks base method.
+func (m *MockProjectService) TransferOwnership(projectId, userId int64, input dto.TransferProjectDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferOwnership", projectId, userId, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferOwnership indicates an expected call of TransferOwnership.
+func (mr *MockProjectServiceMockRecorder) TransferOwnership(projectId, userId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferOwnership", reflect.TypeOf((*MockProjectService)(nil).TransferOwnership), projectId, userId, input)
+}
+
 // UpdateById mocks base method.
 func (m *MockProjectService) UpdateById(id int64, p dto.UpdateProjectDTO, userId int64) error {
 	m.ctrl.T.Helper()
@@ -107,6 +195,116 @@ func (mr *MockProjectServiceMockRecorder) UpdateById(id, p, userId interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateById", reflect.TypeOf((*MockProjectService)(nil).UpdateById), id, p, userId)
 }
 
+// UpdateMemberRole mocks base method.
+func (m *MockProjectService) UpdateMemberRole(projectId, userId, memberId int64, input dto.UpdateProjectMemberDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMemberRole", projectId, userId, memberId, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMemberRole indicates an expected call of UpdateMemberRole.
+func (mr *MockProjectServiceMockRecorder) UpdateMemberRole(projectId, userId, memberId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRole", reflect.TypeOf((*MockProjectService)(nil).UpdateMemberRole), projectId, userId, memberId, input)
+}
+
+// MockTaskService is a mock of TaskService interface.
+type MockTaskService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskServiceMockRecorder
+}
+
+// MockTaskServiceMockRecorder is the mock recorder for MockTaskService.
+type MockTaskServiceMockRecorder struct {
+	mock *MockTaskService
+}
+
+// NewMockTaskService creates a new mock instance.
+func NewMockTaskService(ctrl *gomock.Controller) *MockTaskService {
+	mock := &MockTaskService{ctrl: ctrl}
+	mock.recorder = &MockTaskServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskService) EXPECT() *MockTaskServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockTaskService) Create(projectId int64, t dto.TaskDTO, userId int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", projectId, t, userId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTaskServiceMockRecorder) Create(projectId, t, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTaskService)(nil).Create), projectId, t, userId)
+}
+
+// DeleteById mocks base method.
+func (m *MockTaskService) DeleteById(projectId, id, userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteById", projectId, id, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteById indicates an expected call of DeleteById.
+func (mr *MockTaskServiceMockRecorder) DeleteById(projectId, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteById", reflect.TypeOf((*MockTaskService)(nil).DeleteById), projectId, id, userId)
+}
+
+// GetById mocks base method.
+func (m *MockTaskService) GetById(projectId, id, userId int64) (dto.TaskResponseDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", projectId, id, userId)
+	ret0, _ := ret[0].(dto.TaskResponseDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockTaskServiceMockRecorder) GetById(projectId, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockTaskService)(nil).GetById), projectId, id, userId)
+}
+
+// List mocks base method.
+func (m *MockTaskService) List(projectId, userId int64, query dto.TaskQueryDTO) ([]dto.TaskResponseDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", projectId, userId, query)
+	ret0, _ := ret[0].([]dto.TaskResponseDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTaskServiceMockRecorder) List(projectId, userId, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTaskService)(nil).List), projectId, userId, query)
+}
+
+// UpdateById mocks base method.
+func (m *MockTaskService) UpdateById(projectId, id int64, t dto.UpdateTaskDTO, userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateById", projectId, id, t, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateById indicates an expected call of UpdateById.
+func (mr *MockTaskServiceMockRecorder) UpdateById(projectId, id, t, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateById", reflect.TypeOf((*MockTaskService)(nil).UpdateById), projectId, id, t, userId)
+}
+
 // MockAuthService is a mock of AuthService interface.
 type MockAuthService struct {
 	ctrl     *gomock.Controller
@@ -130,10 +328,139 @@ func (m *MockAuthService) EXPECT() *MockAuthServiceMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockAuthService) ChangePassword(userId int64, cp dto.ChangePasswordDTO, client dto.ClientInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", userId, cp, client)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockAuthServiceMockRecorder) ChangePassword(userId, cp, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAuthService)(nil).ChangePassword), userId, cp, client)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockAuthService) ConfirmTOTP(userId int64, code string) (dto.RecoveryCodesDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", userId, code)
+	ret0, _ := ret[0].(dto.RecoveryCodesDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockAuthServiceMockRecorder) ConfirmTOTP(userId, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockAuthService)(nil).ConfirmTOTP), userId, code)
+}
+
+// CreateAccessToken mocks base method.
+func (m *MockAuthService) CreateAccessToken(userId int64, input dto.CreateAccessTokenDTO) (dto.CreatedAccessTokenDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccessToken", userId, input)
+	ret0, _ := ret[0].(dto.CreatedAccessTokenDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccessToken indicates an expected call of CreateAccessToken.
+func (mr *MockAuthServiceMockRecorder) CreateAccessToken(userId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccessToken", reflect.TypeOf((*MockAuthService)(nil).CreateAccessToken), userId, input)
+}
+
+// DeleteAccessToken mocks base method.
+func (m *MockAuthService) DeleteAccessToken(id, userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccessToken", id, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccessToken indicates an expected call of DeleteAccessToken.
+func (mr *MockAuthServiceMockRecorder) DeleteAccessToken(id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccessToken", reflect.TypeOf((*MockAuthService)(nil).DeleteAccessToken), id, userId)
+}
+
+// DeleteSession mocks base method.
+func (m *MockAuthService) DeleteSession(id, userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", id, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockAuthServiceMockRecorder) DeleteSession(id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockAuthService)(nil).DeleteSession), id, userId)
+}
+
+// DeleteUser mocks base method.
+func (m *MockAuthService) DeleteUser(userId int64, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", userId, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockAuthServiceMockRecorder) DeleteUser(userId, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAuthService)(nil).DeleteUser), userId, password)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockAuthService) DisableTOTP(userId int64, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", userId, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockAuthServiceMockRecorder) DisableTOTP(userId, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockAuthService)(nil).DisableTOTP), userId, password)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockAuthService) EnrollTOTP(userId int64) (dto.TOTPEnrollmentDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", userId)
+	ret0, _ := ret[0].(dto.TOTPEnrollmentDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockAuthServiceMockRecorder) EnrollTOTP(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockAuthService)(nil).EnrollTOTP), userId)
+}
+
+// ForgotPassword mocks base method.
+func (m *MockAuthService) ForgotPassword(email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword.
+func (mr *MockAuthServiceMockRecorder) ForgotPassword(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockAuthService)(nil).ForgotPassword), email)
+}
+
 // GenerateTokens mocks base method.
-func (m *MockAuthService) GenerateTokens(id int64) (string, string, error) {
+func (m *MockAuthService) GenerateTokens(id int64, client dto.ClientInfo) (string, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateTokens", id)
+	ret := m.ctrl.Call(m, "GenerateTokens", id, client)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(error)
@@ -141,17 +468,63 @@ func (m *MockAuthService) GenerateTokens(id int64) (string, string, error) {
 }
 
 // GenerateTokens indicates an expected call of GenerateTokens.
-func (mr *MockAuthServiceMockRecorder) GenerateTokens(id interface{}) *gomock.Call {
+func (mr *MockAuthServiceMockRecorder) GenerateTokens(id, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateTokens", reflect.TypeOf((*MockAuthService)(nil).GenerateTokens), id, client)
+}
+
+// GetAccessTokens mocks base method.
+func (m *MockAuthService) GetAccessTokens(userId int64) ([]dto.AccessTokenDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessTokens", userId)
+	ret0, _ := ret[0].([]dto.AccessTokenDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessTokens indicates an expected call of GetAccessTokens.
+func (mr *MockAuthServiceMockRecorder) GetAccessTokens(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokens", reflect.TypeOf((*MockAuthService)(nil).GetAccessTokens), userId)
+}
+
+// GetSessions mocks base method.
+func (m *MockAuthService) GetSessions(userId int64, rt string) ([]dto.SessionDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", userId, rt)
+	ret0, _ := ret[0].([]dto.SessionDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockAuthServiceMockRecorder) GetSessions(userId, rt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateTokens", reflect.TypeOf((*MockAuthService)(nil).GenerateTokens), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockAuthService)(nil).GetSessions), userId, rt)
+}
+
+// GetUser mocks base method.
+func (m *MockAuthService) GetUser(userId int64) (dto.UserResponseDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", userId)
+	ret0, _ := ret[0].(dto.UserResponseDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockAuthServiceMockRecorder) GetUser(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockAuthService)(nil).GetUser), userId)
 }
 
 // HashPassword mocks base method.
-func (m *MockAuthService) HashPassword(password string) string {
+func (m *MockAuthService) HashPassword(password string) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "HashPassword", password)
 	ret0, _ := ret[0].(string)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // HashPassword indicates an expected call of HashPassword.
@@ -160,11 +533,41 @@ func (mr *MockAuthServiceMockRecorder) HashPassword(password interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashPassword", reflect.TypeOf((*MockAuthService)(nil).HashPassword), password)
 }
 
+// JWKS mocks base method.
+func (m *MockAuthService) JWKS() dto.JWKSetDTO {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(dto.JWKSetDTO)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockAuthServiceMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockAuthService)(nil).JWKS))
+}
+
+// ParseAccessToken mocks base method.
+func (m *MockAuthService) ParseAccessToken(token string) (int64, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseAccessToken", token)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ParseAccessToken indicates an expected call of ParseAccessToken.
+func (mr *MockAuthServiceMockRecorder) ParseAccessToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseAccessToken", reflect.TypeOf((*MockAuthService)(nil).ParseAccessToken), token)
+}
+
 // ParseToken mocks base method.
-func (m *MockAuthService) ParseToken(input string) (int64, error) {
+func (m *MockAuthService) ParseToken(input string) (dto.TokenClaimsDTO, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ParseToken", input)
-	ret0, _ := ret[0].(int64)
+	ret0, _ := ret[0].(dto.TokenClaimsDTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -175,40 +578,144 @@ func (mr *MockAuthServiceMockRecorder) ParseToken(input interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockAuthService)(nil).ParseToken), input)
 }
 
-// SignIn mocks base method.
-func (m *MockAuthService) SignIn(si dto.SignInDTO) (int64, error) {
+// ResendVerification mocks base method.
+func (m *MockAuthService) ResendVerification(email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendVerification", email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResendVerification indicates an expected call of ResendVerification.
+func (mr *MockAuthServiceMockRecorder) ResendVerification(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendVerification", reflect.TypeOf((*MockAuthService)(nil).ResendVerification), email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockAuthService) ResetPassword(rp dto.ResetPasswordDTO, client dto.ClientInfo) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignIn", si)
+	ret := m.ctrl.Call(m, "ResetPassword", rp, client)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAuthServiceMockRecorder) ResetPassword(rp, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAuthService)(nil).ResetPassword), rp, client)
+}
+
+// SignIn mocks base method.
+func (m *MockAuthService) SignIn(si dto.SignInDTO, client dto.ClientInfo) (int64, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignIn", si, client)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // SignIn indicates an expected call of SignIn.
-func (mr *MockAuthServiceMockRecorder) SignIn(si interface{}) *gomock.Call {
+func (mr *MockAuthServiceMockRecorder) SignIn(si, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockAuthService)(nil).SignIn), si, client)
+}
+
+// SignInOIDC mocks base method.
+func (m *MockAuthService) SignInOIDC(provider string, cb dto.OIDCCallbackDTO, flow string, client dto.ClientInfo) (int64, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignInOIDC", provider, cb, flow, client)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SignInOIDC indicates an expected call of SignInOIDC.
+func (mr *MockAuthServiceMockRecorder) SignInOIDC(provider, cb, flow, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInOIDC", reflect.TypeOf((*MockAuthService)(nil).SignInOIDC), provider, cb, flow, client)
+}
+
+// SignInTwoFactor mocks base method.
+func (m *MockAuthService) SignInTwoFactor(input dto.SignInTwoFactorDTO, client dto.ClientInfo) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignInTwoFactor", input, client)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignInTwoFactor indicates an expected call of SignInTwoFactor.
+func (mr *MockAuthServiceMockRecorder) SignInTwoFactor(input, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInTwoFactor", reflect.TypeOf((*MockAuthService)(nil).SignInTwoFactor), input, client)
+}
+
+// SignOut mocks base method.
+func (m *MockAuthService) SignOut(rt string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignOut", rt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SignOut indicates an expected call of SignOut.
+func (mr *MockAuthServiceMockRecorder) SignOut(rt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignOut", reflect.TypeOf((*MockAuthService)(nil).SignOut), rt)
+}
+
+// SignOutAll mocks base method.
+func (m *MockAuthService) SignOutAll(userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignOutAll", userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SignOutAll indicates an expected call of SignOutAll.
+func (mr *MockAuthServiceMockRecorder) SignOutAll(userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockAuthService)(nil).SignIn), si)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignOutAll", reflect.TypeOf((*MockAuthService)(nil).SignOutAll), userId)
 }
 
 // SignUp mocks base method.
-func (m *MockAuthService) SignUp(su dto.SignUpDTO) (int64, error) {
+func (m *MockAuthService) SignUp(su dto.SignUpDTO, client dto.ClientInfo) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignUp", su)
+	ret := m.ctrl.Call(m, "SignUp", su, client)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SignUp indicates an expected call of SignUp.
-func (mr *MockAuthServiceMockRecorder) SignUp(su interface{}) *gomock.Call {
+func (mr *MockAuthServiceMockRecorder) SignUp(su, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockAuthService)(nil).SignUp), su, client)
+}
+
+// StartOIDC mocks base method.
+func (m *MockAuthService) StartOIDC(provider string) (dto.OIDCStartDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartOIDC", provider)
+	ret0, _ := ret[0].(dto.OIDCStartDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartOIDC indicates an expected call of StartOIDC.
+func (mr *MockAuthServiceMockRecorder) StartOIDC(provider interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockAuthService)(nil).SignUp), su)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartOIDC", reflect.TypeOf((*MockAuthService)(nil).StartOIDC), provider)
 }
 
 // UpdateTokens mocks base method.
-func (m *MockAuthService) UpdateTokens(rt string) (string, string, error) {
+func (m *MockAuthService) UpdateTokens(rt string, client dto.ClientInfo) (string, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateTokens", rt)
+	ret := m.ctrl.Call(m, "UpdateTokens", rt, client)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(error)
@@ -216,7 +723,146 @@ func (m *MockAuthService) UpdateTokens(rt string) (string, string, error) {
 }
 
 // UpdateTokens indicates an expected call of UpdateTokens.
-func (mr *MockAuthServiceMockRecorder) UpdateTokens(rt interface{}) *gomock.Call {
+func (mr *MockAuthServiceMockRecorder) UpdateTokens(rt, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTokens", reflect.TypeOf((*MockAuthService)(nil).UpdateTokens), rt, client)
+}
+
+// UpdateUser mocks base method.
+func (m *MockAuthService) UpdateUser(userId int64, input dto.UpdateUserDTO) (dto.UserResponseDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", userId, input)
+	ret0, _ := ret[0].(dto.UserResponseDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockAuthServiceMockRecorder) UpdateUser(userId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockAuthService)(nil).UpdateUser), userId, input)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockAuthService) VerifyEmail(token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockAuthServiceMockRecorder) VerifyEmail(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockAuthService)(nil).VerifyEmail), token)
+}
+
+// MockAdminService is a mock of AdminService interface.
+type MockAdminService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminServiceMockRecorder
+}
+
+// MockAdminServiceMockRecorder is the mock recorder for MockAdminService.
+type MockAdminServiceMockRecorder struct {
+	mock *MockAdminService
+}
+
+// NewMockAdminService creates a new mock instance.
+func NewMockAdminService(ctrl *gomock.Controller) *MockAdminService {
+	mock := &MockAdminService{ctrl: ctrl}
+	mock.recorder = &MockAdminServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminService) EXPECT() *MockAdminServiceMockRecorder {
+	return m.recorder
+}
+
+// DisableUser mocks base method.
+func (m *MockAdminService) DisableUser(adminId, userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableUser", adminId, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableUser indicates an expected call of DisableUser.
+func (mr *MockAdminServiceMockRecorder) DisableUser(adminId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUser", reflect.TypeOf((*MockAdminService)(nil).DisableUser), adminId, userId)
+}
+
+// EnableUser mocks base method.
+func (m *MockAdminService) EnableUser(userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUser", userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableUser indicates an expected call of EnableUser.
+func (mr *MockAdminServiceMockRecorder) EnableUser(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUser", reflect.TypeOf((*MockAdminService)(nil).EnableUser), userId)
+}
+
+// GetUserById mocks base method.
+func (m *MockAdminService) GetUserById(userId int64) (dto.AdminUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserById", userId)
+	ret0, _ := ret[0].(dto.AdminUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserById indicates an expected call of GetUserById.
+func (mr *MockAdminServiceMockRecorder) GetUserById(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserById", reflect.TypeOf((*MockAdminService)(nil).GetUserById), userId)
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockAdminService) ListAuditEvents(query dto.AuditQueryDTO) (dto.AuditPageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", query)
+	ret0, _ := ret[0].(dto.AuditPageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockAdminServiceMockRecorder) ListAuditEvents(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockAdminService)(nil).ListAuditEvents), query)
+}
+
+// ListUsers mocks base method.
+func (m *MockAdminService) ListUsers(query dto.UserQueryDTO) (dto.UserPageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", query)
+	ret0, _ := ret[0].(dto.UserPageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockAdminServiceMockRecorder) ListUsers(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockAdminService)(nil).ListUsers), query)
+}
+
+// SignOutUser mocks base method.
+func (m *MockAdminService) SignOutUser(userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignOutUser", userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SignOutUser indicates an expected call of SignOutUser.
+func (mr *MockAdminServiceMockRecorder) SignOutUser(userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTokens", reflect.TypeOf((*MockAuthService)(nil).UpdateTokens), rt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignOutUser", reflect.TypeOf((*MockAdminService)(nil).SignOutUser), userId)
 }
@@ -0,0 +1,4 @@
+ALTER TABLE projects
+    DROP COLUMN completed_at,
+    DROP COLUMN updated_at,
+    DROP COLUMN created_at;
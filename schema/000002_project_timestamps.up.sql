@@ -0,0 +1,6 @@
+ALTER TABLE projects
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    ADD COLUMN completed_at TIMESTAMP;
+
+UPDATE projects SET completed_at = now() WHERE done;
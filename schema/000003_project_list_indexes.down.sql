@@ -0,0 +1,5 @@
+DROP INDEX projects_user_id_title_idx;
+
+DROP INDEX projects_user_id_updated_at_idx;
+
+DROP INDEX projects_user_id_created_at_idx;
@@ -0,0 +1,5 @@
+CREATE INDEX projects_user_id_created_at_idx ON projects (user_id, created_at, id);
+
+CREATE INDEX projects_user_id_updated_at_idx ON projects (user_id, updated_at, id);
+
+CREATE INDEX projects_user_id_title_idx ON projects (user_id, title, id);
@@ -0,0 +1,7 @@
+DROP INDEX tokens_user_id_idx;
+
+ALTER TABLE tokens
+    DROP COLUMN last_used_at,
+    DROP COLUMN created_at,
+    DROP COLUMN ip,
+    DROP COLUMN user_agent;
@@ -0,0 +1,7 @@
+ALTER TABLE tokens
+    ADD COLUMN user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    ADD COLUMN ip VARCHAR(45) NOT NULL DEFAULT '',
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT now(),
+    ADD COLUMN last_used_at TIMESTAMP NOT NULL DEFAULT now();
+
+CREATE INDEX tokens_user_id_idx ON tokens (user_id);
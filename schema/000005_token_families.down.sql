@@ -0,0 +1,8 @@
+DROP INDEX tokens_family_id_idx;
+
+DELETE FROM tokens WHERE rotated_at IS NOT NULL;
+
+ALTER TABLE tokens
+    DROP COLUMN rotated_at,
+    DROP COLUMN parent_id,
+    DROP COLUMN family_id;
@@ -0,0 +1,10 @@
+CREATE SEQUENCE token_families_id_seq;
+
+ALTER TABLE tokens
+    ADD COLUMN family_id BIGINT NOT NULL DEFAULT nextval('token_families_id_seq'),
+    ADD COLUMN parent_id INT REFERENCES tokens (id) ON DELETE SET NULL,
+    ADD COLUMN rotated_at TIMESTAMP;
+
+ALTER SEQUENCE token_families_id_seq OWNED BY tokens.family_id;
+
+CREATE INDEX tokens_family_id_idx ON tokens (family_id);
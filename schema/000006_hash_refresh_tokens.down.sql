@@ -0,0 +1,7 @@
+DELETE FROM tokens;
+
+ALTER TABLE tokens ALTER COLUMN token_hash TYPE VARCHAR(255);
+
+ALTER TABLE tokens RENAME CONSTRAINT tokens_token_hash_key TO tokens_token_key;
+
+ALTER TABLE tokens RENAME COLUMN token_hash TO token;
@@ -0,0 +1,8 @@
+-- plaintext tokens cannot be converted to their keyed hashes, every session has to sign in again
+DELETE FROM tokens;
+
+ALTER TABLE tokens RENAME COLUMN token TO token_hash;
+
+ALTER TABLE tokens RENAME CONSTRAINT tokens_token_key TO tokens_token_hash_key;
+
+ALTER TABLE tokens ALTER COLUMN token_hash TYPE CHAR(64);
@@ -0,0 +1,14 @@
+-- totp_secret is encrypted, totp_last_step is the time step of the last accepted code
+ALTER TABLE users
+    ADD COLUMN totp_secret VARCHAR(255),
+    ADD COLUMN totp_enabled_at TIMESTAMP,
+    ADD COLUMN totp_last_step BIGINT;
+
+CREATE TABLE recovery_codes(
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    code_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX recovery_codes_user_id_idx ON recovery_codes (user_id);
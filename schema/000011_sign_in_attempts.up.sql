@@ -0,0 +1,7 @@
+-- key is "user:<id>", "login:<email or username>" of unknown users or "ip:<address>"
+CREATE TABLE sign_in_attempts(
+    key TEXT PRIMARY KEY,
+    failures INT NOT NULL,
+    last_failed_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP
+);
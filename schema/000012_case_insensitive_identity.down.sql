@@ -0,0 +1,5 @@
+DROP INDEX users_email_lower_key, users_username_lower_key;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_email_key UNIQUE (email),
+    ADD CONSTRAINT users_username_key UNIQUE (username);
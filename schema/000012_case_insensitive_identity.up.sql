@@ -0,0 +1,7 @@
+-- fails while emails or usernames of users differ only in case, such users have to be renamed first
+ALTER TABLE users
+    DROP CONSTRAINT users_email_key,
+    DROP CONSTRAINT users_username_key;
+
+CREATE UNIQUE INDEX users_email_lower_key ON users (lower(email));
+CREATE UNIQUE INDEX users_username_lower_key ON users (lower(username));
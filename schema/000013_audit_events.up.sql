@@ -0,0 +1,15 @@
+-- user_id has no foreign key, so the events of deleted users are kept until they expire
+CREATE TABLE audit_events(
+    id BIGSERIAL PRIMARY KEY,
+    event VARCHAR(64) NOT NULL,
+    user_id INT,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    detail TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX audit_events_user_id_idx ON audit_events (user_id, id);
+CREATE INDEX audit_events_event_idx ON audit_events (event, id);
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at);
@@ -0,0 +1,15 @@
+-- members share a project, projects.user_id keeps pointing to its only owner
+CREATE TABLE project_members(
+    project_id INT REFERENCES projects (id) ON DELETE CASCADE NOT NULL,
+    user_id INT REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX project_members_user_id_idx ON project_members (user_id, project_id);
+
+CREATE UNIQUE INDEX project_members_owner_key ON project_members (project_id) WHERE role = 'owner';
+
+INSERT INTO project_members (project_id, user_id, role)
+SELECT id, user_id, 'owner' FROM projects;
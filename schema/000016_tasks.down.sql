@@ -0,0 +1,4 @@
+ALTER TABLE projects
+    DROP COLUMN done_from_tasks;
+
+DROP TABLE tasks;
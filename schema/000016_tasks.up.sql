@@ -0,0 +1,21 @@
+-- tasks are ordered by position and then by id, positions do not have to be consecutive
+CREATE TABLE tasks(
+    id SERIAL PRIMARY KEY,
+    project_id INT REFERENCES projects (id) ON DELETE CASCADE NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'todo' CHECK (status IN ('todo', 'in_progress', 'done')),
+    due_date DATE,
+    position INT NOT NULL DEFAULT 0,
+    assignee_id INT REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX tasks_project_id_position_idx ON tasks (project_id, position, id);
+CREATE INDEX tasks_assignee_id_idx ON tasks (assignee_id);
+
+-- done of such projects follows their tasks: they are done when they have tasks and all of them are done
+ALTER TABLE projects
+    ADD COLUMN done_from_tasks BOOLEAN NOT NULL DEFAULT false;